@@ -1,18 +1,33 @@
 package main
 
 import (
+	"context"
 	"errors"
+	"flag"
+	"fmt"
+	"strings"
 
 	"github.com/golang-migrate/migrate/v4"
 	logger "github.com/sirupsen/logrus"
 
 	"wallet-app/internal/app/delivery/http"
+	"wallet-app/internal/app/domain"
 	"wallet-app/internal/app/repository"
 	"wallet-app/internal/app/services"
 	"wallet-app/internal/configs"
 	"wallet-app/internal/infrastructure/database"
+	"wallet-app/internal/infrastructure/fxrates"
+	"wallet-app/internal/infrastructure/jwt"
 	logging "wallet-app/internal/infrastructure/logger"
+	"wallet-app/internal/infrastructure/ratelimit"
 	"wallet-app/internal/infrastructure/server"
+	"wallet-app/internal/infrastructure/signing"
+)
+
+// Варианты хранилища для флага --storage
+const (
+	storagePostgres = "postgres"
+	storageMemory   = "memory"
 )
 
 // @title           Wallet
@@ -21,7 +36,21 @@ import (
 
 // @host      localhost:8080
 // @BasePath  /api/v1
+
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
+
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description Токен JWT в формате "Bearer <token>"
 func main() {
+	storage := flag.String("storage", storagePostgres, "Хранилище кошельков: postgres или memory")
+	issueAPIKey := flag.String("issue-api-key", "", "Выпустить ключ API для указанного клиента (subject), вывести его и завершить работу")
+	apiKeyRoles := flag.String("api-key-roles", "", "Роли выпускаемого ключа API через запятую: admin, operator, viewer")
+	flag.Parse()
+
 	// Загружаем конфигурацию
 	cfg, err := configs.LoadConfig("./internal/configs")
 	if err != nil {
@@ -30,19 +59,84 @@ func main() {
 	// Настройка логгера
 	logging.SetupLogger(&cfg.Logging)
 
-	// Подключение к базе данных
-	dbConn, err := db.ConnectPostgres(cfg.Database.Dsn)
+	// Курсы валют для котировок
+	rates, err := fxrates.NewFileProvider(cfg.FX.RatesFile)
 	if err != nil {
-		logger.Fatalf("Database connection failed: %v", err)
+		logger.Fatalf("Loading exchange rates failed: %v", err)
+	}
+
+	// Токены JWT принимаются, только если задан алгоритм их подписи
+	var tokens services.TokenVerifier
+	if cfg.Auth.JWT.Algorithm != "" {
+		verifier, err := jwt.NewVerifier(cfg.Auth.JWT)
+		if err != nil {
+			logger.Fatalf("JWT verifier setup failed: %v", err)
+		}
+		tokens = verifier
+	}
+
+	// Подписи запросов проверяются, только если настроены ключи клиентов
+	var signatures services.RequestVerifier
+	if len(cfg.Auth.Signing.Keys) > 0 {
+		verifier, err := signing.NewVerifier(cfg.Auth.Signing)
+		if err != nil {
+			logger.Fatalf("Request signing setup failed: %v", err)
+		}
+		signatures = verifier
+	}
+
+	var repo repository.Storage
+	switch *storage {
+	case storagePostgres:
+		// Подключение к базе данных
+		dbConn, err := db.ConnectPostgres(cfg.Database.Dsn)
+		if err != nil {
+			logger.Fatalf("Database connection failed: %v", err)
+		}
+		defer dbConn.Close()
+
+		applyMigrations(cfg.Database.Dsn)
+
+		repo = repository.NewRepository(dbConn, repository.RetryPolicy{
+			MaxAttempts: cfg.Database.Retry.MaxAttempts,
+			BaseDelay:   cfg.Database.Retry.BaseDelay,
+			MaxDelay:    cfg.Database.Retry.MaxDelay,
+		})
+	case storageMemory:
+		// Данные хранятся только в памяти процесса и теряются при перезапуске
+		logger.Warn("Using in-memory storage: data will be lost on restart")
+		repo = repository.NewMemoryRepository()
+	default:
+		logger.Fatalf("Unknown storage '%s': expected %s or %s", *storage, storagePostgres, storageMemory)
+	}
+
+	service := services.NewService(repo, cfg.Holds, cfg.FX, cfg.Batching, cfg.Limits, rates, tokens, signatures)
+
+	if cfg.RateLimit.Enabled {
+		service.RateLimits = services.NewRateLimitService(rateLimiter(cfg.RateLimit, repo), cfg.RateLimit)
 	}
-	defer dbConn.Close()
 
-	applyMigrations(cfg.Database.Dsn)
+	if *issueAPIKey != "" {
+		printAPIKey(service, *issueAPIKey, *apiKeyRoles)
+		return
+	}
+	if *storage == storageMemory {
+		// В памяти нет сохраненных ключей: выпускаем ключ на время работы процесса,
+		// по умолчанию с ролью admin, чтобы им можно было работать с любыми кошельками
+		roles := *apiKeyRoles
+		if roles == "" {
+			roles = string(domain.RoleAdmin)
+		}
+		printAPIKey(service, "local", roles)
+	}
 
-	repo := repository.NewRepository(dbConn)
-	service := services.NewService(repo)
 	handlers := http.NewHandler(service)
 
+	// Фоновое снятие просроченных холдов
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go services.RunHoldSweeper(ctx, service.Holds, cfg.Holds.SweepInterval)
+
 	// Настройка и запуск сервера
 	server.SetupAndRunServer(&cfg.Server, handlers.InitRoutes())
 }
@@ -62,3 +156,35 @@ func applyMigrations(dsn string) {
 
 	logger.Debug("Applied migrations")
 }
+
+// rateLimiter выбирает хранилище корзин ограничения частоты запросов
+func rateLimiter(cfg configs.RateLimitConfig, repo repository.Storage) repository.RateLimits {
+	if cfg.Backend != "postgres" {
+		return ratelimit.NewMemoryLimiter()
+	}
+
+	// Корзины в Postgres общие для всех экземпляров приложения
+	limiter, ok := repo.(repository.RateLimits)
+	if !ok {
+		logger.Fatalf("Rate limit backend postgres requires --storage=%s", storagePostgres)
+	}
+	return limiter
+}
+
+// printAPIKey выпускает ключ API для клиента subject и выводит его; ключ нельзя будет получить повторно
+func printAPIKey(service *services.Service, subject, roles string) {
+	req := domain.APIKeyRequest{Name: subject, Subject: subject}
+	if roles != "" {
+		req.Roles = strings.Split(roles, ",")
+	}
+	if err := req.Validate(); err != nil {
+		logger.Fatalf("Invalid API key request: %v", err)
+	}
+
+	issued, err := service.IssueAPIKey(context.Background(), req)
+	if err != nil {
+		logger.Fatalf("Issuing API key failed: %v", err)
+	}
+
+	fmt.Printf("API key for %s (id %s): %s\n", issued.Subject, issued.ID, issued.Key)
+}
@@ -15,9 +15,17 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/create-wallet": {
+        "/admin/wallets/{walletId}/activate": {
             "post": {
-                "description": "Генерирует новый кошелек с начальным балансом 0",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает замороженный или заблокированный кошелек в активное состояние",
                 "consumes": [
                     "application/json"
                 ],
@@ -25,34 +33,89 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "wallets"
+                    "admin"
+                ],
+                "summary": "Активация кошелька",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "UUID кошелька",
+                        "name": "walletId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Причина",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/domain.WalletStatusRequest"
+                        }
+                    }
                 ],
-                "summary": "Создание нового кошелька",
                 "responses": {
-                    "201": {
-                        "description": "Созданный кошелек",
+                    "200": {
+                        "description": "Состояние кошелька",
                         "schema": {
-                            "$ref": "#/definitions/domain.Wallet"
+                            "$ref": "#/definitions/domain.WalletState"
                         }
                     },
                     "400": {
-                        "description": "Ошибка при создании",
+                        "description": "Ошибка валидации данных",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Клиент не аутентифицирован",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Доступно только администраторам",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "404": {
+                        "description": "Кошелек не найден",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "409": {
+                        "description": "Кошелек закрыт",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "429": {
+                        "description": "Превышена частота запросов; повторить после Retry-After",
                         "schema": {
-                            "$ref": "#/definitions/http.ErrorResponse"
+                            "$ref": "#/definitions/http.ProblemDetails"
                         }
                     },
                     "500": {
                         "description": "Ошибка сервера",
                         "schema": {
-                            "$ref": "#/definitions/http.ErrorResponse"
+                            "$ref": "#/definitions/http.ProblemDetails"
                         }
                     }
                 }
             }
         },
-        "/wallet": {
+        "/admin/wallets/{walletId}/block": {
             "post": {
-                "description": "Пополнение или снятие средств с кошелька",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Запрещает любые движения средств по кошельку",
                 "consumes": [
                     "application/json"
                 ],
@@ -60,45 +123,89 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "wallets"
+                    "admin"
                 ],
-                "summary": "Изменение баланса кошелька",
+                "summary": "Блокировка кошелька",
                 "parameters": [
                     {
-                        "description": "Данные операции",
+                        "type": "string",
+                        "description": "UUID кошелька",
+                        "name": "walletId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Причина",
                         "name": "request",
                         "in": "body",
-                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/domain.WalletOperation"
+                            "$ref": "#/definitions/domain.WalletStatusRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Операция выполнена",
+                        "description": "Состояние кошелька",
                         "schema": {
-                            "$ref": "#/definitions/http.SuccessResponse"
+                            "$ref": "#/definitions/domain.WalletState"
                         }
                     },
                     "400": {
                         "description": "Ошибка валидации данных",
                         "schema": {
-                            "$ref": "#/definitions/http.ErrorResponse"
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Клиент не аутентифицирован",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Доступно только администраторам",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "404": {
+                        "description": "Кошелек не найден",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "409": {
+                        "description": "Кошелек закрыт",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "429": {
+                        "description": "Превышена частота запросов; повторить после Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
                         }
                     },
                     "500": {
-                        "description": "Внутренняя ошибка сервера",
+                        "description": "Ошибка сервера",
                         "schema": {
-                            "$ref": "#/definitions/http.ErrorResponse"
+                            "$ref": "#/definitions/http.ProblemDetails"
                         }
                     }
                 }
             }
         },
-        "/wallets/{walletId}": {
-            "get": {
-                "description": "Возвращает баланс указанного кошелька",
+        "/admin/wallets/{walletId}/close": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Окончательно закрывает кошелек; возможно только при нулевом балансе и без активных холдов",
                 "consumes": [
                     "application/json"
                 ],
@@ -106,9 +213,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "wallets"
+                    "admin"
                 ],
-                "summary": "Получение баланса кошелька",
+                "summary": "Закрытие кошелька",
                 "parameters": [
                     {
                         "type": "string",
@@ -116,83 +223,2178 @@ const docTemplate = `{
                         "name": "walletId",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Причина",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/domain.WalletStatusRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Баланс кошелька",
+                        "description": "Состояние кошелька",
                         "schema": {
-                            "$ref": "#/definitions/domain.WalletBalance"
+                            "$ref": "#/definitions/domain.WalletState"
                         }
                     },
                     "400": {
-                        "description": "Неверный UUID",
+                        "description": "Ошибка валидации данных",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Клиент не аутентифицирован",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Доступно только администраторам",
                         "schema": {
-                            "$ref": "#/definitions/http.ErrorResponse"
+                            "$ref": "#/definitions/http.ProblemDetails"
                         }
                     },
                     "404": {
                         "description": "Кошелек не найден",
                         "schema": {
-                            "$ref": "#/definitions/http.ErrorResponse"
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "409": {
+                        "description": "Ненулевой баланс или кошелек уже закрыт",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "429": {
+                        "description": "Превышена частота запросов; повторить после Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
                         }
                     },
                     "500": {
                         "description": "Ошибка сервера",
                         "schema": {
-                            "$ref": "#/definitions/http.ErrorResponse"
+                            "$ref": "#/definitions/http.ProblemDetails"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "domain.OperationType": {
-            "type": "string",
-            "enum": [
-                "DEPOSIT",
-                "WITHDRAW"
-            ],
-            "x-enum-varnames": [
-                "Deposit",
-                "Withdraw"
-            ]
         },
-        "domain.Wallet": {
-            "type": "object",
-            "properties": {
-                "balance": {
-                    "type": "number"
-                },
-                "walletId": {
-                    "type": "string"
+        "/admin/wallets/{walletId}/freeze": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Запрещает списания с кошелька, зачисления остаются разрешены. Используется на время проверок.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Заморозка кошелька",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "UUID кошелька",
+                        "name": "walletId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Причина",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/domain.WalletStatusRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Состояние кошелька",
+                        "schema": {
+                            "$ref": "#/definitions/domain.WalletState"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка валидации данных",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Клиент не аутентифицирован",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Доступно только администраторам",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "404": {
+                        "description": "Кошелек не найден",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "409": {
+                        "description": "Кошелек закрыт",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "429": {
+                        "description": "Превышена частота запросов; повторить после Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    }
                 }
             }
         },
-        "domain.WalletBalance": {
-            "type": "object",
-            "properties": {
-                "balance": {
-                    "type": "number"
+        "/create-wallet": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Генерирует новый кошелек с начальным балансом 0 в указанной валюте (по умолчанию RUB).\nВалюта кошелька задается при создании и не меняется. Можно указать владельца,\nотображаемое имя, произвольные метаданные (JSON-объект) и метки.\nЕсли владелец не указан, им становится клиент, создающий кошелек.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "wallets"
+                ],
+                "summary": "Создание нового кошелька",
+                "parameters": [
+                    {
+                        "description": "Валюта (код ISO 4217), владелец и описательные данные",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/domain.CreateWalletRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Созданный кошелек",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Wallet"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка валидации данных",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Клиент не аутентифицирован",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Нет прав создать кошелек другому владельцу",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "429": {
+                        "description": "Превышена частота запросов; повторить после Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    }
                 }
             }
         },
-        "domain.WalletOperation": {
-            "type": "object",
-            "required": [
-                "amount",
-                "operationType",
+        "/fx/quotes": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Фиксирует текущий курс пары валют на ограниченное время. Идентификатор котировки\nпередается в quoteId перевода между кошельками в разных валютах; котировку можно использовать один раз.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "fx"
+                ],
+                "summary": "Котировка курса валют",
+                "parameters": [
+                    {
+                        "description": "Пара валют",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.FXQuoteRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Котировка",
+                        "schema": {
+                            "$ref": "#/definitions/domain.FXQuote"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка валидации данных",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Клиент не аутентифицирован",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "422": {
+                        "description": "Курс для пары валют недоступен",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "429": {
+                        "description": "Превышена частота запросов; повторить после Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/ledger/verify": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Проверяет, что сумма проводок в каждой валюте равна нулю, каждая запись сбалансирована,\nа балансы кошельков совпадают с суммами их проводок",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ledger"
+                ],
+                "summary": "Сверка журнала",
+                "responses": {
+                    "200": {
+                        "description": "Результат сверки",
+                        "schema": {
+                            "$ref": "#/definitions/domain.LedgerReport"
+                        }
+                    },
+                    "401": {
+                        "description": "Клиент не аутентифицирован",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Доступно только администраторам",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "429": {
+                        "description": "Превышена частота запросов; повторить после Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/transfer": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Списывает сумму с одного кошелька и зачисляет на другой в одной транзакции.\nПеревод между кошельками в разных валютах выполняется по котировке (quoteId): сумма зачисления\nпересчитывается по зафиксированному курсу и округляется до точности валюты получателя.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "wallets"
+                ],
+                "summary": "Перевод между кошельками",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Ключ идемпотентности (до 255 символов)",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Данные перевода",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.TransferOperation"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Перевод выполнен",
+                        "schema": {
+                            "$ref": "#/definitions/domain.TransferResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка валидации данных",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Клиент не аутентифицирован",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Нет доступа к кошельку-отправителю",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "404": {
+                        "description": "Кошелек или котировка не найдены",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "409": {
+                        "description": "Котировка истекла или уже использована, кошелек заморожен или закрыт",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "422": {
+                        "description": "Недостаточно средств, превышено ограничение операций кошелька (limit) или ключ идемпотентности использован с другими данными",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "429": {
+                        "description": "Превышена частота запросов; повторить после Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "503": {
+                        "description": "Хранилище временно недоступно",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/wallet": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Пополнение или снятие средств с кошелька, сторно (REVERSAL) или возврат (REFUND) исходной операции.\nДля REVERSAL и REFUND передается transactionId исходной операции; сумма всех возвратов не превышает ее сумму.\nПовторный запрос с тем же Idempotency-Key возвращает сохраненный результат без повторного изменения баланса.\nС заголовком If-Match (ETag из GET /wallets/{walletId}) операция применяется, только если кошелек с тех пор не менялся.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "wallets"
+                ],
+                "summary": "Изменение баланса кошелька",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Ключ идемпотентности (до 255 символов)",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Ожидаемая версия кошелька (ETag)",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Данные операции",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.WalletOperation"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Операция выполнена",
+                        "schema": {
+                            "$ref": "#/definitions/http.OperationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка валидации данных",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Клиент не аутентифицирован",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Нет доступа к кошельку",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "404": {
+                        "description": "Кошелек или исходная операция не найдены",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "409": {
+                        "description": "Операцию нельзя вернуть, сумма возврата превышает остаток или состояние кошелька запрещает операцию",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "412": {
+                        "description": "Кошелек изменился после чтения (If-Match)",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "422": {
+                        "description": "Недостаточно средств, превышено ограничение операций кошелька (limit) или ключ идемпотентности использован с другими данными",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "429": {
+                        "description": "Превышена частота запросов; повторить после Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "503": {
+                        "description": "Хранилище временно недоступно",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/wallets": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает все кошельки указанного владельца в порядке создания",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "wallets"
+                ],
+                "summary": "Кошельки владельца",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор владельца",
+                        "name": "ownerId",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Кошельки владельца",
+                        "schema": {
+                            "$ref": "#/definitions/domain.WalletsByOwner"
+                        }
+                    },
+                    "400": {
+                        "description": "Не указан владелец",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Клиент не аутентифицирован",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Нет доступа к кошелькам владельца",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "429": {
+                        "description": "Превышена частота запросов; повторить после Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/wallets/{walletId}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает доступный остаток, сумму холдов и полный баланс указанного кошелька.\nВерсия кошелька возвращается в заголовке ETag; шардированный кошелек версию не возвращает.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "wallets"
+                ],
+                "summary": "Получение баланса кошелька",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "UUID кошелька",
+                        "name": "walletId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Баланс кошелька",
+                        "schema": {
+                            "$ref": "#/definitions/domain.WalletBalance"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Версия кошелька для If-Match"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный UUID",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Клиент не аутентифицирован",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Нет доступа к кошельку",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "404": {
+                        "description": "Кошелек не найден",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "429": {
+                        "description": "Превышена частота запросов; повторить после Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/wallets/{walletId}/grants": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает клиентов, которым выдана роль на кошелек. Владелец кошелька (ownerId) в списке не указывается.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "grants"
+                ],
+                "summary": "Доступ к кошельку",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "UUID кошелька",
+                        "name": "walletId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Выданные роли",
+                        "schema": {
+                            "$ref": "#/definitions/domain.WalletGrants"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный UUID",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Клиент не аутентифицирован",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Управлять доступом может только владелец кошелька или администратор",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "404": {
+                        "description": "Кошелек не найден",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "429": {
+                        "description": "Превышена частота запросов; повторить после Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/wallets/{walletId}/grants/{subject}": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Выдает клиенту роль owner, operator или viewer на кошелек; выданная ранее роль заменяется.\nowner — операции, чтение и управление доступом, operator — операции и чтение, viewer — только чтение.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "grants"
+                ],
+                "summary": "Выдача доступа к кошельку",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "UUID кошелька",
+                        "name": "walletId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Идентификатор клиента",
+                        "name": "subject",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Роль",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.WalletGrantRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Выданная роль",
+                        "schema": {
+                            "$ref": "#/definitions/domain.WalletGrant"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка валидации данных",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Клиент не аутентифицирован",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Управлять доступом может только владелец кошелька или администратор",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "404": {
+                        "description": "Кошелек не найден",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "429": {
+                        "description": "Превышена частота запросов; повторить после Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Отзывает роль, выданную клиенту на кошелек. Владение кошельком (ownerId) так не отзывается.",
+                "tags": [
+                    "grants"
+                ],
+                "summary": "Отзыв доступа к кошельку",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "UUID кошелька",
+                        "name": "walletId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Идентификатор клиента",
+                        "name": "subject",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Роль отозвана"
+                    },
+                    "400": {
+                        "description": "Ошибка валидации данных",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Клиент не аутентифицирован",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Управлять доступом может только владелец кошелька или администратор",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "404": {
+                        "description": "Кошелек не найден или роль не выдавалась",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "429": {
+                        "description": "Превышена частота запросов; повторить после Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/wallets/{walletId}/holds": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Создает холд: сумма уменьшает доступный остаток, но не баланс, пока холд не списан или не освобожден",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "holds"
+                ],
+                "summary": "Резервирование средств",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "UUID кошелька",
+                        "name": "walletId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Сумма и время жизни холда",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.HoldRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Созданный холд",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Hold"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка валидации данных",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Клиент не аутентифицирован",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Нет доступа к кошельку",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "404": {
+                        "description": "Кошелек не найден",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "409": {
+                        "description": "Состояние кошелька запрещает списания или конкурентное изменение",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "422": {
+                        "description": "Недостаточно средств или превышено ограничение операций кошелька (limit)",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "429": {
+                        "description": "Превышена частота запросов; повторить после Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "503": {
+                        "description": "Хранилище временно недоступно",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/wallets/{walletId}/holds/{holdId}/capture": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Списывает всю сумму холда или ее часть; остаток частичного списания освобождается",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "holds"
+                ],
+                "summary": "Списание холда",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "UUID кошелька",
+                        "name": "walletId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "UUID холда",
+                        "name": "holdId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Сумма списания",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/domain.CaptureRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Холд списан",
+                        "schema": {
+                            "$ref": "#/definitions/domain.HoldCaptureResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка валидации данных",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Клиент не аутентифицирован",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Нет доступа к кошельку",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "404": {
+                        "description": "Кошелек или холд не найден",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "409": {
+                        "description": "Холд не активен или состояние кошелька запрещает списания",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "422": {
+                        "description": "Превышено ограничение операций кошелька (limit)",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "429": {
+                        "description": "Превышена частота запросов; повторить после Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/wallets/{walletId}/holds/{holdId}/release": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Снимает резерв без списания средств",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "holds"
+                ],
+                "summary": "Освобождение холда",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "UUID кошелька",
+                        "name": "walletId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "UUID холда",
+                        "name": "holdId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Холд освобожден",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Hold"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный UUID",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Клиент не аутентифицирован",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Нет доступа к кошельку",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "404": {
+                        "description": "Кошелек или холд не найден",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "409": {
+                        "description": "Холд не активен",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "429": {
+                        "description": "Превышена частота запросов; повторить после Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/wallets/{walletId}/limits": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает уровень кошелька, его собственные ограничения и действующие ограничения:\nограничения уровня в валюте кошелька, замененные собственными. Суммы указаны в валюте кошелька;\nотсутствующее ограничение не действует.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "limits"
+                ],
+                "summary": "Ограничения кошелька",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "UUID кошелька",
+                        "name": "walletId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ограничения кошелька",
+                        "schema": {
+                            "$ref": "#/definitions/domain.WalletLimits"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный UUID",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Клиент не аутентифицирован",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Нет доступа к кошельку",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "404": {
+                        "description": "Кошелек не найден",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "429": {
+                        "description": "Превышена частота запросов; повторить после Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Заменяет уровень и собственные ограничения кошелька целиком: не указанное ограничение берется из уровня.\nОграничения: maxTransaction — сумма одной операции, dailyWithdrawal и monthlyWithdrawal — сумма списаний\nза календарный день и месяц (UTC), maxBalance — баланс после зачисления.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "limits"
+                ],
+                "summary": "Установка ограничений кошелька",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "UUID кошелька",
+                        "name": "walletId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Уровень и ограничения",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.WalletLimitsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Ограничения кошелька",
+                        "schema": {
+                            "$ref": "#/definitions/domain.WalletLimits"
+                        }
+                    },
+                    "400": {
+                        "description": "Ошибка валидации данных или уровень, не описанный для валюты кошелька",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Клиент не аутентифицирован",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Доступно только администраторам",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "404": {
+                        "description": "Кошелек не найден",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "429": {
+                        "description": "Превышена частота запросов; повторить после Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        },
+        "/wallets/{walletId}/transactions": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает операции кошелька от новых к старым с курсорной пагинацией и фильтрами",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transactions"
+                ],
+                "summary": "История операций кошелька",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "UUID кошелька",
+                        "name": "walletId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "DEPOSIT",
+                            "WITHDRAW",
+                            "TRANSFER",
+                            "CAPTURE",
+                            "REVERSAL",
+                            "REFUND"
+                        ],
+                        "type": "string",
+                        "description": "Тип операции",
+                        "name": "operationType",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Минимальная сумма операции (по модулю)",
+                        "name": "minAmount",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Максимальная сумма операции (по модулю)",
+                        "name": "maxAmount",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Начало периода (RFC3339, включительно)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Конец периода (RFC3339, не включительно)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Курсор следующей страницы",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Размер страницы (1-100, по умолчанию 20)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Страница истории операций",
+                        "schema": {
+                            "$ref": "#/definitions/domain.TransactionPage"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверные параметры запроса",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "401": {
+                        "description": "Клиент не аутентифицирован",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "403": {
+                        "description": "Нет доступа к кошельку",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "404": {
+                        "description": "Кошелек не найден",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "429": {
+                        "description": "Превышена частота запросов; повторить после Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/http.ProblemDetails"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "domain.AccountBalance": {
+            "type": "object",
+            "properties": {
+                "accountId": {
+                    "type": "string"
+                },
+                "balance": {
+                    "type": "number"
+                },
+                "code": {
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.CaptureRequest": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.Conversion": {
+            "type": "object",
+            "properties": {
+                "convertedAmount": {
+                    "type": "number"
+                },
+                "quoteId": {
+                    "type": "string"
+                },
+                "rate": {
+                    "type": "number"
+                },
+                "toCurrency": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.CreateWalletRequest": {
+            "type": "object",
+            "properties": {
+                "currency": {
+                    "type": "string"
+                },
+                "displayName": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "labels": {
+                    "type": "array",
+                    "maxItems": 32,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "metadata": {
+                    "type": "object",
+                    "additionalProperties": {}
+                },
+                "ownerId": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "shards": {
+                    "description": "Число шардов баланса для кошельков с большим потоком зачислений; 0 — обычный кошелек",
+                    "type": "integer",
+                    "maximum": 64,
+                    "minimum": 2
+                }
+            }
+        },
+        "domain.CurrencyTotal": {
+            "type": "object",
+            "properties": {
+                "currency": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "number"
+                }
+            }
+        },
+        "domain.FXQuote": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "expiresAt": {
+                    "type": "string"
+                },
+                "fromCurrency": {
+                    "type": "string"
+                },
+                "quoteId": {
+                    "type": "string"
+                },
+                "rate": {
+                    "type": "number"
+                },
+                "toCurrency": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.FXQuoteRequest": {
+            "type": "object",
+            "required": [
+                "fromCurrency",
+                "toCurrency"
+            ],
+            "properties": {
+                "fromCurrency": {
+                    "type": "string"
+                },
+                "toCurrency": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.FieldError": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.Hold": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "capturedAmount": {
+                    "type": "number"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "expiresAt": {
+                    "type": "string"
+                },
+                "holdId": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/domain.HoldStatus"
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "walletId": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.HoldCaptureResult": {
+            "type": "object",
+            "properties": {
+                "hold": {
+                    "$ref": "#/definitions/domain.Hold"
+                },
+                "transaction": {
+                    "$ref": "#/definitions/domain.Transaction"
+                }
+            }
+        },
+        "domain.HoldRequest": {
+            "type": "object",
+            "required": [
+                "amount"
+            ],
+            "properties": {
+                "amount": {
+                    "type": "string"
+                },
+                "ttlSeconds": {
+                    "description": "Время жизни холда в секундах; если не задано, используется значение из конфигурации",
+                    "type": "integer",
+                    "minimum": 1
+                }
+            }
+        },
+        "domain.HoldStatus": {
+            "type": "string",
+            "enum": [
+                "ACTIVE",
+                "CAPTURED",
+                "RELEASED",
+                "EXPIRED"
+            ],
+            "x-enum-varnames": [
+                "HoldActive",
+                "HoldCaptured",
+                "HoldReleased",
+                "HoldExpired"
+            ]
+        },
+        "domain.LedgerReport": {
+            "type": "object",
+            "properties": {
+                "balanced": {
+                    "type": "boolean"
+                },
+                "systemAccounts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.AccountBalance"
+                    }
+                },
+                "totals": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.CurrencyTotal"
+                    }
+                },
+                "unbalancedEntries": {
+                    "type": "integer"
+                },
+                "walletDiscrepancies": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.WalletDiscrepancy"
+                    }
+                }
+            }
+        },
+        "domain.LimitName": {
+            "type": "string",
+            "enum": [
+                "max_transaction",
+                "daily_withdrawal",
+                "monthly_withdrawal",
+                "max_balance"
+            ],
+            "x-enum-comments": {
+                "LimitDailyWithdrawal": "Сумма списаний за календарный день (UTC)",
+                "LimitMaxBalance": "Баланс кошелька после зачисления",
+                "LimitMaxTransaction": "Сумма одной операции",
+                "LimitMonthlyWithdrawal": "Сумма списаний за календарный месяц (UTC)"
+            },
+            "x-enum-varnames": [
+                "LimitMaxTransaction",
+                "LimitDailyWithdrawal",
+                "LimitMonthlyWithdrawal",
+                "LimitMaxBalance"
+            ]
+        },
+        "domain.OperationType": {
+            "type": "string",
+            "enum": [
+                "DEPOSIT",
+                "WITHDRAW",
+                "TRANSFER",
+                "CAPTURE",
+                "REVERSAL",
+                "REFUND"
+            ],
+            "x-enum-varnames": [
+                "Deposit",
+                "Withdraw",
+                "Transfer",
+                "Capture",
+                "Reversal",
+                "Refund"
+            ]
+        },
+        "domain.Role": {
+            "type": "string",
+            "enum": [
+                "owner",
+                "operator",
+                "viewer",
+                "admin"
+            ],
+            "x-enum-comments": {
+                "RoleAdmin": "Администратор: любые действия; выдается только в ключе API или токене",
+                "RoleOperator": "Оператор: операции над средствами и чтение",
+                "RoleOwner": "Владелец: операции над средствами, чтение и управление доступом к кошельку",
+                "RoleViewer": "Наблюдатель: только чтение баланса и истории"
+            },
+            "x-enum-varnames": [
+                "RoleOwner",
+                "RoleOperator",
+                "RoleViewer",
+                "RoleAdmin"
+            ]
+        },
+        "domain.Transaction": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "balanceAfter": {
+                    "description": "Баланс после операции; не заполняется для операций по шардированному кошельку,\nбаланс которого складывается из нескольких строк, меняющихся параллельно",
+                    "type": "number"
+                },
+                "counterAmount": {
+                    "type": "number"
+                },
+                "counterCurrency": {
+                    "type": "string"
+                },
+                "counterpartyWalletId": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "entryId": {
+                    "description": "Запись журнала двойной записи, которой проведена операция",
+                    "type": "string"
+                },
+                "exchangeRate": {
+                    "description": "Заполняются только для переводов с конвертацией: курс и сумма второй стороны перевода в ее валюте",
+                    "type": "number"
+                },
+                "operationType": {
+                    "$ref": "#/definitions/domain.OperationType"
+                },
+                "originalTransactionId": {
+                    "description": "Исходная операция для сторно и возвратов",
+                    "type": "string"
+                },
+                "transactionId": {
+                    "type": "string"
+                },
+                "transferId": {
+                    "description": "Заполняются только для переводов между кошельками",
+                    "type": "string"
+                },
+                "walletId": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.TransactionLimits": {
+            "type": "object",
+            "properties": {
+                "dailyWithdrawal": {
+                    "type": "number"
+                },
+                "maxBalance": {
+                    "type": "number"
+                },
+                "maxTransaction": {
+                    "type": "number"
+                },
+                "monthlyWithdrawal": {
+                    "type": "number"
+                }
+            }
+        },
+        "domain.TransactionPage": {
+            "type": "object",
+            "properties": {
+                "nextCursor": {
+                    "type": "string"
+                },
+                "transactions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Transaction"
+                    }
+                }
+            }
+        },
+        "domain.TransferOperation": {
+            "type": "object",
+            "required": [
+                "amount",
+                "fromWalletId",
+                "toWalletId"
+            ],
+            "properties": {
+                "amount": {
+                    "type": "string"
+                },
+                "currency": {
+                    "description": "Валюта списания; если указана, должна совпадать с валютой кошелька отправителя",
+                    "type": "string"
+                },
+                "fromWalletId": {
+                    "type": "string"
+                },
+                "quoteId": {
+                    "description": "Котировка для перевода между кошельками в разных валютах",
+                    "type": "string"
+                },
+                "toWalletId": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.TransferResult": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "conversion": {
+                    "description": "Заполняется для перевода с конвертацией; сумма зачисления указана в валюте получателя",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.Conversion"
+                        }
+                    ]
+                },
+                "credit": {
+                    "$ref": "#/definitions/domain.Transaction"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "debit": {
+                    "$ref": "#/definitions/domain.Transaction"
+                },
+                "fromWalletId": {
+                    "type": "string"
+                },
+                "toWalletId": {
+                    "type": "string"
+                },
+                "transferId": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.Wallet": {
+            "type": "object",
+            "properties": {
+                "balance": {
+                    "type": "number"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "displayName": {
+                    "type": "string"
+                },
+                "labels": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "metadata": {
+                    "type": "object",
+                    "additionalProperties": {}
+                },
+                "ownerId": {
+                    "description": "Владелец кошелька во внешней системе клиентов и описательные данные",
+                    "type": "string"
+                },
+                "shards": {
+                    "description": "Число шардов баланса; 0 — обычный кошелек",
+                    "type": "integer"
+                },
+                "status": {
+                    "$ref": "#/definitions/domain.WalletStatus"
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "walletId": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.WalletBalance": {
+            "type": "object",
+            "properties": {
+                "available": {
+                    "type": "number"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "held": {
+                    "type": "number"
+                },
+                "status": {
+                    "$ref": "#/definitions/domain.WalletStatus"
+                },
+                "total": {
+                    "type": "number"
+                },
+                "version": {
+                    "description": "Версия кошелька, она же ETag ответа; 0 — кошелек не поддерживает If-Match (шардированный)",
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.WalletDiscrepancy": {
+            "type": "object",
+            "properties": {
+                "ledgerBalance": {
+                    "type": "number"
+                },
+                "projectedBalance": {
+                    "type": "number"
+                },
+                "walletId": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.WalletGrant": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "grantedBy": {
+                    "type": "string"
+                },
+                "role": {
+                    "$ref": "#/definitions/domain.Role"
+                },
+                "subject": {
+                    "type": "string"
+                },
+                "walletId": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.WalletGrantRequest": {
+            "type": "object",
+            "required": [
+                "role"
+            ],
+            "properties": {
+                "role": {
+                    "enum": [
+                        "owner",
+                        "operator",
+                        "viewer"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.Role"
+                        }
+                    ]
+                }
+            }
+        },
+        "domain.WalletGrants": {
+            "type": "object",
+            "properties": {
+                "grants": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.WalletGrant"
+                    }
+                },
+                "walletId": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.WalletLimits": {
+            "type": "object",
+            "properties": {
+                "currency": {
+                    "description": "Валюта кошелька: в ней заданы все суммы ограничений",
+                    "type": "string"
+                },
+                "effective": {
+                    "description": "Effective заполняет сервис: ограничения уровня, замененные собственными ограничениями кошелька",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.TransactionLimits"
+                        }
+                    ]
+                },
+                "overrides": {
+                    "$ref": "#/definitions/domain.TransactionLimits"
+                },
+                "tier": {
+                    "description": "Уровень, назначенный кошельку; пустой — уровень по умолчанию из конфигурации",
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "walletId": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.WalletLimitsRequest": {
+            "type": "object",
+            "properties": {
+                "dailyWithdrawal": {
+                    "type": "string"
+                },
+                "maxBalance": {
+                    "type": "string"
+                },
+                "maxTransaction": {
+                    "type": "string"
+                },
+                "monthlyWithdrawal": {
+                    "type": "string"
+                },
+                "tier": {
+                    "type": "string",
+                    "maxLength": 64
+                }
+            }
+        },
+        "domain.WalletOperation": {
+            "type": "object",
+            "required": [
+                "operationType",
                 "walletId"
             ],
             "properties": {
                 "amount": {
                     "type": "string"
                 },
+                "currency": {
+                    "description": "Валюта операции; если указана, должна совпадать с валютой кошелька",
+                    "type": "string"
+                },
                 "operationType": {
                     "enum": [
                         "DEPOSIT",
-                        "WITHDRAW"
+                        "WITHDRAW",
+                        "REVERSAL",
+                        "REFUND"
                     ],
                     "allOf": [
                         {
@@ -200,27 +2402,140 @@ const docTemplate = `{
                         }
                     ]
                 },
+                "transactionId": {
+                    "description": "Исходная операция для REVERSAL и REFUND",
+                    "type": "string"
+                },
+                "walletId": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.WalletState": {
+            "type": "object",
+            "properties": {
+                "reason": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/domain.WalletStatus"
+                },
+                "statusChangedAt": {
+                    "type": "string"
+                },
                 "walletId": {
                     "type": "string"
                 }
             }
         },
-        "http.ErrorResponse": {
+        "domain.WalletStatus": {
+            "type": "string",
+            "enum": [
+                "ACTIVE",
+                "FROZEN",
+                "BLOCKED",
+                "CLOSED"
+            ],
+            "x-enum-comments": {
+                "WalletActive": "Разрешены все операции",
+                "WalletBlocked": "Запрещены любые движения средств",
+                "WalletClosed": "Кошелек закрыт окончательно",
+                "WalletFrozen": "Запрещены списания, зачисления разрешены"
+            },
+            "x-enum-varnames": [
+                "WalletActive",
+                "WalletFrozen",
+                "WalletBlocked",
+                "WalletClosed"
+            ]
+        },
+        "domain.WalletStatusRequest": {
+            "type": "object",
+            "properties": {
+                "reason": {
+                    "type": "string",
+                    "maxLength": 500
+                }
+            }
+        },
+        "domain.WalletsByOwner": {
             "type": "object",
             "properties": {
-                "error": {
+                "ownerId": {
                     "type": "string"
+                },
+                "wallets": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Wallet"
+                    }
                 }
             }
         },
-        "http.SuccessResponse": {
+        "http.OperationResponse": {
             "type": "object",
             "properties": {
                 "message": {
                     "type": "string"
+                },
+                "transaction": {
+                    "$ref": "#/definitions/domain.Transaction"
+                }
+            }
+        },
+        "http.ProblemDetails": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "detail": {
+                    "type": "string"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.FieldError"
+                    }
+                },
+                "instance": {
+                    "type": "string"
+                },
+                "limit": {
+                    "description": "Limit — ограничение операций кошелька, которое превысила операция; только для limit_exceeded",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.LimitName"
+                        }
+                    ]
+                },
+                "requestId": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "integer"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
                 }
             }
         }
+    },
+    "securityDefinitions": {
+        "ApiKeyAuth": {
+            "type": "apiKey",
+            "name": "X-API-Key",
+            "in": "header"
+        },
+        "BearerAuth": {
+            "description": "Токен JWT в формате \"Bearer \u003ctoken\u003e\"",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
+        }
     }
 }`
 
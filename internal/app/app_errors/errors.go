@@ -1,9 +1,77 @@
 package app_errors
 
-import "errors"
-
+// Ошибки предметной области. Каждая ошибка имеет вид (Kind), по которому выбирается
+// HTTP-статус, и стабильный машиночитаемый код, который клиенты могут использовать в логике.
 var (
-	ErrInsufficientFunds    = errors.New("insufficient funds")
-	ErrAmountMustBePositive = errors.New("amount must be greater than zero")
-	ErrInvalidAmount        = errors.New("invalid amount format")
+	ErrInsufficientFunds    = New(KindUnprocessable, "insufficient_funds", "insufficient funds")
+	ErrAmountMustBePositive = New(KindValidation, "amount_not_positive", "amount must be greater than zero")
+	ErrInvalidAmount        = New(KindValidation, "invalid_amount", "invalid amount format")
+	ErrAmountOutOfRange     = New(KindValidation, "amount_out_of_range", "amount exceeds the supported precision")
+	ErrWalletNotFound       = New(KindNotFound, "wallet_not_found", "wallet not found")
+	ErrInvalidAmountRange   = New(KindValidation, "invalid_amount_range", "minAmount must not be greater than maxAmount")
+	ErrInvalidTimeRange     = New(KindValidation, "invalid_time_range", "from must not be after to")
+	ErrInvalidCursor        = New(KindValidation, "invalid_cursor", "invalid cursor")
+	ErrIdempotencyKeyReused = New(KindUnprocessable, "idempotency_key_reused", "idempotency key was already used with a different request")
+	ErrSameWalletTransfer   = New(KindValidation, "same_wallet_transfer", "cannot transfer to the same wallet")
+	ErrUnbalancedEntry      = New(KindInternal, "unbalanced_entry", "journal entry postings must be non-zero and sum to zero")
+	ErrHoldNotFound         = New(KindNotFound, "hold_not_found", "hold not found")
+	ErrHoldNotActive        = New(KindConflict, "hold_not_active", "hold is not active")
+	ErrCaptureExceedsHold   = New(KindValidation, "capture_exceeds_hold", "capture amount exceeds held amount")
+	ErrHoldTTLTooLong       = New(KindValidation, "hold_ttl_too_long", "hold ttl exceeds the maximum allowed")
+
+	ErrTransactionNotFound           = New(KindNotFound, "transaction_not_found", "transaction not found")
+	ErrOriginalTransactionRequired   = New(KindValidation, "original_transaction_required", "transactionId is required for REVERSAL and REFUND")
+	ErrOriginalTransactionNotAllowed = New(KindValidation, "original_transaction_not_allowed", "transactionId is allowed only for REVERSAL and REFUND")
+	ErrReversalAmountNotAllowed      = New(KindValidation, "reversal_amount_not_allowed", "amount must not be set for REVERSAL")
+	ErrTransactionNotReversible      = New(KindConflict, "transaction_not_reversible", "transaction cannot be reversed or refunded")
+	ErrRefundExceedsOriginal         = New(KindConflict, "refund_exceeds_original", "total refunded amount exceeds the original transaction amount")
+
+	ErrUnsupportedCurrency = New(KindValidation, "unsupported_currency", "unsupported currency")
+	ErrInvalidAmountScale  = New(KindValidation, "invalid_amount_scale", "amount has more decimal places than the currency allows")
+	ErrCurrencyMismatch    = New(KindValidation, "currency_mismatch", "operation currency does not match the wallet currency")
+
+	ErrSameCurrencyQuote       = New(KindValidation, "same_currency_quote", "quote currencies must differ")
+	ErrExchangeRateUnavailable = New(KindUnprocessable, "exchange_rate_unavailable", "exchange rate is not available for the currency pair")
+	ErrQuoteNotFound           = New(KindNotFound, "quote_not_found", "quote not found")
+	ErrQuoteNotActive          = New(KindConflict, "quote_not_active", "quote has expired or was already used")
+	ErrConvertedAmountTooSmall = New(KindValidation, "converted_amount_too_small", "converted amount rounds to zero")
+	ErrInvalidRoundingMode     = New(KindValidation, "invalid_rounding_mode", "invalid rounding mode")
+
+	ErrWalletFrozen            = New(KindConflict, "wallet_frozen", "wallet is frozen: debits are not allowed")
+	ErrWalletBlocked           = New(KindConflict, "wallet_blocked", "wallet is blocked: operations are not allowed")
+	ErrWalletClosed            = New(KindConflict, "wallet_closed", "wallet is closed")
+	ErrWalletBalanceNotZero    = New(KindConflict, "wallet_balance_not_zero", "wallet can be closed only with zero balance and no active holds")
+	ErrInvalidStatusTransition = New(KindConflict, "invalid_status_transition", "invalid wallet status transition")
+
+	ErrUnauthenticated      = New(KindUnauthorized, "unauthenticated", "authentication required: pass an API key in X-API-Key or a bearer token in Authorization")
+	ErrInvalidCredentials   = New(KindUnauthorized, "invalid_credentials", "invalid, expired or revoked credentials")
+	ErrAmbiguousCredentials = New(KindValidation, "ambiguous_credentials", "pass either an API key or a bearer token, not both")
+
+	ErrSignatureRequired = New(KindUnauthorized, "signature_required", "request must be signed with the client's signing key")
+	ErrInvalidSignature  = New(KindUnauthorized, "invalid_signature", "invalid request signature")
+	ErrSignatureExpired  = New(KindUnauthorized, "signature_expired", "request timestamp is outside the allowed window")
+	ErrRequestReplayed   = New(KindUnauthorized, "request_replayed", "request nonce was already used")
+
+	ErrRateLimited = New(KindRateLimited, "rate_limited", "too many requests, retry after the time in Retry-After")
+
+	ErrLimitExceeded    = New(KindUnprocessable, "limit_exceeded", "operation exceeds a wallet transaction limit")
+	ErrUnknownLimitTier = New(KindValidation, "unknown_limit_tier", "unknown wallet limit tier")
+
+	ErrForbidden     = New(KindForbidden, "forbidden", "principal is not allowed to perform this action")
+	ErrGrantNotFound = New(KindNotFound, "grant_not_found", "wallet access grant not found")
+
+	ErrPreconditionFailed = New(KindPrecondition, "precondition_failed", "wallet has been modified since it was read")
+	ErrInvalidIfMatch     = New(KindValidation, "invalid_if_match", "If-Match must be a single strong ETag or *")
+
+	ErrInvalidRequest   = New(KindValidation, "invalid_request", "invalid request format")
+	ErrInvalidUUID      = New(KindValidation, "invalid_uuid", "invalid UUID format")
+	ErrValidationFailed = New(KindValidation, "validation_failed", "request validation failed")
+	ErrOwnerIDRequired  = New(KindValidation, "owner_id_required", "ownerId query parameter is required")
+
+	// Обобщенные ошибки, в которые репозиторий переводит ошибки базы данных
+	ErrNotFound         = New(KindNotFound, "not_found", "resource not found")
+	ErrConflict         = New(KindConflict, "conflict", "resource already exists or was changed")
+	ErrConcurrentUpdate = New(KindConflict, "concurrent_update", "concurrent update, retry the request")
+	ErrUnavailable      = New(KindUnavailable, "service_unavailable", "storage is temporarily unavailable")
+	ErrInternal         = New(KindInternal, "internal_error", "internal error")
 )
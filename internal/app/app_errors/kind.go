@@ -0,0 +1,64 @@
+package app_errors
+
+import "errors"
+
+// Kind — категория ошибки, определяющая, как на нее реагировать клиенту
+type Kind string
+
+const (
+	KindValidation    Kind = "validation"    // Некорректный запрос
+	KindUnauthorized  Kind = "unauthorized"  // Клиент не аутентифицирован
+	KindForbidden     Kind = "forbidden"     // Клиенту не разрешено действие
+	KindNotFound      Kind = "not_found"     // Объект не существует
+	KindConflict      Kind = "conflict"      // Запрос противоречит текущему состоянию
+	KindPrecondition  Kind = "precondition"  // Не выполнено условие запроса (If-Match)
+	KindUnprocessable Kind = "unprocessable" // Запрос корректен, но не может быть выполнен
+	KindRateLimited   Kind = "rate_limited"  // Превышена частота запросов, запрос можно повторить позже
+	KindUnavailable   Kind = "unavailable"   // Временная недоступность, запрос можно повторить
+	KindInternal      Kind = "internal"      // Внутренняя ошибка
+)
+
+// Error — типизированная ошибка приложения со стабильным кодом
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+	// Исходная ошибка, например ошибка драйвера базы данных
+	Err error
+}
+
+// New создает ошибку вида kind с кодом code
+func New(kind Kind, code, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is сравнивает ошибки по коду, чтобы обертка Wrap совпадала с исходной ошибкой
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && e.Code == t.Code
+}
+
+// Wrap возвращает копию ошибки e с причиной cause
+func (e *Error) Wrap(cause error) *Error {
+	return &Error{Kind: e.Kind, Code: e.Code, Message: e.Message, Err: cause}
+}
+
+// As извлекает типизированную ошибку; для прочих ошибок возвращает ErrInternal
+func As(err error) *Error {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+	return ErrInternal.Wrap(err)
+}
@@ -0,0 +1,90 @@
+package http
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"wallet-app/internal/app/app_errors"
+	"wallet-app/internal/app/domain"
+	"wallet-app/internal/app/services"
+)
+
+const (
+	// AuthorizationHeader — заголовок с токеном JWT: Authorization: Bearer <token>
+	AuthorizationHeader = "Authorization"
+	// APIKeyHeader — заголовок с ключом API
+	APIKeyHeader = "X-API-Key"
+	// bearerScheme — схема авторизации для токенов JWT
+	bearerScheme = "Bearer"
+
+	// Заголовки подписи запроса HMAC-SHA256
+	SignatureKeyIDHeader     = "X-Signature-Key-Id"
+	SignatureTimestampHeader = "X-Signature-Timestamp"
+	SignatureNonceHeader     = "X-Signature-Nonce"
+	SignatureHeader          = "X-Signature"
+)
+
+// Authenticate требует от запроса ключ API или токен JWT. Клиент, от имени которого выполняется запрос,
+// сохраняется в контексте запроса (domain.PrincipalFromContext) для последующих проверок доступа.
+func Authenticate(auth services.Auth) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, err := authenticate(c, auth)
+		if err != nil {
+			if app_errors.As(err).Kind == app_errors.KindUnauthorized {
+				c.Header("WWW-Authenticate", bearerScheme+` realm="wallet-app"`)
+			}
+			newErrorResponse(c, err)
+			return
+		}
+
+		c.Request = c.Request.WithContext(domain.ContextWithPrincipal(c.Request.Context(), principal))
+		c.Next()
+	}
+}
+
+func authenticate(c *gin.Context, auth services.Auth) (domain.Principal, error) {
+	credentials := domain.Credentials{APIKey: c.GetHeader(APIKeyHeader)}
+
+	if authorization := c.GetHeader(AuthorizationHeader); authorization != "" {
+		scheme, token, ok := strings.Cut(authorization, " ")
+		if !ok || !strings.EqualFold(scheme, bearerScheme) || strings.TrimSpace(token) == "" {
+			return domain.Principal{}, app_errors.ErrInvalidCredentials.Wrap(errors.New("unsupported authorization scheme"))
+		}
+		credentials.BearerToken = strings.TrimSpace(token)
+	}
+
+	return auth.Authenticate(c.Request.Context(), credentials)
+}
+
+// VerifySignature проверяет подпись HMAC-SHA256 запроса (заголовки X-Signature-*) после аутентификации клиента.
+// Подписываются метод, путь с параметрами, метка времени, nonce и SHA-256 тела (domain.SignedRequest.StringToSign).
+// Тело читается только у подписанных запросов и возвращается в запрос для обработчика.
+func VerifySignature(auth services.Auth) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		req := domain.SignedRequest{
+			KeyID:     c.GetHeader(SignatureKeyIDHeader),
+			Timestamp: c.GetHeader(SignatureTimestampHeader),
+			Nonce:     c.GetHeader(SignatureNonceHeader),
+			Signature: c.GetHeader(SignatureHeader),
+			Method:    c.Request.Method,
+			Path:      c.Request.URL.RequestURI(),
+		}
+
+		if req.Signed() {
+			body, err := peekBody(c)
+			if err != nil {
+				newErrorResponse(c, app_errors.ErrInvalidRequest.Wrap(err))
+				return
+			}
+			req.Body = body
+		}
+
+		if err := auth.VerifySignature(c.Request.Context(), req); err != nil {
+			newErrorResponse(c, err)
+			return
+		}
+		c.Next()
+	}
+}
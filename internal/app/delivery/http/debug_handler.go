@@ -0,0 +1,18 @@
+package http
+
+import (
+	"expvar"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DebugVars отдает метрики процесса и репозитория (счетчики повторов транзакций, пакетов операций)
+// в формате expvar. Метрики раскрывают командную строку процесса, поэтому доступны только администраторам.
+func (h *Handler) DebugVars(c *gin.Context) {
+	if err := h.services.CheckDiagnostics(c.Request.Context()); err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	expvar.Handler().ServeHTTP(c.Writer, c.Request)
+}
@@ -0,0 +1,44 @@
+package http
+
+import (
+	"strconv"
+	"strings"
+
+	"wallet-app/internal/app/app_errors"
+)
+
+const (
+	// ETagHeader — заголовок с версией кошелька в ответе на чтение
+	ETagHeader = "ETag"
+	// IfMatchHeader — заголовок с версией кошелька, при которой операцию можно применить
+	IfMatchHeader = "If-Match"
+)
+
+// formatETag представляет версию кошелька сильным ETag
+func formatETag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// parseIfMatch разбирает заголовок If-Match. Пустой заголовок и * не ограничивают операцию (nil).
+// Слабые ETag не подходят: If-Match требует строгого сравнения.
+func parseIfMatch(header string) (*int64, error) {
+	header = strings.TrimSpace(header)
+	if header == "" || header == "*" {
+		return nil, nil
+	}
+
+	unquoted, ok := strings.CutPrefix(header, `"`)
+	if !ok {
+		return nil, app_errors.ErrInvalidIfMatch
+	}
+	unquoted, ok = strings.CutSuffix(unquoted, `"`)
+	if !ok {
+		return nil, app_errors.ErrInvalidIfMatch
+	}
+
+	version, err := strconv.ParseInt(unquoted, 10, 64)
+	if err != nil || version <= 0 {
+		return nil, app_errors.ErrInvalidIfMatch
+	}
+	return &version, nil
+}
@@ -0,0 +1,50 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"wallet-app/internal/app/app_errors"
+	"wallet-app/internal/app/domain"
+)
+
+// CreateQuote фиксирует курс для перевода между валютами.
+//
+// @Summary Котировка курса валют
+// @Description Фиксирует текущий курс пары валют на ограниченное время. Идентификатор котировки
+// @Description передается в quoteId перевода между кошельками в разных валютах; котировку можно использовать один раз.
+// @Tags fx
+// @Accept json
+// @Produce json
+// @Param request body domain.FXQuoteRequest true "Пара валют"
+// @Success 201 {object} domain.FXQuote "Котировка"
+// @Failure 400 {object} ProblemDetails "Ошибка валидации данных"
+// @Failure 401 {object} ProblemDetails "Клиент не аутентифицирован"
+// @Failure 422 {object} ProblemDetails "Курс для пары валют недоступен"
+// @Failure 429 {object} ProblemDetails "Превышена частота запросов; повторить после Retry-After"
+// @Failure 500 {object} ProblemDetails "Внутренняя ошибка сервера"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /fx/quotes [post]
+func (h *Handler) CreateQuote(c *gin.Context) {
+	var req domain.FXQuoteRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		newErrorResponse(c, app_errors.ErrInvalidRequest.Wrap(err))
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	quote, err := h.services.CreateQuote(c.Request.Context(), req)
+	if err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, quote)
+}
@@ -0,0 +1,144 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"wallet-app/internal/app/app_errors"
+	"wallet-app/internal/app/domain"
+)
+
+// maxSubjectLength — наибольшая длина идентификатора клиента, как у столбца wallet_grants.subject
+const maxSubjectLength = 255
+
+// ListGrants возвращает роли, выданные на кошелек.
+//
+// @Summary Доступ к кошельку
+// @Description Возвращает клиентов, которым выдана роль на кошелек. Владелец кошелька (ownerId) в списке не указывается.
+// @Tags grants
+// @Produce json
+// @Param walletId path string true "UUID кошелька"
+// @Success 200 {object} domain.WalletGrants "Выданные роли"
+// @Failure 400 {object} ProblemDetails "Неверный UUID"
+// @Failure 401 {object} ProblemDetails "Клиент не аутентифицирован"
+// @Failure 403 {object} ProblemDetails "Управлять доступом может только владелец кошелька или администратор"
+// @Failure 404 {object} ProblemDetails "Кошелек не найден"
+// @Failure 429 {object} ProblemDetails "Превышена частота запросов; повторить после Retry-After"
+// @Failure 500 {object} ProblemDetails "Ошибка сервера"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /wallets/{walletId}/grants [get]
+func (h *Handler) ListGrants(c *gin.Context) {
+	walletUUID, err := uuid.Parse(c.Param("walletId"))
+	if err != nil {
+		newErrorResponse(c, app_errors.ErrInvalidUUID.Wrap(err))
+		return
+	}
+
+	grants, err := h.services.ListGrants(c.Request.Context(), walletUUID)
+	if err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, grants)
+}
+
+// GrantAccess выдает клиенту роль на кошелек.
+//
+// @Summary Выдача доступа к кошельку
+// @Description Выдает клиенту роль owner, operator или viewer на кошелек; выданная ранее роль заменяется.
+// @Description owner — операции, чтение и управление доступом, operator — операции и чтение, viewer — только чтение.
+// @Tags grants
+// @Accept json
+// @Produce json
+// @Param walletId path string true "UUID кошелька"
+// @Param subject path string true "Идентификатор клиента"
+// @Param request body domain.WalletGrantRequest true "Роль"
+// @Success 200 {object} domain.WalletGrant "Выданная роль"
+// @Failure 400 {object} ProblemDetails "Ошибка валидации данных"
+// @Failure 401 {object} ProblemDetails "Клиент не аутентифицирован"
+// @Failure 403 {object} ProblemDetails "Управлять доступом может только владелец кошелька или администратор"
+// @Failure 404 {object} ProblemDetails "Кошелек не найден"
+// @Failure 429 {object} ProblemDetails "Превышена частота запросов; повторить после Retry-After"
+// @Failure 500 {object} ProblemDetails "Ошибка сервера"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /wallets/{walletId}/grants/{subject} [put]
+func (h *Handler) GrantAccess(c *gin.Context) {
+	walletUUID, subject, ok := parseGrantPath(c)
+	if !ok {
+		return
+	}
+
+	var req domain.WalletGrantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		newErrorResponse(c, app_errors.ErrInvalidRequest.Wrap(err))
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	grant, err := h.services.GrantAccess(c.Request.Context(), walletUUID, subject, req)
+	if err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, grant)
+}
+
+// RevokeAccess отзывает роль клиента на кошелек.
+//
+// @Summary Отзыв доступа к кошельку
+// @Description Отзывает роль, выданную клиенту на кошелек. Владение кошельком (ownerId) так не отзывается.
+// @Tags grants
+// @Param walletId path string true "UUID кошелька"
+// @Param subject path string true "Идентификатор клиента"
+// @Success 204 "Роль отозвана"
+// @Failure 400 {object} ProblemDetails "Ошибка валидации данных"
+// @Failure 401 {object} ProblemDetails "Клиент не аутентифицирован"
+// @Failure 403 {object} ProblemDetails "Управлять доступом может только владелец кошелька или администратор"
+// @Failure 404 {object} ProblemDetails "Кошелек не найден или роль не выдавалась"
+// @Failure 429 {object} ProblemDetails "Превышена частота запросов; повторить после Retry-After"
+// @Failure 500 {object} ProblemDetails "Ошибка сервера"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /wallets/{walletId}/grants/{subject} [delete]
+func (h *Handler) RevokeAccess(c *gin.Context) {
+	walletUUID, subject, ok := parseGrantPath(c)
+	if !ok {
+		return
+	}
+
+	if err := h.services.RevokeAccess(c.Request.Context(), walletUUID, subject); err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// parseGrantPath разбирает кошелек и клиента из пути запроса; при ошибке ответ уже отправлен
+func parseGrantPath(c *gin.Context) (uuid.UUID, string, bool) {
+	walletUUID, err := uuid.Parse(c.Param("walletId"))
+	if err != nil {
+		newErrorResponse(c, app_errors.ErrInvalidUUID.Wrap(err))
+		return uuid.Nil, "", false
+	}
+
+	subject := c.Param("subject")
+	if subject == "" || utf8.RuneCountInString(subject) > maxSubjectLength {
+		newErrorResponse(c, app_errors.ErrInvalidRequest.Wrap(errors.New("subject must be 1 to 255 characters long")))
+		return uuid.Nil, "", false
+	}
+
+	return walletUUID, subject, true
+}
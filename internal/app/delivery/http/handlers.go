@@ -22,14 +22,50 @@ func NewHandler(services *services.Service) *Handler {
 
 func (h *Handler) InitRoutes() *gin.Engine {
 	router := gin.New()
+	router.Use(RequestID())
 	docs.SwaggerInfo.BasePath = "/api/v1"
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	// Все операции API доступны только аутентифицированным клиентам; клиенты с ключами подписи
+	// обязаны подписывать запросы. Частота запросов ограничивается до проверки подписи, чтобы
+	// превысивший ограничение клиент не нагружал хранилище nonce.
+	protected := []gin.HandlerFunc{Authenticate(h.services.Auth)}
+	if h.services.RateLimits != nil {
+		protected = append(protected, LimitClientRate(h.services.RateLimits))
+	}
+	protected = append(protected, VerifySignature(h.services.Auth))
 
-	wallet := router.Group("/api/v1")
+	wallet := router.Group("/api/v1", protected...)
 	{
 		wallet.POST("/create-wallet", h.CreateWallet)
-		wallet.POST("/wallet", h.ChangeBalance)
+		wallet.POST("/wallet", h.limitWalletDebits(withdrawalWallet), h.ChangeBalance)
+		wallet.POST("/transfer", h.limitWalletDebits(transferWallet), h.Transfer)
+		wallet.GET("/wallets", h.ListWalletsByOwner)
 		wallet.GET("/wallets/:walletId", h.GetBalance)
+		wallet.GET("/wallets/:walletId/transactions", h.ListTransactions)
+		wallet.POST("/wallets/:walletId/holds", h.limitWalletDebits(pathWallet), h.CreateHold)
+		wallet.POST("/wallets/:walletId/holds/:holdId/capture", h.limitWalletDebits(pathWallet), h.CaptureHold)
+		wallet.POST("/wallets/:walletId/holds/:holdId/release", h.ReleaseHold)
+		wallet.GET("/wallets/:walletId/grants", h.ListGrants)
+		wallet.PUT("/wallets/:walletId/grants/:subject", h.GrantAccess)
+		wallet.DELETE("/wallets/:walletId/grants/:subject", h.RevokeAccess)
+		wallet.GET("/wallets/:walletId/limits", h.GetLimits)
+		wallet.PUT("/wallets/:walletId/limits", h.SetLimits)
+		wallet.GET("/ledger/verify", h.VerifyLedger)
+		wallet.POST("/fx/quotes", h.CreateQuote)
+	}
+
+	admin := router.Group("/api/v1/admin", protected...)
+	{
+		admin.POST("/wallets/:walletId/freeze", h.FreezeWallet)
+		admin.POST("/wallets/:walletId/block", h.BlockWallet)
+		admin.POST("/wallets/:walletId/activate", h.ActivateWallet)
+		admin.POST("/wallets/:walletId/close", h.CloseWallet)
+	}
+
+	// Метрики процесса и репозитория в формате expvar
+	debug := router.Group("/debug", protected...)
+	{
+		debug.GET("/vars", h.DebugVars)
 	}
 	return router
 }
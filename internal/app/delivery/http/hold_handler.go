@@ -0,0 +1,161 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"wallet-app/internal/app/app_errors"
+	"wallet-app/internal/app/domain"
+)
+
+// CreateHold резервирует средства кошелька.
+//
+// @Summary Резервирование средств
+// @Description Создает холд: сумма уменьшает доступный остаток, но не баланс, пока холд не списан или не освобожден
+// @Tags holds
+// @Accept json
+// @Produce json
+// @Param walletId path string true "UUID кошелька"
+// @Param request body domain.HoldRequest true "Сумма и время жизни холда"
+// @Success 201 {object} domain.Hold "Созданный холд"
+// @Failure 400 {object} ProblemDetails "Ошибка валидации данных"
+// @Failure 401 {object} ProblemDetails "Клиент не аутентифицирован"
+// @Failure 403 {object} ProblemDetails "Нет доступа к кошельку"
+// @Failure 404 {object} ProblemDetails "Кошелек не найден"
+// @Failure 409 {object} ProblemDetails "Состояние кошелька запрещает списания или конкурентное изменение"
+// @Failure 422 {object} ProblemDetails "Недостаточно средств или превышено ограничение операций кошелька (limit)"
+// @Failure 429 {object} ProblemDetails "Превышена частота запросов; повторить после Retry-After"
+// @Failure 500 {object} ProblemDetails "Ошибка сервера"
+// @Failure 503 {object} ProblemDetails "Хранилище временно недоступно"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /wallets/{walletId}/holds [post]
+func (h *Handler) CreateHold(c *gin.Context) {
+	walletUUID, err := uuid.Parse(c.Param("walletId"))
+	if err != nil {
+		newErrorResponse(c, app_errors.ErrInvalidUUID.Wrap(err))
+		return
+	}
+
+	var req domain.HoldRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		newErrorResponse(c, app_errors.ErrInvalidRequest.Wrap(err))
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	hold, err := h.services.CreateHold(c.Request.Context(), walletUUID, req)
+	if err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, hold)
+}
+
+// CaptureHold списывает зарезервированные средства.
+//
+// @Summary Списание холда
+// @Description Списывает всю сумму холда или ее часть; остаток частичного списания освобождается
+// @Tags holds
+// @Accept json
+// @Produce json
+// @Param walletId path string true "UUID кошелька"
+// @Param holdId path string true "UUID холда"
+// @Param request body domain.CaptureRequest false "Сумма списания"
+// @Success 200 {object} domain.HoldCaptureResult "Холд списан"
+// @Failure 400 {object} ProblemDetails "Ошибка валидации данных"
+// @Failure 401 {object} ProblemDetails "Клиент не аутентифицирован"
+// @Failure 403 {object} ProblemDetails "Нет доступа к кошельку"
+// @Failure 404 {object} ProblemDetails "Кошелек или холд не найден"
+// @Failure 409 {object} ProblemDetails "Холд не активен или состояние кошелька запрещает списания"
+// @Failure 422 {object} ProblemDetails "Превышено ограничение операций кошелька (limit)"
+// @Failure 429 {object} ProblemDetails "Превышена частота запросов; повторить после Retry-After"
+// @Failure 500 {object} ProblemDetails "Ошибка сервера"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /wallets/{walletId}/holds/{holdId}/capture [post]
+func (h *Handler) CaptureHold(c *gin.Context) {
+	walletUUID, holdUUID, ok := parseHoldParams(c)
+	if !ok {
+		return
+	}
+
+	var req domain.CaptureRequest
+	// Тело запроса необязательно: без него списывается весь холд
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			newErrorResponse(c, app_errors.ErrInvalidRequest.Wrap(err))
+			return
+		}
+	}
+
+	if err := req.Validate(); err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	result, err := h.services.CaptureHold(c.Request.Context(), walletUUID, holdUUID, req)
+	if err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// ReleaseHold освобождает зарезервированные средства.
+//
+// @Summary Освобождение холда
+// @Description Снимает резерв без списания средств
+// @Tags holds
+// @Produce json
+// @Param walletId path string true "UUID кошелька"
+// @Param holdId path string true "UUID холда"
+// @Success 200 {object} domain.Hold "Холд освобожден"
+// @Failure 400 {object} ProblemDetails "Неверный UUID"
+// @Failure 401 {object} ProblemDetails "Клиент не аутентифицирован"
+// @Failure 403 {object} ProblemDetails "Нет доступа к кошельку"
+// @Failure 404 {object} ProblemDetails "Кошелек или холд не найден"
+// @Failure 409 {object} ProblemDetails "Холд не активен"
+// @Failure 429 {object} ProblemDetails "Превышена частота запросов; повторить после Retry-After"
+// @Failure 500 {object} ProblemDetails "Ошибка сервера"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /wallets/{walletId}/holds/{holdId}/release [post]
+func (h *Handler) ReleaseHold(c *gin.Context) {
+	walletUUID, holdUUID, ok := parseHoldParams(c)
+	if !ok {
+		return
+	}
+
+	hold, err := h.services.ReleaseHold(c.Request.Context(), walletUUID, holdUUID)
+	if err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, hold)
+}
+
+func parseHoldParams(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	walletUUID, err := uuid.Parse(c.Param("walletId"))
+	if err != nil {
+		newErrorResponse(c, app_errors.ErrInvalidUUID.Wrap(err))
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	holdUUID, err := uuid.Parse(c.Param("holdId"))
+	if err != nil {
+		newErrorResponse(c, app_errors.ErrInvalidUUID.Wrap(err))
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return walletUUID, holdUUID, true
+}
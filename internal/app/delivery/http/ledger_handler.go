@@ -0,0 +1,32 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// VerifyLedger сверяет журнал двойной записи.
+//
+// @Summary Сверка журнала
+// @Description Проверяет, что сумма проводок в каждой валюте равна нулю, каждая запись сбалансирована,
+// @Description а балансы кошельков совпадают с суммами их проводок
+// @Tags ledger
+// @Produce json
+// @Success 200 {object} domain.LedgerReport "Результат сверки"
+// @Failure 401 {object} ProblemDetails "Клиент не аутентифицирован"
+// @Failure 403 {object} ProblemDetails "Доступно только администраторам"
+// @Failure 429 {object} ProblemDetails "Превышена частота запросов; повторить после Retry-After"
+// @Failure 500 {object} ProblemDetails "Ошибка сервера"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /ledger/verify [get]
+func (h *Handler) VerifyLedger(c *gin.Context) {
+	report, err := h.services.VerifyLedger(c.Request.Context())
+	if err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
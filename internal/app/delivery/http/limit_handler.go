@@ -0,0 +1,94 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"wallet-app/internal/app/app_errors"
+	"wallet-app/internal/app/domain"
+)
+
+// GetLimits возвращает ограничения операций кошелька.
+//
+// @Summary Ограничения кошелька
+// @Description Возвращает уровень кошелька, его собственные ограничения и действующие ограничения:
+// @Description ограничения уровня в валюте кошелька, замененные собственными. Суммы указаны в валюте кошелька;
+// @Description отсутствующее ограничение не действует.
+// @Tags limits
+// @Produce json
+// @Param walletId path string true "UUID кошелька"
+// @Success 200 {object} domain.WalletLimits "Ограничения кошелька"
+// @Failure 400 {object} ProblemDetails "Неверный UUID"
+// @Failure 401 {object} ProblemDetails "Клиент не аутентифицирован"
+// @Failure 403 {object} ProblemDetails "Нет доступа к кошельку"
+// @Failure 404 {object} ProblemDetails "Кошелек не найден"
+// @Failure 429 {object} ProblemDetails "Превышена частота запросов; повторить после Retry-After"
+// @Failure 500 {object} ProblemDetails "Ошибка сервера"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /wallets/{walletId}/limits [get]
+func (h *Handler) GetLimits(c *gin.Context) {
+	walletUUID, err := uuid.Parse(c.Param("walletId"))
+	if err != nil {
+		newErrorResponse(c, app_errors.ErrInvalidUUID.Wrap(err))
+		return
+	}
+
+	limits, err := h.services.GetLimits(c.Request.Context(), walletUUID)
+	if err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, limits)
+}
+
+// SetLimits назначает кошельку уровень и собственные ограничения операций.
+//
+// @Summary Установка ограничений кошелька
+// @Description Заменяет уровень и собственные ограничения кошелька целиком: не указанное ограничение берется из уровня.
+// @Description Ограничения: maxTransaction — сумма одной операции, dailyWithdrawal и monthlyWithdrawal — сумма списаний
+// @Description за календарный день и месяц (UTC), maxBalance — баланс после зачисления.
+// @Tags limits
+// @Accept json
+// @Produce json
+// @Param walletId path string true "UUID кошелька"
+// @Param request body domain.WalletLimitsRequest true "Уровень и ограничения"
+// @Success 200 {object} domain.WalletLimits "Ограничения кошелька"
+// @Failure 400 {object} ProblemDetails "Ошибка валидации данных или уровень, не описанный для валюты кошелька"
+// @Failure 401 {object} ProblemDetails "Клиент не аутентифицирован"
+// @Failure 403 {object} ProblemDetails "Доступно только администраторам"
+// @Failure 404 {object} ProblemDetails "Кошелек не найден"
+// @Failure 429 {object} ProblemDetails "Превышена частота запросов; повторить после Retry-After"
+// @Failure 500 {object} ProblemDetails "Ошибка сервера"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /wallets/{walletId}/limits [put]
+func (h *Handler) SetLimits(c *gin.Context) {
+	walletUUID, err := uuid.Parse(c.Param("walletId"))
+	if err != nil {
+		newErrorResponse(c, app_errors.ErrInvalidUUID.Wrap(err))
+		return
+	}
+
+	var req domain.WalletLimitsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		newErrorResponse(c, app_errors.ErrInvalidRequest.Wrap(err))
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	limits, err := h.services.SetLimits(c.Request.Context(), walletUUID, req)
+	if err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, limits)
+}
@@ -0,0 +1,48 @@
+package http
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	// RequestIDHeader — заголовок с идентификатором запроса
+	RequestIDHeader = "X-Request-ID"
+	// requestIDKey — ключ идентификатора запроса в контексте gin
+	requestIDKey = "requestId"
+	// maxInspectedBodyBytes — наибольшее тело запроса, которое middleware читает целиком до обработчика
+	maxInspectedBodyBytes = 1 << 20
+)
+
+// RequestID присваивает запросу идентификатор: берет его из заголовка X-Request-ID или генерирует новый.
+// Идентификатор возвращается в ответе и попадает в тело ошибок, чтобы по нему можно было найти запрос в логах.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if requestID == "" || len(requestID) > 128 {
+			requestID = uuid.NewString()
+		}
+
+		c.Set(requestIDKey, requestID)
+		c.Header(RequestIDHeader, requestID)
+		c.Next()
+	}
+}
+
+// peekBody читает тело запроса и возвращает его в запрос, чтобы обработчик прочитал его еще раз
+func peekBody(c *gin.Context) ([]byte, error) {
+	if c.Request.Body == nil {
+		return nil, nil
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxInspectedBodyBytes))
+	if err != nil {
+		return nil, err
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
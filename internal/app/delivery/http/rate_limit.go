@@ -0,0 +1,96 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"wallet-app/internal/app/app_errors"
+	"wallet-app/internal/app/domain"
+	"wallet-app/internal/app/services"
+)
+
+// RetryAfterHeader — заголовок с числом секунд, через которое запрос можно повторить
+const RetryAfterHeader = "Retry-After"
+
+// LimitClientRate ограничивает частоту запросов аутентифицированного клиента
+func LimitClientRate(limits services.RateLimits) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		decision := limits.AllowRequest(c.Request.Context())
+		if !decision.Allowed {
+			rejectRateLimited(c, decision, "client")
+			return
+		}
+		c.Next()
+	}
+}
+
+// LimitWalletDebits ограничивает частоту списаний с кошелька, который определяет debitWallet;
+// запросы, которые ничего не списывают, не учитываются
+func LimitWalletDebits(limits services.RateLimits, debitWallet func(c *gin.Context) (uuid.UUID, bool)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		walletID, ok := debitWallet(c)
+		if !ok {
+			c.Next()
+			return
+		}
+
+		decision := limits.AllowWalletDebit(c.Request.Context(), walletID)
+		if !decision.Allowed {
+			rejectRateLimited(c, decision, "wallet debit")
+			return
+		}
+		c.Next()
+	}
+}
+
+func rejectRateLimited(c *gin.Context, decision domain.RateDecision, scope string) {
+	// Retry-After передается в целых секундах, поэтому округляется вверх
+	seconds := int(math.Max(1, math.Ceil(decision.RetryAfter.Seconds())))
+	c.Header(RetryAfterHeader, strconv.Itoa(seconds))
+	newErrorResponse(c, app_errors.ErrRateLimited.Wrap(fmt.Errorf("%s rate limit exceeded", scope)))
+}
+
+// limitWalletDebits возвращает LimitWalletDebits или пропускает запрос, если ограничение частоты выключено
+func (h *Handler) limitWalletDebits(debitWallet func(c *gin.Context) (uuid.UUID, bool)) gin.HandlerFunc {
+	if h.services.RateLimits == nil {
+		return func(c *gin.Context) { c.Next() }
+	}
+	return LimitWalletDebits(h.services.RateLimits, debitWallet)
+}
+
+// withdrawalWallet — кошелек, с которого могут списываться средства в POST /wallet: снятие, а также сторно
+// и возврат, направление которых определяет исходная операция — ее здесь не читают, поэтому они учитываются
+// как списания. Пополнения и некорректное тело не учитываются: последнее отклонит обработчик.
+func withdrawalWallet(c *gin.Context) (uuid.UUID, bool) {
+	var op domain.WalletOperation
+	if !peekJSON(c, &op) || (op.OperationType != domain.Withdraw && !op.IsCorrection()) {
+		return uuid.Nil, false
+	}
+	return op.WalletID, true
+}
+
+// transferWallet — кошелек-отправитель в POST /transfer
+func transferWallet(c *gin.Context) (uuid.UUID, bool) {
+	var op domain.TransferOperation
+	if !peekJSON(c, &op) {
+		return uuid.Nil, false
+	}
+	return op.FromWalletID, true
+}
+
+// pathWallet — кошелек из пути запроса, например при создании и списании холда
+func pathWallet(c *gin.Context) (uuid.UUID, bool) {
+	walletID, err := uuid.Parse(c.Param("walletId"))
+	return walletID, err == nil
+}
+
+// peekJSON разбирает тело запроса в v, оставляя его для обработчика
+func peekJSON(c *gin.Context, v any) bool {
+	body, err := peekBody(c)
+	return err == nil && json.Unmarshal(body, v) == nil
+}
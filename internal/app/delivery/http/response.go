@@ -1,21 +1,117 @@
 package http
 
 import (
+	"errors"
+	"net/http"
+	"strings"
+
 	"github.com/gin-gonic/gin"
 	logger "github.com/sirupsen/logrus"
+
+	"wallet-app/internal/app/app_errors"
+	"wallet-app/internal/app/domain"
 )
 
 type SuccessResponse struct {
 	Message string `json:"message"`
 }
 
-type ErrorResponse struct {
-	Message string `json:"error"`
+type OperationResponse struct {
+	Message     string             `json:"message"`
+	Transaction domain.Transaction `json:"transaction"`
 }
 
-func newErrorResponse(c *gin.Context, statusCode int, logMessage, userMessage string) {
-	logger.Error(logMessage)
+const (
+	// problemContentType — тип содержимого ответа с ошибкой по RFC 7807
+	problemContentType = "application/problem+json"
+	// problemTypePrefix — префикс URI типа ошибки; за ним следует стабильный код ошибки
+	problemTypePrefix = "urn:wallet-app:problem:"
+)
+
+// ProblemDetails — тело ответа с ошибкой по RFC 7807.
+// Клиенты ветвятся по type (или code), а не по тексту title и detail.
+type ProblemDetails struct {
+	Type      string              `json:"type"`
+	Title     string              `json:"title"`
+	Status    int                 `json:"status"`
+	Detail    string              `json:"detail,omitempty"`
+	Instance  string              `json:"instance,omitempty"`
+	Code      string              `json:"code"`
+	RequestID string              `json:"requestId,omitempty"`
+	Errors    []domain.FieldError `json:"errors,omitempty"`
+	// Limit — ограничение операций кошелька, которое превысила операция; только для limit_exceeded
+	Limit domain.LimitName `json:"limit,omitempty"`
+}
+
+// statusByKind — HTTP-статус для каждого вида ошибки приложения
+var statusByKind = map[app_errors.Kind]int{
+	app_errors.KindValidation:    http.StatusBadRequest,
+	app_errors.KindUnauthorized:  http.StatusUnauthorized,
+	app_errors.KindForbidden:     http.StatusForbidden,
+	app_errors.KindNotFound:      http.StatusNotFound,
+	app_errors.KindConflict:      http.StatusConflict,
+	app_errors.KindPrecondition:  http.StatusPreconditionFailed,
+	app_errors.KindUnprocessable: http.StatusUnprocessableEntity,
+	app_errors.KindRateLimited:   http.StatusTooManyRequests,
+	app_errors.KindUnavailable:   http.StatusServiceUnavailable,
+	app_errors.KindInternal:      http.StatusInternalServerError,
+}
+
+// newErrorResponse отвечает ошибкой в формате application/problem+json: статус выбирается по виду ошибки
+// приложения, type и code — по ее коду. Причина показывается клиенту только для ошибок валидации;
+// внутренние детали остаются в логе.
+func newErrorResponse(c *gin.Context, err error) {
+	fieldErrors := domain.ParseValidationErrors(err)
+	if fieldErrors != nil {
+		err = app_errors.ErrValidationFailed.Wrap(err)
+	}
+
+	appErr := app_errors.As(err)
+	status, ok := statusByKind[appErr.Kind]
+	if !ok {
+		status = http.StatusInternalServerError
+	}
+
+	problem := ProblemDetails{
+		Type:      problemTypePrefix + appErr.Code,
+		Title:     appErr.Message,
+		Status:    status,
+		Instance:  c.Request.URL.Path,
+		Code:      appErr.Code,
+		RequestID: c.GetString(requestIDKey),
+		Errors:    fieldErrors,
+	}
+	if appErr.Kind == app_errors.KindValidation {
+		problem.Detail = validationDetail(appErr, fieldErrors)
+	}
+	var violation *domain.LimitViolation
+	if errors.As(err, &violation) {
+		problem.Limit = violation.Limit
+		problem.Detail = violation.Error()
+	}
+
+	entry := logger.WithFields(logger.Fields{"request_id": problem.RequestID, "code": appErr.Code})
+	if status >= http.StatusInternalServerError {
+		entry.Error(err.Error())
+	} else {
+		entry.Warn(err.Error())
+	}
+
+	c.Header("Content-Type", problemContentType)
+	c.AbortWithStatusJSON(status, problem)
+}
 
-	errJSON := ErrorResponse{Message: userMessage}
-	c.AbortWithStatusJSON(statusCode, errJSON)
+// validationDetail описывает, что именно не так с запросом
+func validationDetail(appErr *app_errors.Error, fieldErrors []domain.FieldError) string {
+	if len(fieldErrors) > 0 {
+		messages := make([]string, 0, len(fieldErrors))
+		for _, fe := range fieldErrors {
+			messages = append(messages, fe.Message)
+		}
+		return strings.Join(messages, "; ")
+	}
+	if appErr.Err != nil {
+		return appErr.Err.Error()
+	}
+	return ""
 }
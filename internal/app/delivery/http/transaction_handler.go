@@ -0,0 +1,63 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"wallet-app/internal/app/app_errors"
+	"wallet-app/internal/app/domain"
+)
+
+// ListTransactions возвращает историю операций кошелька.
+//
+// @Summary История операций кошелька
+// @Description Возвращает операции кошелька от новых к старым с курсорной пагинацией и фильтрами
+// @Tags transactions
+// @Accept json
+// @Produce json
+// @Param walletId path string true "UUID кошелька"
+// @Param operationType query string false "Тип операции" Enums(DEPOSIT, WITHDRAW, TRANSFER, CAPTURE, REVERSAL, REFUND)
+// @Param minAmount query string false "Минимальная сумма операции (по модулю)"
+// @Param maxAmount query string false "Максимальная сумма операции (по модулю)"
+// @Param from query string false "Начало периода (RFC3339, включительно)"
+// @Param to query string false "Конец периода (RFC3339, не включительно)"
+// @Param cursor query string false "Курсор следующей страницы"
+// @Param limit query int false "Размер страницы (1-100, по умолчанию 20)"
+// @Success 200 {object} domain.TransactionPage "Страница истории операций"
+// @Failure 400 {object} ProblemDetails "Неверные параметры запроса"
+// @Failure 401 {object} ProblemDetails "Клиент не аутентифицирован"
+// @Failure 403 {object} ProblemDetails "Нет доступа к кошельку"
+// @Failure 404 {object} ProblemDetails "Кошелек не найден"
+// @Failure 429 {object} ProblemDetails "Превышена частота запросов; повторить после Retry-After"
+// @Failure 500 {object} ProblemDetails "Ошибка сервера"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /wallets/{walletId}/transactions [get]
+func (h *Handler) ListTransactions(c *gin.Context) {
+	walletUUID, err := uuid.Parse(c.Param("walletId"))
+	if err != nil {
+		newErrorResponse(c, app_errors.ErrInvalidUUID.Wrap(err))
+		return
+	}
+
+	var filter domain.TransactionFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		newErrorResponse(c, app_errors.ErrInvalidRequest.Wrap(err))
+		return
+	}
+
+	if err := filter.Validate(); err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	page, err := h.services.ListTransactions(c.Request.Context(), walletUUID, filter)
+	if err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
@@ -0,0 +1,57 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"wallet-app/internal/app/app_errors"
+	"wallet-app/internal/app/domain"
+)
+
+// Transfer переводит средства между кошельками.
+//
+// @Summary Перевод между кошельками
+// @Description Списывает сумму с одного кошелька и зачисляет на другой в одной транзакции.
+// @Description Перевод между кошельками в разных валютах выполняется по котировке (quoteId): сумма зачисления
+// @Description пересчитывается по зафиксированному курсу и округляется до точности валюты получателя.
+// @Tags wallets
+// @Accept json
+// @Produce json
+// @Param Idempotency-Key header string false "Ключ идемпотентности (до 255 символов)"
+// @Param request body domain.TransferOperation true "Данные перевода"
+// @Success 200 {object} domain.TransferResult "Перевод выполнен"
+// @Failure 400 {object} ProblemDetails "Ошибка валидации данных"
+// @Failure 401 {object} ProblemDetails "Клиент не аутентифицирован"
+// @Failure 403 {object} ProblemDetails "Нет доступа к кошельку-отправителю"
+// @Failure 404 {object} ProblemDetails "Кошелек или котировка не найдены"
+// @Failure 409 {object} ProblemDetails "Котировка истекла или уже использована, кошелек заморожен или закрыт"
+// @Failure 422 {object} ProblemDetails "Недостаточно средств, превышено ограничение операций кошелька (limit) или ключ идемпотентности использован с другими данными"
+// @Failure 429 {object} ProblemDetails "Превышена частота запросов; повторить после Retry-After"
+// @Failure 500 {object} ProblemDetails "Внутренняя ошибка сервера"
+// @Failure 503 {object} ProblemDetails "Хранилище временно недоступно"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /transfer [post]
+func (h *Handler) Transfer(c *gin.Context) {
+	var op domain.TransferOperation
+
+	if err := c.ShouldBindJSON(&op); err != nil {
+		newErrorResponse(c, app_errors.ErrInvalidRequest.Wrap(err))
+		return
+	}
+	op.IdempotencyKey = c.GetHeader(IdempotencyKeyHeader)
+
+	if err := op.Validate(); err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	result, err := h.services.Transfer(c.Request.Context(), op)
+	if err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
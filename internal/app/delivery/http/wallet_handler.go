@@ -6,43 +6,74 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 
+	"wallet-app/internal/app/app_errors"
 	"wallet-app/internal/app/domain"
 )
 
+// IdempotencyKeyHeader — заголовок с ключом идемпотентности операции
+const IdempotencyKeyHeader = "Idempotency-Key"
+
 // ChangeBalance обрабатывает изменение баланса кошелька.
 //
 // @Summary Изменение баланса кошелька
-// @Description Пополнение или снятие средств с кошелька
+// @Description Пополнение или снятие средств с кошелька, сторно (REVERSAL) или возврат (REFUND) исходной операции.
+// @Description Для REVERSAL и REFUND передается transactionId исходной операции; сумма всех возвратов не превышает ее сумму.
+// @Description Повторный запрос с тем же Idempotency-Key возвращает сохраненный результат без повторного изменения баланса.
+// @Description С заголовком If-Match (ETag из GET /wallets/{walletId}) операция применяется, только если кошелек с тех пор не менялся.
 // @Tags wallets
 // @Accept json
 // @Produce json
+// @Param Idempotency-Key header string false "Ключ идемпотентности (до 255 символов)"
+// @Param If-Match header string false "Ожидаемая версия кошелька (ETag)"
 // @Param request body domain.WalletOperation true "Данные операции"
-// @Success 200 {object} SuccessResponse "Операция выполнена"
-// @Failure 400 {object} ErrorResponse "Ошибка валидации данных"
-// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Success 200 {object} OperationResponse "Операция выполнена"
+// @Failure 400 {object} ProblemDetails "Ошибка валидации данных"
+// @Failure 401 {object} ProblemDetails "Клиент не аутентифицирован"
+// @Failure 403 {object} ProblemDetails "Нет доступа к кошельку"
+// @Failure 404 {object} ProblemDetails "Кошелек или исходная операция не найдены"
+// @Failure 409 {object} ProblemDetails "Операцию нельзя вернуть, сумма возврата превышает остаток или состояние кошелька запрещает операцию"
+// @Failure 412 {object} ProblemDetails "Кошелек изменился после чтения (If-Match)"
+// @Failure 422 {object} ProblemDetails "Недостаточно средств, превышено ограничение операций кошелька (limit) или ключ идемпотентности использован с другими данными"
+// @Failure 429 {object} ProblemDetails "Превышена частота запросов; повторить после Retry-After"
+// @Failure 500 {object} ProblemDetails "Внутренняя ошибка сервера"
+// @Failure 503 {object} ProblemDetails "Хранилище временно недоступно"
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /wallet [post]
 func (h *Handler) ChangeBalance(c *gin.Context) {
 	var op domain.WalletOperation
 
 	// Привязываем тело запроса к структуре
 	if err := c.ShouldBindJSON(&op); err != nil {
-		newErrorResponse(c, http.StatusBadRequest, err.Error(), "Invalid request format")
+		newErrorResponse(c, app_errors.ErrInvalidRequest.Wrap(err))
 		return
 	}
+	op.IdempotencyKey = c.GetHeader(IdempotencyKeyHeader)
+
+	expectedVersion, err := parseIfMatch(c.GetHeader(IfMatchHeader))
+	if err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+	op.ExpectedVersion = expectedVersion
 
 	// Валидация данных операции
 	if err := op.Validate(); err != nil {
-		newErrorResponse(c, http.StatusBadRequest, err.Error(), err.Error())
+		newErrorResponse(c, err)
 		return
 	}
 
 	// Обрабатываем операцию (пополнение или снятие)
-	if err := h.services.ProcessOperation(c.Request.Context(), op); err != nil {
-		newErrorResponse(c, http.StatusBadRequest, err.Error(), "Error in processing the operation")
+	transaction, err := h.services.ProcessOperation(c.Request.Context(), op)
+	if err != nil {
+		newErrorResponse(c, err)
 		return
 	}
 
-	response := SuccessResponse{Message: "Operation completed"}
+	response := OperationResponse{
+		Message:     "Operation completed",
+		Transaction: transaction,
+	}
 
 	c.JSON(http.StatusOK, response)
 }
@@ -50,54 +81,115 @@ func (h *Handler) ChangeBalance(c *gin.Context) {
 // GetBalance получает текущий баланс кошелька.
 //
 // @Summary Получение баланса кошелька
-// @Description Возвращает баланс указанного кошелька
+// @Description Возвращает доступный остаток, сумму холдов и полный баланс указанного кошелька.
+// @Description Версия кошелька возвращается в заголовке ETag; шардированный кошелек версию не возвращает.
 // @Tags wallets
 // @Accept json
 // @Produce json
 // @Param walletId path string true "UUID кошелька"
 // @Success 200 {object} domain.WalletBalance "Баланс кошелька"
-// @Failure 400 {object} ErrorResponse "Неверный UUID"
-// @Failure 404 {object} ErrorResponse "Кошелек не найден"
-// @Failure 500 {object} ErrorResponse "Ошибка сервера"
+// @Header 200 {string} ETag "Версия кошелька для If-Match"
+// @Failure 400 {object} ProblemDetails "Неверный UUID"
+// @Failure 401 {object} ProblemDetails "Клиент не аутентифицирован"
+// @Failure 403 {object} ProblemDetails "Нет доступа к кошельку"
+// @Failure 404 {object} ProblemDetails "Кошелек не найден"
+// @Failure 429 {object} ProblemDetails "Превышена частота запросов; повторить после Retry-After"
+// @Failure 500 {object} ProblemDetails "Ошибка сервера"
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /wallets/{walletId} [get]
 func (h *Handler) GetBalance(c *gin.Context) {
 	walletID := c.Param("walletId")
 	walletUUID, err := uuid.Parse(walletID)
 	if err != nil {
-		newErrorResponse(c, http.StatusForbidden, err.Error(), "Invalid UUID format")
+		newErrorResponse(c, app_errors.ErrInvalidUUID.Wrap(err))
 		return
 	}
 
 	balance, err := h.services.GetBalance(c.Request.Context(), walletUUID)
 	if err != nil {
-		newErrorResponse(c, http.StatusNotFound, err.Error(), "Wallet not found")
+		newErrorResponse(c, err)
 		return
 	}
 
-	response := domain.WalletBalance{
-		Balance: balance,
+	if balance.Version > 0 {
+		c.Header(ETagHeader, formatETag(balance.Version))
 	}
-
-	c.JSON(http.StatusOK, response)
+	c.JSON(http.StatusOK, balance)
 }
 
 // CreateWallet создает новый кошелек.
 //
 // @Summary Создание нового кошелька
-// @Description Генерирует новый кошелек с начальным балансом 0
+// @Description Генерирует новый кошелек с начальным балансом 0 в указанной валюте (по умолчанию RUB).
+// @Description Валюта кошелька задается при создании и не меняется. Можно указать владельца,
+// @Description отображаемое имя, произвольные метаданные (JSON-объект) и метки.
+// @Description Если владелец не указан, им становится клиент, создающий кошелек.
 // @Tags wallets
 // @Accept json
 // @Produce json
+// @Param request body domain.CreateWalletRequest false "Валюта (код ISO 4217), владелец и описательные данные"
 // @Success 201 {object} domain.Wallet "Созданный кошелек"
-// @Failure 400 {object} ErrorResponse "Ошибка при создании"
-// @Failure 500 {object} ErrorResponse "Ошибка сервера"
+// @Failure 400 {object} ProblemDetails "Ошибка валидации данных"
+// @Failure 401 {object} ProblemDetails "Клиент не аутентифицирован"
+// @Failure 403 {object} ProblemDetails "Нет прав создать кошелек другому владельцу"
+// @Failure 429 {object} ProblemDetails "Превышена частота запросов; повторить после Retry-After"
+// @Failure 500 {object} ProblemDetails "Ошибка сервера"
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /create-wallet [post]
 func (h *Handler) CreateWallet(c *gin.Context) {
-	wallet, err := h.services.CreateWallet(c.Request.Context())
+	var req domain.CreateWalletRequest
+	// Тело запроса необязательно: без него создается кошелек в валюте по умолчанию
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			newErrorResponse(c, app_errors.ErrInvalidRequest.Wrap(err))
+			return
+		}
+	}
+
+	if err := req.Validate(); err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	wallet, err := h.services.CreateWallet(c.Request.Context(), req)
 	if err != nil {
-		newErrorResponse(c, http.StatusBadRequest, err.Error(), "Wallet creation error")
+		newErrorResponse(c, err)
 		return
 	}
 
 	c.JSON(http.StatusCreated, wallet)
 }
+
+// ListWalletsByOwner возвращает кошельки владельца.
+//
+// @Summary Кошельки владельца
+// @Description Возвращает все кошельки указанного владельца в порядке создания
+// @Tags wallets
+// @Produce json
+// @Param ownerId query string true "Идентификатор владельца"
+// @Success 200 {object} domain.WalletsByOwner "Кошельки владельца"
+// @Failure 400 {object} ProblemDetails "Не указан владелец"
+// @Failure 401 {object} ProblemDetails "Клиент не аутентифицирован"
+// @Failure 403 {object} ProblemDetails "Нет доступа к кошелькам владельца"
+// @Failure 429 {object} ProblemDetails "Превышена частота запросов; повторить после Retry-After"
+// @Failure 500 {object} ProblemDetails "Ошибка сервера"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /wallets [get]
+func (h *Handler) ListWalletsByOwner(c *gin.Context) {
+	ownerID := c.Query("ownerId")
+	if ownerID == "" {
+		newErrorResponse(c, app_errors.ErrOwnerIDRequired)
+		return
+	}
+
+	wallets, err := h.services.ListWalletsByOwner(c.Request.Context(), ownerID)
+	if err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, wallets)
+}
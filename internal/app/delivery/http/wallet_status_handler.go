@@ -0,0 +1,137 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"wallet-app/internal/app/app_errors"
+	"wallet-app/internal/app/domain"
+)
+
+// FreezeWallet замораживает кошелек.
+//
+// @Summary Заморозка кошелька
+// @Description Запрещает списания с кошелька, зачисления остаются разрешены. Используется на время проверок.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param walletId path string true "UUID кошелька"
+// @Param request body domain.WalletStatusRequest false "Причина"
+// @Success 200 {object} domain.WalletState "Состояние кошелька"
+// @Failure 400 {object} ProblemDetails "Ошибка валидации данных"
+// @Failure 401 {object} ProblemDetails "Клиент не аутентифицирован"
+// @Failure 403 {object} ProblemDetails "Доступно только администраторам"
+// @Failure 404 {object} ProblemDetails "Кошелек не найден"
+// @Failure 409 {object} ProblemDetails "Кошелек закрыт"
+// @Failure 429 {object} ProblemDetails "Превышена частота запросов; повторить после Retry-After"
+// @Failure 500 {object} ProblemDetails "Ошибка сервера"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/wallets/{walletId}/freeze [post]
+func (h *Handler) FreezeWallet(c *gin.Context) {
+	h.setWalletStatus(c, domain.WalletFrozen)
+}
+
+// BlockWallet полностью блокирует кошелек.
+//
+// @Summary Блокировка кошелька
+// @Description Запрещает любые движения средств по кошельку
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param walletId path string true "UUID кошелька"
+// @Param request body domain.WalletStatusRequest false "Причина"
+// @Success 200 {object} domain.WalletState "Состояние кошелька"
+// @Failure 400 {object} ProblemDetails "Ошибка валидации данных"
+// @Failure 401 {object} ProblemDetails "Клиент не аутентифицирован"
+// @Failure 403 {object} ProblemDetails "Доступно только администраторам"
+// @Failure 404 {object} ProblemDetails "Кошелек не найден"
+// @Failure 409 {object} ProblemDetails "Кошелек закрыт"
+// @Failure 429 {object} ProblemDetails "Превышена частота запросов; повторить после Retry-After"
+// @Failure 500 {object} ProblemDetails "Ошибка сервера"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/wallets/{walletId}/block [post]
+func (h *Handler) BlockWallet(c *gin.Context) {
+	h.setWalletStatus(c, domain.WalletBlocked)
+}
+
+// ActivateWallet снимает заморозку или блокировку.
+//
+// @Summary Активация кошелька
+// @Description Возвращает замороженный или заблокированный кошелек в активное состояние
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param walletId path string true "UUID кошелька"
+// @Param request body domain.WalletStatusRequest false "Причина"
+// @Success 200 {object} domain.WalletState "Состояние кошелька"
+// @Failure 400 {object} ProblemDetails "Ошибка валидации данных"
+// @Failure 401 {object} ProblemDetails "Клиент не аутентифицирован"
+// @Failure 403 {object} ProblemDetails "Доступно только администраторам"
+// @Failure 404 {object} ProblemDetails "Кошелек не найден"
+// @Failure 409 {object} ProblemDetails "Кошелек закрыт"
+// @Failure 429 {object} ProblemDetails "Превышена частота запросов; повторить после Retry-After"
+// @Failure 500 {object} ProblemDetails "Ошибка сервера"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/wallets/{walletId}/activate [post]
+func (h *Handler) ActivateWallet(c *gin.Context) {
+	h.setWalletStatus(c, domain.WalletActive)
+}
+
+// CloseWallet закрывает кошелек.
+//
+// @Summary Закрытие кошелька
+// @Description Окончательно закрывает кошелек; возможно только при нулевом балансе и без активных холдов
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param walletId path string true "UUID кошелька"
+// @Param request body domain.WalletStatusRequest false "Причина"
+// @Success 200 {object} domain.WalletState "Состояние кошелька"
+// @Failure 400 {object} ProblemDetails "Ошибка валидации данных"
+// @Failure 401 {object} ProblemDetails "Клиент не аутентифицирован"
+// @Failure 403 {object} ProblemDetails "Доступно только администраторам"
+// @Failure 404 {object} ProblemDetails "Кошелек не найден"
+// @Failure 409 {object} ProblemDetails "Ненулевой баланс или кошелек уже закрыт"
+// @Failure 429 {object} ProblemDetails "Превышена частота запросов; повторить после Retry-After"
+// @Failure 500 {object} ProblemDetails "Ошибка сервера"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/wallets/{walletId}/close [post]
+func (h *Handler) CloseWallet(c *gin.Context) {
+	h.setWalletStatus(c, domain.WalletClosed)
+}
+
+func (h *Handler) setWalletStatus(c *gin.Context, status domain.WalletStatus) {
+	walletUUID, err := uuid.Parse(c.Param("walletId"))
+	if err != nil {
+		newErrorResponse(c, app_errors.ErrInvalidUUID.Wrap(err))
+		return
+	}
+
+	var req domain.WalletStatusRequest
+	// Тело запроса необязательно: причина может быть не указана
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			newErrorResponse(c, app_errors.ErrInvalidRequest.Wrap(err))
+			return
+		}
+	}
+
+	if err := req.Validate(); err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	state, err := h.services.SetWalletStatus(c.Request.Context(), walletUUID, status, req)
+	if err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, state)
+}
@@ -0,0 +1,92 @@
+package domain
+
+import (
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Role — роль клиента. Роли owner, operator и viewer выдаются на отдельный кошелек (WalletGrant);
+// роли из ключа API или токена (Principal.Roles) действуют на все кошельки.
+type Role string
+
+const (
+	RoleOwner    Role = "owner"    // Владелец: операции над средствами, чтение и управление доступом к кошельку
+	RoleOperator Role = "operator" // Оператор: операции над средствами и чтение
+	RoleViewer   Role = "viewer"   // Наблюдатель: только чтение баланса и истории
+	RoleAdmin    Role = "admin"    // Администратор: любые действия; выдается только в ключе API или токене
+)
+
+// Permission — действие, на которое проверяется доступ
+type Permission string
+
+const (
+	PermissionRead    Permission = "read"    // Баланс, история операций, список кошельков владельца
+	PermissionOperate Permission = "operate" // Движение средств: операции, переводы, холды
+	PermissionManage  Permission = "manage"  // Управление доступом к кошельку
+	PermissionAdmin   Permission = "admin"   // Смена состояния кошельков и сверка журнала
+)
+
+// rolePermissions — действия, разрешенные каждой роли
+var rolePermissions = map[Role][]Permission{
+	RoleOwner:    {PermissionRead, PermissionOperate, PermissionManage},
+	RoleOperator: {PermissionRead, PermissionOperate},
+	RoleViewer:   {PermissionRead},
+	RoleAdmin:    {PermissionRead, PermissionOperate, PermissionManage, PermissionAdmin},
+}
+
+// Allows сообщает, разрешает ли роль действие permission
+func (r Role) Allows(permission Permission) bool {
+	return slices.Contains(rolePermissions[r], permission)
+}
+
+// Allows сообщает, разрешают ли роли клиента действие permission над любым кошельком.
+// Роль owner относится только к конкретному кошельку, поэтому в ключе или токене не учитывается.
+func (p Principal) Allows(permission Permission) bool {
+	for _, role := range p.Roles {
+		if Role(role) != RoleOwner && Role(role).Allows(permission) {
+			return true
+		}
+	}
+	return false
+}
+
+// WalletAccess — сведения о доступе клиента к кошельку: владелец кошелька и выданная клиенту роль
+type WalletAccess struct {
+	OwnerID string
+	// Роль, выданная клиенту на кошелек; пустая, если доступ не выдавался
+	Role Role
+}
+
+// Allows сообщает, разрешено ли клиенту subject действие permission над кошельком
+func (a WalletAccess) Allows(subject string, permission Permission) bool {
+	if a.OwnerID != "" && a.OwnerID == subject {
+		return RoleOwner.Allows(permission)
+	}
+	return a.Role.Allows(permission)
+}
+
+// WalletGrant — роль, выданная клиенту subject на кошелек
+type WalletGrant struct {
+	WalletID  uuid.UUID `json:"walletId"`
+	Subject   string    `json:"subject"`
+	Role      Role      `json:"role"`
+	GrantedBy string    `json:"grantedBy,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// WalletGrants — все выданные роли на кошелек
+type WalletGrants struct {
+	WalletID uuid.UUID     `json:"walletId"`
+	Grants   []WalletGrant `json:"grants"`
+}
+
+// WalletGrantRequest — роль, которую нужно выдать клиенту на кошелек
+type WalletGrantRequest struct {
+	Role Role `json:"role" validate:"required,oneof=owner operator viewer"`
+}
+
+func (r *WalletGrantRequest) Validate() error {
+	return NewValidate.Struct(r)
+}
@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"regexp"
+
+	"github.com/shopspring/decimal"
+
+	"wallet-app/internal/app/app_errors"
+)
+
+const (
+	// MaxAmountScale — наибольшее число знаков после запятой, которое хранит база (NUMERIC(38, 18))
+	MaxAmountScale = 18
+	// MaxAmountIntegerDigits — наибольшее число значащих знаков до запятой (38 - 18)
+	MaxAmountIntegerDigits = 20
+)
+
+// amountPattern — допустимая запись суммы: знак, цифры и необязательная дробная часть.
+// Экспоненциальная запись, NaN, Inf и суммы без цифр до или после точки не принимаются.
+var amountPattern = regexp.MustCompile(`^[-+]?[0-9]+(\.[0-9]+)?$`)
+
+// amountLimit — наименьшая по модулю сумма, у которой больше MaxAmountIntegerDigits знаков до запятой
+var amountLimit = decimal.New(1, MaxAmountIntegerDigits)
+
+// ParseAmount разбирает сумму из запроса. В отличие от decimal.NewFromString, не принимает экспоненциальную
+// запись и суммы, которые не помещаются в колонки базы без округления или переполнения.
+func ParseAmount(value string) (decimal.Decimal, error) {
+	// Длина записи ограничена, чтобы разбор огромных строк не расходовал память и время
+	if len(value) > 1024 || !amountPattern.MatchString(value) {
+		return decimal.Zero, app_errors.ErrInvalidAmount
+	}
+
+	amount, err := decimal.NewFromString(value)
+	if err != nil {
+		return decimal.Zero, app_errors.ErrInvalidAmount
+	}
+
+	// Незначащие нули ("1.000…0", "000…01") допустимы: проверяется значение, а не запись
+	if !amount.Equal(amount.Truncate(MaxAmountScale)) || amount.Abs().GreaterThanOrEqual(amountLimit) {
+		return decimal.Zero, app_errors.ErrAmountOutOfRange
+	}
+
+	return amount, nil
+}
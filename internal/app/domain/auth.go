@@ -0,0 +1,85 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuthMethod — способ, которым клиент подтвердил свою личность
+type AuthMethod string
+
+const (
+	AuthAPIKey AuthMethod = "api_key" // Ключ API в заголовке X-API-Key
+	AuthJWT    AuthMethod = "jwt"     // Токен JWT в заголовке Authorization: Bearer
+)
+
+// Principal — аутентифицированный клиент, от имени которого выполняется запрос
+type Principal struct {
+	// Subject — идентификатор клиента: владелец ключа API или claim sub токена
+	Subject string
+	Method  AuthMethod
+	Roles   []string
+	// KeyID — ключ API, которым подписан запрос; nil для токенов
+	KeyID *uuid.UUID
+}
+
+// Credentials — учетные данные из запроса; заполняется ровно одно поле
+type Credentials struct {
+	APIKey      string
+	BearerToken string
+}
+
+// APIKey — ключ API. Сам ключ не хранится: по нему вычисляется хеш, а для опознания ключа
+// в списках и логах сохраняется его начало (Prefix).
+type APIKey struct {
+	ID        uuid.UUID  `json:"keyId"`
+	Name      string     `json:"name"`
+	Subject   string     `json:"subject"`
+	Roles     []string   `json:"roles"`
+	Prefix    string     `json:"prefix"`
+	CreatedAt time.Time  `json:"createdAt"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+	RevokedAt *time.Time `json:"revokedAt,omitempty"`
+}
+
+// Active сообщает, можно ли аутентифицироваться ключом в момент now
+func (k APIKey) Active(now time.Time) bool {
+	if k.RevokedAt != nil {
+		return false
+	}
+	return k.ExpiresAt == nil || now.Before(*k.ExpiresAt)
+}
+
+// APIKeyRequest — параметры выпуска ключа API
+type APIKeyRequest struct {
+	Name    string   `json:"name" validate:"required,max=255"`
+	Subject string   `json:"subject" validate:"required,max=255"`
+	Roles   []string `json:"roles" validate:"max=16,dive,min=1,max=64"`
+	// Срок действия ключа; 0 — бессрочный
+	TTL time.Duration `json:"-"`
+}
+
+func (r *APIKeyRequest) Validate() error {
+	return NewValidate.Struct(r)
+}
+
+// IssuedAPIKey — выпущенный ключ; Key показывается один раз и больше не может быть получен
+type IssuedAPIKey struct {
+	APIKey
+	Key string `json:"key"`
+}
+
+type principalContextKey struct{}
+
+// ContextWithPrincipal возвращает контекст, в котором запрос выполняется от имени principal
+func ContextWithPrincipal(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, principalContextKey{}, principal)
+}
+
+// PrincipalFromContext возвращает клиента, от имени которого выполняется запрос
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	principal, ok := ctx.Value(principalContextKey{}).(Principal)
+	return principal, ok
+}
@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// BalanceUpdate — подготовленное сервисом изменение баланса, которое репозиторий применяет в одной транзакции
+type BalanceUpdate struct {
+	WalletID      uuid.UUID
+	OperationType OperationType
+	Amount        decimal.Decimal // Сумма со знаком: отрицательная для списаний
+
+	// Ожидаемая валюта кошелька; пустая строка — без проверки
+	Currency string
+
+	// Для REVERSAL и REFUND: исходная операция; Amount тогда содержит модуль суммы возврата,
+	// а нулевая сумма означает возврат всего остатка
+	OriginalTransactionID *uuid.UUID
+
+	// Ключ идемпотентности и отпечаток запроса; пустой ключ отключает дедупликацию.
+	// Ключ уникален в пределах клиента IdempotencySubject; пустой subject — внутренний вызов.
+	IdempotencyKey     string
+	IdempotencySubject string
+	Fingerprint        string
+
+	// Версия кошелька из If-Match: операция применяется, только если кошелек с тех пор не менялся; nil — без проверки
+	ExpectedVersion *int64
+
+	// Действующие ограничения операций кошелька; проверяются под блокировкой кошелька
+	Limits TransactionLimits
+}
+
+// TransferUpdate — подготовленный сервисом перевод между кошельками
+type TransferUpdate struct {
+	FromWalletID uuid.UUID
+	ToWalletID   uuid.UUID
+	Amount       decimal.Decimal // Положительная сумма перевода
+	Currency     string          // Ожидаемая валюта кошелька отправителя; пустая строка — без проверки
+
+	// Конвертация по котировке для кошельков в разных валютах; nil — перевод в одной валюте
+	Conversion *Conversion
+
+	// Действующие ограничения операций отправителя и получателя
+	FromLimits TransactionLimits
+	ToLimits   TransactionLimits
+
+	// Ключ идемпотентности и отпечаток запроса; пустой ключ отключает дедупликацию.
+	// Ключ уникален в пределах клиента IdempotencySubject; пустой subject — внутренний вызов.
+	IdempotencyKey     string
+	IdempotencySubject string
+	Fingerprint        string
+}
+
+// BalanceUpdateResult — результат одной операции из пакета: созданная запись истории или причина отказа
+type BalanceUpdateResult struct {
+	Transaction Transaction
+	Err         error
+}
@@ -0,0 +1,65 @@
+package domain
+
+import (
+	"github.com/shopspring/decimal"
+
+	"wallet-app/internal/app/app_errors"
+)
+
+// DefaultCurrency — валюта кошелька, если она не указана при создании
+const DefaultCurrency = "RUB"
+
+// currencyMinorUnits — количество знаков после запятой по ISO 4217.
+// Криптовалюты не входят в ISO 4217, их точность задана по принятой в сетях практике.
+var currencyMinorUnits = map[string]int32{
+	"RUB":  2,
+	"USD":  2,
+	"EUR":  2,
+	"GBP":  2,
+	"CHF":  2,
+	"CNY":  2,
+	"KZT":  2,
+	"BYN":  2,
+	"TRY":  2,
+	"AED":  2,
+	"JPY":  0,
+	"KRW":  0,
+	"VND":  0,
+	"BHD":  3,
+	"KWD":  3,
+	"OMR":  3,
+	"JOD":  3,
+	"TND":  3,
+	"BTC":  8,
+	"ETH":  18,
+	"USDT": 6,
+}
+
+// MinorUnits возвращает точность валюты и признак того, что валюта поддерживается
+func MinorUnits(code string) (int32, bool) {
+	units, ok := currencyMinorUnits[code]
+	return units, ok
+}
+
+// ValidateCurrency проверяет, что валюта поддерживается
+func ValidateCurrency(code string) error {
+	if _, ok := MinorUnits(code); !ok {
+		return app_errors.ErrUnsupportedCurrency
+	}
+	return nil
+}
+
+// ValidateAmountScale проверяет, что сумма не содержит больше знаков после запятой, чем допускает валюта.
+// Незначащие нули не учитываются: "100.00" — допустимая сумма в JPY.
+func ValidateAmountScale(code string, amount decimal.Decimal) error {
+	units, ok := MinorUnits(code)
+	if !ok {
+		return app_errors.ErrUnsupportedCurrency
+	}
+
+	if !amount.Equal(amount.Truncate(units)) {
+		return app_errors.ErrInvalidAmountScale
+	}
+
+	return nil
+}
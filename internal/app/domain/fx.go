@@ -0,0 +1,105 @@
+package domain
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"wallet-app/internal/app/app_errors"
+)
+
+// RoundingMode — политика округления суммы после конвертации до точности валюты зачисления
+type RoundingMode string
+
+const (
+	RoundHalfEven RoundingMode = "HALF_EVEN" // Банковское округление
+	RoundHalfUp   RoundingMode = "HALF_UP"   // Половина округляется от нуля
+	RoundDown     RoundingMode = "DOWN"      // Отбрасывание лишних знаков
+)
+
+// ParseRoundingMode разбирает название политики округления без учета регистра
+func ParseRoundingMode(mode string) (RoundingMode, error) {
+	switch m := RoundingMode(strings.ToUpper(mode)); m {
+	case RoundHalfEven, RoundHalfUp, RoundDown:
+		return m, nil
+	}
+	return "", app_errors.ErrInvalidRoundingMode
+}
+
+// Round округляет сумму до places знаков после запятой
+func (m RoundingMode) Round(amount decimal.Decimal, places int32) decimal.Decimal {
+	switch m {
+	case RoundHalfUp:
+		return amount.Round(places)
+	case RoundDown:
+		return amount.Truncate(places)
+	default:
+		return amount.RoundBank(places)
+	}
+}
+
+// FXQuoteRequest — запрос котировки для конвертации из FromCurrency в ToCurrency
+type FXQuoteRequest struct {
+	FromCurrency string `json:"fromCurrency" validate:"required"`
+	ToCurrency   string `json:"toCurrency" validate:"required"`
+}
+
+// FXQuote — зафиксированный курс: 1 единица FromCurrency = Rate единиц ToCurrency.
+// Котировку можно использовать для одного перевода до ExpiresAt.
+type FXQuote struct {
+	ID           uuid.UUID       `json:"quoteId"`
+	FromCurrency string          `json:"fromCurrency"`
+	ToCurrency   string          `json:"toCurrency"`
+	Rate         decimal.Decimal `json:"rate"`
+	ExpiresAt    time.Time       `json:"expiresAt"`
+	CreatedAt    time.Time       `json:"createdAt"`
+}
+
+// Conversion — параметры конвертации перевода по котировке
+type Conversion struct {
+	QuoteID         uuid.UUID       `json:"quoteId"`
+	ToCurrency      string          `json:"toCurrency"`
+	Rate            decimal.Decimal `json:"rate"`
+	ConvertedAmount decimal.Decimal `json:"convertedAmount"`
+}
+
+func (r *FXQuoteRequest) Validate() error {
+	if err := NewValidate.Struct(r); err != nil {
+		return err
+	}
+
+	if err := ValidateCurrency(r.FromCurrency); err != nil {
+		return err
+	}
+	if err := ValidateCurrency(r.ToCurrency); err != nil {
+		return err
+	}
+
+	if r.FromCurrency == r.ToCurrency {
+		return app_errors.ErrSameCurrencyQuote
+	}
+
+	return nil
+}
+
+// Convert пересчитывает сумму в валюту зачисления по курсу котировки с округлением до точности этой валюты
+func (q FXQuote) Convert(amount decimal.Decimal, mode RoundingMode) (Conversion, error) {
+	units, ok := MinorUnits(q.ToCurrency)
+	if !ok {
+		return Conversion{}, app_errors.ErrUnsupportedCurrency
+	}
+
+	converted := mode.Round(amount.Mul(q.Rate), units)
+	if !converted.IsPositive() {
+		return Conversion{}, app_errors.ErrConvertedAmountTooSmall
+	}
+
+	return Conversion{
+		QuoteID:         q.ID,
+		ToCurrency:      q.ToCurrency,
+		Rate:            q.Rate,
+		ConvertedAmount: converted,
+	}, nil
+}
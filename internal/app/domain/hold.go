@@ -0,0 +1,103 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"wallet-app/internal/app/app_errors"
+)
+
+type HoldStatus string
+
+const (
+	HoldActive   HoldStatus = "ACTIVE"
+	HoldCaptured HoldStatus = "CAPTURED"
+	HoldReleased HoldStatus = "RELEASED"
+	HoldExpired  HoldStatus = "EXPIRED"
+)
+
+// Hold — резервирование средств кошелька. Пока холд активен, сумма уменьшает доступный остаток,
+// но не баланс по журналу; списание происходит только при capture.
+type Hold struct {
+	ID             uuid.UUID        `json:"holdId"`
+	WalletID       uuid.UUID        `json:"walletId"`
+	Amount         decimal.Decimal  `json:"amount"`
+	CapturedAmount *decimal.Decimal `json:"capturedAmount,omitempty"`
+	Status         HoldStatus       `json:"status"`
+	ExpiresAt      time.Time        `json:"expiresAt"`
+	CreatedAt      time.Time        `json:"createdAt"`
+	UpdatedAt      time.Time        `json:"updatedAt"`
+}
+
+// HoldRequest — запрос на резервирование средств
+type HoldRequest struct {
+	Amount string `json:"amount" validate:"required,numeric"`
+	// Время жизни холда в секундах; если не задано, используется значение из конфигурации
+	TTLSeconds int `json:"ttlSeconds" validate:"omitempty,min=1"`
+}
+
+// CaptureRequest — запрос на списание зарезервированных средств.
+// Пустая сумма означает списание всего холда; остаток частичного списания освобождается.
+type CaptureRequest struct {
+	Amount string `json:"amount" validate:"omitempty,numeric"`
+}
+
+// HoldCaptureResult — результат списания холда
+type HoldCaptureResult struct {
+	Hold        Hold        `json:"hold"`
+	Transaction Transaction `json:"transaction"`
+}
+
+func (r *HoldRequest) Validate() error {
+	if err := NewValidate.Struct(r); err != nil {
+		return err
+	}
+
+	amount, err := r.ParseAmount()
+	if err != nil {
+		return err
+	}
+
+	if amount.LessThanOrEqual(decimal.Zero) {
+		return app_errors.ErrAmountMustBePositive
+	}
+
+	return nil
+}
+
+func (r *HoldRequest) ParseAmount() (decimal.Decimal, error) {
+	return ParseAmount(r.Amount)
+}
+
+func (r *CaptureRequest) Validate() error {
+	if err := NewValidate.Struct(r); err != nil {
+		return err
+	}
+
+	amount, err := r.ParseAmount()
+	if err != nil {
+		return err
+	}
+
+	if amount != nil && amount.LessThanOrEqual(decimal.Zero) {
+		return app_errors.ErrAmountMustBePositive
+	}
+
+	return nil
+}
+
+// ParseAmount возвращает сумму списания, nil — если списывается весь холд
+func (r *CaptureRequest) ParseAmount() (*decimal.Decimal, error) {
+	if r.Amount == "" {
+		return nil, nil
+	}
+
+	amount, err := ParseAmount(r.Amount)
+	if err != nil {
+		return nil, err
+	}
+
+	return &amount, nil
+}
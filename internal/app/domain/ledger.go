@@ -0,0 +1,158 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"wallet-app/internal/app/app_errors"
+)
+
+// Системные счета журнала. Деньги, пришедшие извне, списываются со счета external_cash_in,
+// выведенные из системы — зачисляются на external_cash_out. Счет fx_conversion принимает валюту
+// отправителя и отдает валюту получателя при переводах с конвертацией.
+var (
+	SystemAccountCashIn         = uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	SystemAccountCashOut        = uuid.MustParse("00000000-0000-0000-0000-000000000002")
+	SystemAccountOpeningBalance = uuid.MustParse("00000000-0000-0000-0000-000000000003")
+	SystemAccountFXConversion   = uuid.MustParse("00000000-0000-0000-0000-000000000004")
+)
+
+// IsSystemAccount сообщает, относится ли счет к системным.
+// Счет кошелька имеет тот же идентификатор, что и сам кошелек.
+func IsSystemAccount(accountID uuid.UUID) bool {
+	switch accountID {
+	case SystemAccountCashIn, SystemAccountCashOut, SystemAccountOpeningBalance, SystemAccountFXConversion:
+		return true
+	}
+	return false
+}
+
+// Posting — проводка по одному счету; положительная сумма увеличивает баланс счета
+type Posting struct {
+	AccountID uuid.UUID       `json:"accountId"`
+	Amount    decimal.Decimal `json:"amount"`
+	Currency  string          `json:"currency"`
+}
+
+// JournalEntry — запись журнала двойной записи. Сумма проводок записи в каждой валюте равна нулю.
+type JournalEntry struct {
+	ID            uuid.UUID     `json:"entryId"`
+	OperationType OperationType `json:"operationType"`
+	Postings      []Posting     `json:"postings"`
+	CreatedAt     time.Time     `json:"createdAt"`
+}
+
+// NewExternalEntry создает запись для пополнения или снятия: кошелек против внешнего системного счета.
+// amount — сумма со знаком с точки зрения кошелька.
+func NewExternalEntry(operationType OperationType, walletID uuid.UUID, currency string, amount decimal.Decimal) JournalEntry {
+	counterAccount := SystemAccountCashIn
+	if amount.IsNegative() {
+		counterAccount = SystemAccountCashOut
+	}
+
+	return JournalEntry{
+		ID:            uuid.New(),
+		OperationType: operationType,
+		Postings: []Posting{
+			{AccountID: walletID, Amount: amount, Currency: currency},
+			{AccountID: counterAccount, Amount: amount.Neg(), Currency: currency},
+		},
+	}
+}
+
+// NewCorrectionEntry создает запись сторно или возврата: движение кошелька против счета исходной операции.
+// amount — сумма со знаком с точки зрения кошелька.
+func NewCorrectionEntry(operationType OperationType, walletID, counterAccountID uuid.UUID, currency string, amount decimal.Decimal) JournalEntry {
+	return JournalEntry{
+		ID:            uuid.New(),
+		OperationType: operationType,
+		Postings: []Posting{
+			{AccountID: walletID, Amount: amount, Currency: currency},
+			{AccountID: counterAccountID, Amount: amount.Neg(), Currency: currency},
+		},
+	}
+}
+
+// NewTransferEntry создает запись перевода между двумя кошельками
+func NewTransferEntry(fromWalletID, toWalletID uuid.UUID, currency string, amount decimal.Decimal) JournalEntry {
+	return JournalEntry{
+		ID:            uuid.New(),
+		OperationType: Transfer,
+		Postings: []Posting{
+			{AccountID: fromWalletID, Amount: amount.Neg(), Currency: currency},
+			{AccountID: toWalletID, Amount: amount, Currency: currency},
+		},
+	}
+}
+
+// NewConversionEntry создает запись перевода с конвертацией: в каждой валюте кошелек
+// проводится против счета fx_conversion, поэтому запись сбалансирована по валютам
+func NewConversionEntry(fromWalletID, toWalletID uuid.UUID, fromCurrency string, amount decimal.Decimal,
+	toCurrency string, convertedAmount decimal.Decimal) JournalEntry {
+	return JournalEntry{
+		ID:            uuid.New(),
+		OperationType: Transfer,
+		Postings: []Posting{
+			{AccountID: fromWalletID, Amount: amount.Neg(), Currency: fromCurrency},
+			{AccountID: SystemAccountFXConversion, Amount: amount, Currency: fromCurrency},
+			{AccountID: SystemAccountFXConversion, Amount: convertedAmount.Neg(), Currency: toCurrency},
+			{AccountID: toWalletID, Amount: convertedAmount, Currency: toCurrency},
+		},
+	}
+}
+
+// Validate проверяет, что запись содержит ненулевые проводки и сбалансирована в каждой валюте
+func (e JournalEntry) Validate() error {
+	if len(e.Postings) < 2 {
+		return app_errors.ErrUnbalancedEntry
+	}
+
+	totals := make(map[string]decimal.Decimal)
+	for _, posting := range e.Postings {
+		if posting.Amount.IsZero() || posting.Currency == "" {
+			return app_errors.ErrUnbalancedEntry
+		}
+		totals[posting.Currency] = totals[posting.Currency].Add(posting.Amount)
+	}
+
+	for _, total := range totals {
+		if !total.IsZero() {
+			return app_errors.ErrUnbalancedEntry
+		}
+	}
+
+	return nil
+}
+
+// AccountBalance — баланс счета журнала, вычисленный по проводкам
+type AccountBalance struct {
+	AccountID uuid.UUID       `json:"accountId"`
+	Code      string          `json:"code"`
+	Currency  string          `json:"currency"`
+	Balance   decimal.Decimal `json:"balance"`
+}
+
+// CurrencyTotal — сумма всех проводок журнала в одной валюте
+type CurrencyTotal struct {
+	Currency string          `json:"currency"`
+	Total    decimal.Decimal `json:"total"`
+}
+
+// WalletDiscrepancy — расхождение проекции баланса кошелька с суммой его проводок
+type WalletDiscrepancy struct {
+	WalletID         uuid.UUID       `json:"walletId"`
+	ProjectedBalance decimal.Decimal `json:"projectedBalance"`
+	LedgerBalance    decimal.Decimal `json:"ledgerBalance"`
+}
+
+// LedgerReport — результат сверки журнала: сумма всех проводок в каждой валюте должна быть нулевой,
+// а балансы кошельков — совпадать с суммами их проводок
+type LedgerReport struct {
+	Totals              []CurrencyTotal     `json:"totals"`
+	UnbalancedEntries   int                 `json:"unbalancedEntries"`
+	SystemAccounts      []AccountBalance    `json:"systemAccounts"`
+	WalletDiscrepancies []WalletDiscrepancy `json:"walletDiscrepancies"`
+	Balanced            bool                `json:"balanced"`
+}
@@ -0,0 +1,171 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"wallet-app/internal/app/app_errors"
+)
+
+// LimitName — вид ограничения операций кошелька; возвращается клиенту в ошибке limit_exceeded
+type LimitName string
+
+const (
+	LimitMaxTransaction    LimitName = "max_transaction"    // Сумма одной операции
+	LimitDailyWithdrawal   LimitName = "daily_withdrawal"   // Сумма списаний за календарный день (UTC)
+	LimitMonthlyWithdrawal LimitName = "monthly_withdrawal" // Сумма списаний за календарный месяц (UTC)
+	LimitMaxBalance        LimitName = "max_balance"        // Баланс кошелька после зачисления
+)
+
+// WithdrawalTypes — операции, которые учитываются в ограничениях списаний за день и месяц.
+// Сторно и возвраты исправляют прежние операции и не учитываются.
+var WithdrawalTypes = []OperationType{Withdraw, Transfer, Capture}
+
+// TransactionLimits — ограничения операций кошелька в его валюте; nil — ограничения нет
+type TransactionLimits struct {
+	MaxTransaction    *decimal.Decimal `json:"maxTransaction,omitempty"`
+	DailyWithdrawal   *decimal.Decimal `json:"dailyWithdrawal,omitempty"`
+	MonthlyWithdrawal *decimal.Decimal `json:"monthlyWithdrawal,omitempty"`
+	MaxBalance        *decimal.Decimal `json:"maxBalance,omitempty"`
+}
+
+// IsZero сообщает, что ни одно ограничение не задано
+func (l TransactionLimits) IsZero() bool {
+	return l.MaxTransaction == nil && l.DailyWithdrawal == nil && l.MonthlyWithdrawal == nil && l.MaxBalance == nil
+}
+
+// LimitsWithdrawals сообщает, ограничены ли списания за день или месяц
+func (l TransactionLimits) LimitsWithdrawals() bool {
+	return l.DailyWithdrawal != nil || l.MonthlyWithdrawal != nil
+}
+
+// Merge возвращает ограничения l, дополненные ограничениями base там, где свои не заданы
+func (l TransactionLimits) Merge(base TransactionLimits) TransactionLimits {
+	merged := l
+	if merged.MaxTransaction == nil {
+		merged.MaxTransaction = base.MaxTransaction
+	}
+	if merged.DailyWithdrawal == nil {
+		merged.DailyWithdrawal = base.DailyWithdrawal
+	}
+	if merged.MonthlyWithdrawal == nil {
+		merged.MonthlyWithdrawal = base.MonthlyWithdrawal
+	}
+	if merged.MaxBalance == nil {
+		merged.MaxBalance = base.MaxBalance
+	}
+	return merged
+}
+
+// Check проверяет операцию на сумму amount со знаком, после которой баланс кошелька станет balanceAfter.
+// usage — списания кошелька за текущие день и месяц вместе с активными холдами, без учета этой операции.
+func (l TransactionLimits) Check(amount, balanceAfter decimal.Decimal, usage WithdrawalUsage) error {
+	if l.MaxTransaction != nil && amount.Abs().GreaterThan(*l.MaxTransaction) {
+		return limitExceeded(LimitMaxTransaction, *l.MaxTransaction)
+	}
+	if amount.IsNegative() {
+		if l.DailyWithdrawal != nil && usage.Daily.Sub(amount).GreaterThan(*l.DailyWithdrawal) {
+			return limitExceeded(LimitDailyWithdrawal, *l.DailyWithdrawal)
+		}
+		if l.MonthlyWithdrawal != nil && usage.Monthly.Sub(amount).GreaterThan(*l.MonthlyWithdrawal) {
+			return limitExceeded(LimitMonthlyWithdrawal, *l.MonthlyWithdrawal)
+		}
+	}
+	// Списания не ограничиваются потолком баланса, даже если баланс уже выше него
+	if amount.IsPositive() && l.MaxBalance != nil && balanceAfter.GreaterThan(*l.MaxBalance) {
+		return limitExceeded(LimitMaxBalance, *l.MaxBalance)
+	}
+	return nil
+}
+
+// WithdrawalUsage — сумма списаний кошелька (модуль) за текущие календарные день и месяц
+type WithdrawalUsage struct {
+	Daily   decimal.Decimal
+	Monthly decimal.Decimal
+}
+
+// Add учитывает списание на сумму amount со знаком
+func (u WithdrawalUsage) Add(amount decimal.Decimal) WithdrawalUsage {
+	return WithdrawalUsage{Daily: u.Daily.Sub(amount), Monthly: u.Monthly.Sub(amount)}
+}
+
+// WithdrawalPeriods возвращает начало календарных дня и месяца в UTC, в которые попадает now
+func WithdrawalPeriods(now time.Time) (dayStart, monthStart time.Time) {
+	now = now.UTC()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC),
+		time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// LimitViolation — причина ошибки limit_exceeded: какое ограничение нарушено и его значение
+type LimitViolation struct {
+	Limit LimitName
+	Max   decimal.Decimal
+}
+
+func (v *LimitViolation) Error() string {
+	return fmt.Sprintf("%s limit of %s exceeded", v.Limit, v.Max)
+}
+
+func limitExceeded(limit LimitName, max decimal.Decimal) error {
+	return app_errors.ErrLimitExceeded.Wrap(&LimitViolation{Limit: limit, Max: max})
+}
+
+// WalletLimits — ограничения операций кошелька: уровень, собственные ограничения кошелька,
+// которые заменяют ограничения уровня, и действующие в итоге ограничения
+type WalletLimits struct {
+	WalletID uuid.UUID `json:"walletId"`
+	// Валюта кошелька: в ней заданы все суммы ограничений
+	Currency string `json:"currency"`
+	// Уровень, назначенный кошельку; пустой — уровень по умолчанию из конфигурации
+	Tier      string            `json:"tier,omitempty"`
+	Overrides TransactionLimits `json:"overrides"`
+	// Effective заполняет сервис: ограничения уровня, замененные собственными ограничениями кошелька
+	Effective TransactionLimits `json:"effective"`
+	UpdatedAt *time.Time        `json:"updatedAt,omitempty"`
+}
+
+// WalletLimitsRequest — новые ограничения кошелька; заменяют прежние целиком.
+// Пустая сумма означает, что ограничение берется из уровня кошелька.
+type WalletLimitsRequest struct {
+	Tier              string `json:"tier,omitempty" validate:"omitempty,max=64"`
+	MaxTransaction    string `json:"maxTransaction,omitempty"`
+	DailyWithdrawal   string `json:"dailyWithdrawal,omitempty"`
+	MonthlyWithdrawal string `json:"monthlyWithdrawal,omitempty"`
+	MaxBalance        string `json:"maxBalance,omitempty"`
+}
+
+func (r *WalletLimitsRequest) Validate() error {
+	return NewValidate.Struct(r)
+}
+
+// Overrides разбирает собственные ограничения кошелька из запроса
+func (r WalletLimitsRequest) Overrides() (TransactionLimits, error) {
+	var limits TransactionLimits
+	fields := []struct {
+		name   string
+		value  string
+		target **decimal.Decimal
+	}{
+		{"maxTransaction", r.MaxTransaction, &limits.MaxTransaction},
+		{"dailyWithdrawal", r.DailyWithdrawal, &limits.DailyWithdrawal},
+		{"monthlyWithdrawal", r.MonthlyWithdrawal, &limits.MonthlyWithdrawal},
+		{"maxBalance", r.MaxBalance, &limits.MaxBalance},
+	}
+	for _, field := range fields {
+		if field.value == "" {
+			continue
+		}
+		amount, err := ParseAmount(field.value)
+		if err == nil && !amount.IsPositive() {
+			err = app_errors.ErrAmountMustBePositive
+		}
+		if err != nil {
+			return TransactionLimits{}, app_errors.As(err).Wrap(fmt.Errorf("invalid %s", field.name))
+		}
+		*field.target = &amount
+	}
+	return limits, nil
+}
@@ -0,0 +1,80 @@
+package domain
+
+import (
+	"math"
+	"time"
+)
+
+// RateLimit — ограничение частоты запросов корзиной токенов: Requests запросов за Per
+// с запасом Burst запросов подряд. Нулевое ограничение ничего не ограничивает.
+type RateLimit struct {
+	Requests int
+	Per      time.Duration
+	// Burst — емкость корзины; 0 — равна Requests
+	Burst int
+}
+
+// Unlimited сообщает, что ограничение не задано
+func (l RateLimit) Unlimited() bool {
+	return l.Requests <= 0 || l.Per <= 0
+}
+
+func (l RateLimit) burst() float64 {
+	if l.Burst > 0 {
+		return float64(l.Burst)
+	}
+	return float64(l.Requests)
+}
+
+// rate — сколько токенов добавляется в корзину за секунду
+func (l RateLimit) rate() float64 {
+	return float64(l.Requests) / l.Per.Seconds()
+}
+
+// TokenBucket — состояние корзины токенов; нулевое значение — корзина, которой еще не пользовались
+type TokenBucket struct {
+	Tokens    float64
+	UpdatedAt time.Time
+}
+
+// RateDecision — результат попытки взять токен из корзины
+type RateDecision struct {
+	Allowed bool
+	// Remaining — сколько запросов еще можно выполнить без ожидания
+	Remaining int
+	// RetryAfter — через сколько в корзине появится токен, если запрос отклонен
+	RetryAfter time.Duration
+}
+
+// Take пополняет корзину за время с последнего обращения до now и берет из нее токен, если он есть
+func (l RateLimit) Take(bucket *TokenBucket, now time.Time) RateDecision {
+	if l.Unlimited() {
+		return RateDecision{Allowed: true, Remaining: math.MaxInt32}
+	}
+
+	burst := l.burst()
+	switch {
+	case bucket.UpdatedAt.IsZero():
+		bucket.Tokens = burst
+		bucket.UpdatedAt = now
+	case now.After(bucket.UpdatedAt):
+		bucket.Tokens = math.Min(burst, bucket.Tokens+now.Sub(bucket.UpdatedAt).Seconds()*l.rate())
+		bucket.UpdatedAt = now
+	}
+
+	if bucket.Tokens >= 1 {
+		bucket.Tokens--
+		return RateDecision{Allowed: true, Remaining: int(bucket.Tokens)}
+	}
+
+	wait := time.Duration((1 - bucket.Tokens) / l.rate() * float64(time.Second))
+	return RateDecision{RetryAfter: wait}
+}
+
+// RefillTime возвращает, за какое время корзина наполнится, если ею не пользоваться
+func (l RateLimit) RefillTime(bucket TokenBucket) time.Duration {
+	if l.Unlimited() {
+		return 0
+	}
+	return time.Duration((l.burst() - bucket.Tokens) / l.rate() * float64(time.Second))
+}
@@ -0,0 +1,34 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// SignedRequest — запрос с подписью HMAC-SHA256 ключом клиента
+type SignedRequest struct {
+	KeyID string
+	// Timestamp — время подписи в секундах Unix
+	Timestamp string
+	// Nonce — одноразовое значение, по которому отклоняются повторы запроса
+	Nonce     string
+	Signature string
+
+	Method string
+	// Path — путь запроса вместе с параметрами
+	Path string
+	Body []byte
+}
+
+// Signed сообщает, передал ли клиент хотя бы одно поле подписи
+func (r SignedRequest) Signed() bool {
+	return r.KeyID != "" || r.Timestamp != "" || r.Nonce != "" || r.Signature != ""
+}
+
+// StringToSign возвращает подписываемую строку: метод, путь, метка времени, nonce
+// и SHA-256 тела в шестнадцатеричной записи, разделенные переводом строки
+func (r SignedRequest) StringToSign() string {
+	digest := sha256.Sum256(r.Body)
+	return strings.Join([]string{r.Method, r.Path, r.Timestamp, r.Nonce, hex.EncodeToString(digest[:])}, "\n")
+}
@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// Transaction — запись истории операций по кошельку.
+// Amount хранится со знаком: положительный для зачислений, отрицательный для списаний.
+type Transaction struct {
+	ID            uuid.UUID       `json:"transactionId"`
+	WalletID      uuid.UUID       `json:"walletId"`
+	OperationType OperationType   `json:"operationType"`
+	Amount        decimal.Decimal `json:"amount"`
+	Currency      string          `json:"currency"`
+	// Баланс после операции; не заполняется для операций по шардированному кошельку,
+	// баланс которого складывается из нескольких строк, меняющихся параллельно
+	BalanceAfter *decimal.Decimal `json:"balanceAfter,omitempty"`
+	CreatedAt    time.Time        `json:"createdAt"`
+
+	// Запись журнала двойной записи, которой проведена операция
+	EntryID *uuid.UUID `json:"entryId,omitempty"`
+
+	// Исходная операция для сторно и возвратов
+	OriginalTransactionID *uuid.UUID `json:"originalTransactionId,omitempty"`
+
+	// Заполняются только для переводов между кошельками
+	TransferID           *uuid.UUID `json:"transferId,omitempty"`
+	CounterpartyWalletID *uuid.UUID `json:"counterpartyWalletId,omitempty"`
+
+	// Заполняются только для переводов с конвертацией: курс и сумма второй стороны перевода в ее валюте
+	ExchangeRate    *decimal.Decimal `json:"exchangeRate,omitempty"`
+	CounterAmount   *decimal.Decimal `json:"counterAmount,omitempty"`
+	CounterCurrency string           `json:"counterCurrency,omitempty"`
+}
@@ -0,0 +1,134 @@
+package domain
+
+import (
+	"encoding/base64"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"wallet-app/internal/app/app_errors"
+)
+
+const (
+	DefaultTransactionsLimit = 20
+	MaxTransactionsLimit     = 100
+)
+
+// TransactionFilter описывает параметры выборки истории операций кошелька.
+// Фильтр по сумме применяется к модулю суммы операции.
+type TransactionFilter struct {
+	OperationType OperationType `form:"operationType" validate:"omitempty,oneof=DEPOSIT WITHDRAW TRANSFER CAPTURE REVERSAL REFUND"`
+	MinAmount     string        `form:"minAmount" validate:"omitempty,numeric"`
+	MaxAmount     string        `form:"maxAmount" validate:"omitempty,numeric"`
+	From          time.Time     `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To            time.Time     `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Cursor        string        `form:"cursor"`
+	Limit         int           `form:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+// TransactionPage — страница истории операций с курсором на следующую страницу
+type TransactionPage struct {
+	Transactions []Transaction `json:"transactions"`
+	NextCursor   string        `json:"nextCursor,omitempty"`
+}
+
+// TransactionCursor — позиция в истории операций, после которой начинается следующая страница
+type TransactionCursor struct {
+	CreatedAt     time.Time
+	TransactionID uuid.UUID
+}
+
+func (f *TransactionFilter) Validate() error {
+	if err := NewValidate.Struct(f); err != nil {
+		return err
+	}
+
+	minAmount, maxAmount, err := f.ParseAmountRange()
+	if err != nil {
+		return err
+	}
+	if minAmount != nil && maxAmount != nil && minAmount.GreaterThan(*maxAmount) {
+		return app_errors.ErrInvalidAmountRange
+	}
+
+	if !f.From.IsZero() && !f.To.IsZero() && f.From.After(f.To) {
+		return app_errors.ErrInvalidTimeRange
+	}
+
+	if _, err := f.ParseCursor(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetLimit возвращает размер страницы с учетом значения по умолчанию
+func (f *TransactionFilter) GetLimit() int {
+	if f.Limit <= 0 {
+		return DefaultTransactionsLimit
+	}
+	if f.Limit > MaxTransactionsLimit {
+		return MaxTransactionsLimit
+	}
+	return f.Limit
+}
+
+// ParseAmountRange возвращает границы диапазона сумм, nil — если граница не задана
+func (f *TransactionFilter) ParseAmountRange() (*decimal.Decimal, *decimal.Decimal, error) {
+	var minAmount, maxAmount *decimal.Decimal
+
+	if f.MinAmount != "" {
+		amount, err := ParseAmount(f.MinAmount)
+		if err != nil {
+			return nil, nil, err
+		}
+		minAmount = &amount
+	}
+
+	if f.MaxAmount != "" {
+		amount, err := ParseAmount(f.MaxAmount)
+		if err != nil {
+			return nil, nil, err
+		}
+		maxAmount = &amount
+	}
+
+	return minAmount, maxAmount, nil
+}
+
+// ParseCursor разбирает курсор страницы, nil — если курсор не задан
+func (f *TransactionFilter) ParseCursor() (*TransactionCursor, error) {
+	if f.Cursor == "" {
+		return nil, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(f.Cursor)
+	if err != nil {
+		return nil, app_errors.ErrInvalidCursor
+	}
+
+	createdAtStr, idStr, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return nil, app_errors.ErrInvalidCursor
+	}
+
+	createdAt, err := time.Parse(time.RFC3339Nano, createdAtStr)
+	if err != nil {
+		return nil, app_errors.ErrInvalidCursor
+	}
+
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return nil, app_errors.ErrInvalidCursor
+	}
+
+	return &TransactionCursor{CreatedAt: createdAt, TransactionID: id}, nil
+}
+
+// Encode кодирует курсор в непрозрачную строку для передачи клиенту
+func (c TransactionCursor) Encode() string {
+	raw := c.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + c.TransactionID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
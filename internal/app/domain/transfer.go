@@ -0,0 +1,85 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"wallet-app/internal/app/app_errors"
+)
+
+// TransferOperation — запрос на перевод средств между кошельками
+type TransferOperation struct {
+	FromWalletID uuid.UUID `json:"fromWalletId" validate:"required"`
+	ToWalletID   uuid.UUID `json:"toWalletId" validate:"required"`
+	Amount       string    `json:"amount" validate:"required,numeric"`
+	// Валюта списания; если указана, должна совпадать с валютой кошелька отправителя
+	Currency string `json:"currency,omitempty"`
+	// Котировка для перевода между кошельками в разных валютах
+	QuoteID *uuid.UUID `json:"quoteId,omitempty"`
+	// IdempotencyKey передается в заголовке Idempotency-Key
+	IdempotencyKey string `json:"-" validate:"omitempty,max=255"`
+}
+
+// TransferResult — результат перевода: списание с одного кошелька и зачисление на другой
+type TransferResult struct {
+	ID           uuid.UUID       `json:"transferId"`
+	FromWalletID uuid.UUID       `json:"fromWalletId"`
+	ToWalletID   uuid.UUID       `json:"toWalletId"`
+	Amount       decimal.Decimal `json:"amount"`
+	Currency     string          `json:"currency"`
+	// Заполняется для перевода с конвертацией; сумма зачисления указана в валюте получателя
+	Conversion *Conversion `json:"conversion,omitempty"`
+	Debit      Transaction `json:"debit"`
+	Credit     Transaction `json:"credit"`
+}
+
+func (op *TransferOperation) Validate() error {
+	if err := NewValidate.Struct(op); err != nil {
+		return err
+	}
+
+	if op.FromWalletID == op.ToWalletID {
+		return app_errors.ErrSameWalletTransfer
+	}
+
+	amount, err := op.ParseAmount()
+	if err != nil {
+		return err
+	}
+
+	if amount.LessThanOrEqual(decimal.Zero) {
+		return app_errors.ErrAmountMustBePositive
+	}
+
+	if op.Currency != "" {
+		return ValidateAmountScale(op.Currency, amount)
+	}
+
+	return nil
+}
+
+func (op *TransferOperation) ParseAmount() (decimal.Decimal, error) {
+	return ParseAmount(op.Amount)
+}
+
+// Fingerprint вычисляет отпечаток перевода для проверки повторного использования ключа идемпотентности
+func (op *TransferOperation) Fingerprint() (string, error) {
+	amount, err := op.ParseAmount()
+	if err != nil {
+		return "", err
+	}
+
+	payload := string(Transfer) + "|" + op.FromWalletID.String() + "|" + op.ToWalletID.String() + "|" + amount.String()
+	if op.Currency != "" {
+		payload += "|" + op.Currency
+	}
+	if op.QuoteID != nil {
+		payload += "|" + op.QuoteID.String()
+	}
+
+	hash := sha256.Sum256([]byte(payload))
+	return hex.EncodeToString(hash[:]), nil
+}
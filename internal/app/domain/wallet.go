@@ -1,15 +1,74 @@
 package domain
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
 )
 
 type Wallet struct {
-	ID      uuid.UUID       `json:"walletId"`
-	Balance decimal.Decimal `json:"balance"`
+	ID       uuid.UUID       `json:"walletId"`
+	Balance  decimal.Decimal `json:"balance"`
+	Currency string          `json:"currency"`
+	Status   WalletStatus    `json:"status"`
+	// Число шардов баланса; 0 — обычный кошелек
+	Shards int `json:"shards,omitempty"`
+
+	// Владелец кошелька во внешней системе клиентов и описательные данные
+	OwnerID     string         `json:"ownerId,omitempty"`
+	DisplayName string         `json:"displayName,omitempty"`
+	Metadata    map[string]any `json:"metadata"`
+	Labels      []string       `json:"labels"`
+
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// CreateWalletRequest — параметры создания кошелька; валюта по умолчанию — DefaultCurrency
+type CreateWalletRequest struct {
+	Currency    string         `json:"currency"`
+	OwnerID     string         `json:"ownerId" validate:"omitempty,max=255"`
+	DisplayName string         `json:"displayName" validate:"omitempty,max=255"`
+	Metadata    map[string]any `json:"metadata"`
+	Labels      []string       `json:"labels" validate:"max=32,dive,min=1,max=64"`
+	// Число шардов баланса для кошельков с большим потоком зачислений; 0 — обычный кошелек
+	Shards int `json:"shards" validate:"omitempty,min=2,max=64"`
+}
+
+// WalletsByOwner — все кошельки владельца
+type WalletsByOwner struct {
+	OwnerID string   `json:"ownerId"`
+	Wallets []Wallet `json:"wallets"`
 }
 
+// WalletBalance — остатки кошелька: total — баланс по журналу, held — сумма активных холдов,
+// available — сумма, доступная для списания
 type WalletBalance struct {
-	Balance decimal.Decimal `json:"balance"`
+	Available decimal.Decimal `json:"available"`
+	Held      decimal.Decimal `json:"held"`
+	Total     decimal.Decimal `json:"total"`
+	Currency  string          `json:"currency"`
+	Status    WalletStatus    `json:"status"`
+	// Версия кошелька, она же ETag ответа; 0 — кошелек не поддерживает If-Match (шардированный)
+	Version int64 `json:"version,omitempty"`
+}
+
+func (r *CreateWalletRequest) Validate() error {
+	if err := NewValidate.Struct(r); err != nil {
+		return err
+	}
+
+	if r.Currency == "" {
+		return nil
+	}
+	return ValidateCurrency(r.Currency)
+}
+
+// GetCurrency возвращает валюту кошелька с учетом значения по умолчанию
+func (r *CreateWalletRequest) GetCurrency() string {
+	if r.Currency == "" {
+		return DefaultCurrency
+	}
+	return r.Currency
 }
@@ -1,8 +1,11 @@
 package domain
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"reflect"
 	"strings"
 
 	"github.com/go-playground/validator/v10"
@@ -17,39 +20,102 @@ type OperationType string
 const (
 	Deposit  OperationType = "DEPOSIT"
 	Withdraw OperationType = "WITHDRAW"
+	Transfer OperationType = "TRANSFER"
+	Capture  OperationType = "CAPTURE"
+	Reversal OperationType = "REVERSAL"
+	Refund   OperationType = "REFUND"
 )
 
 type WalletOperation struct {
 	WalletID      uuid.UUID     `json:"walletId" validate:"required"`
-	OperationType OperationType `json:"operationType" validate:"required,oneof=DEPOSIT WITHDRAW"`
-	Amount        string        `json:"amount" validate:"required,numeric"`
+	OperationType OperationType `json:"operationType" validate:"required,oneof=DEPOSIT WITHDRAW REVERSAL REFUND"`
+	Amount        string        `json:"amount" validate:"required_unless=OperationType REVERSAL,omitempty,numeric"`
+	// Валюта операции; если указана, должна совпадать с валютой кошелька
+	Currency string `json:"currency,omitempty"`
+	// Исходная операция для REVERSAL и REFUND
+	TransactionID *uuid.UUID `json:"transactionId,omitempty"`
+	// IdempotencyKey передается в заголовке Idempotency-Key
+	IdempotencyKey string `json:"-" validate:"omitempty,max=255"`
+	// ExpectedVersion — версия кошелька из заголовка If-Match; nil — операция применяется без проверки версии
+	ExpectedVersion *int64 `json:"-"`
 }
 
-var NewValidate = validator.New()
+var NewValidate = newValidate()
+
+// newValidate создает валидатор, который называет поля в ошибках так же, как они называются в запросе (json или form)
+func newValidate() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(func(fld reflect.StructField) string {
+		for _, tag := range []string{"json", "form"} {
+			name := strings.SplitN(fld.Tag.Get(tag), ",", 2)[0]
+			if name != "" && name != "-" {
+				return name
+			}
+		}
+		return fld.Name
+	})
+	return v
+}
 
 func (op *WalletOperation) Validate() error {
 	if err := NewValidate.Struct(op); err != nil {
 		return err
 	}
 
+	if op.Currency != "" {
+		if err := ValidateCurrency(op.Currency); err != nil {
+			return err
+		}
+	}
+
+	if op.IsCorrection() {
+		if op.TransactionID == nil {
+			return app_errors.ErrOriginalTransactionRequired
+		}
+		// Сторнирование всегда возвращает весь еще не возвращенный остаток исходной операции
+		if op.OperationType == Reversal {
+			if op.Amount != "" {
+				return app_errors.ErrReversalAmountNotAllowed
+			}
+			return nil
+		}
+	} else if op.TransactionID != nil {
+		return app_errors.ErrOriginalTransactionNotAllowed
+	}
+
 	amount, err := op.ParseAmount()
 	if err != nil {
-		return app_errors.ErrInvalidAmount
+		return err
 	}
 
 	if amount.LessThanOrEqual(decimal.Zero) {
 		return app_errors.ErrAmountMustBePositive
 	}
 
+	if op.Currency != "" {
+		return ValidateAmountScale(op.Currency, amount)
+	}
+
 	return nil
 }
 
 func (op *WalletOperation) ParseAmount() (decimal.Decimal, error) {
-	return decimal.NewFromString(op.Amount)
+	return ParseAmount(op.Amount)
+}
+
+// IsCorrection сообщает, ссылается ли операция на исходную (сторно или возврат)
+func (op *WalletOperation) IsCorrection() bool {
+	return op.OperationType == Reversal || op.OperationType == Refund
 }
 
-// GetSignedAmount определяет знак суммы в зависимости от типа операции (DEPOSIT или WITHDRAW)
+// GetSignedAmount определяет знак суммы в зависимости от типа операции (DEPOSIT или WITHDRAW).
+// Для REVERSAL и REFUND знак зависит от исходной операции, поэтому возвращается модуль суммы;
+// сторно без суммы возвращает ноль — весь остаток исходной операции.
 func (op *WalletOperation) GetSignedAmount() (decimal.Decimal, error) {
+	if op.OperationType == Reversal && op.Amount == "" {
+		return decimal.Zero, nil
+	}
+
 	// Преобразуем строку Amount в decimal.Decimal
 	amount, err := op.ParseAmount()
 	if err != nil {
@@ -65,27 +131,76 @@ func (op *WalletOperation) GetSignedAmount() (decimal.Decimal, error) {
 	return amount, nil
 }
 
-func ParseValidationErrors(err error) string {
+// Fingerprint вычисляет отпечаток операции для проверки повторного использования ключа идемпотентности.
+// Сумма нормализуется, поэтому "100" и "100.00" считаются одинаковым запросом.
+func (op *WalletOperation) Fingerprint() (string, error) {
+	normalizedAmount := ""
+	if op.Amount != "" {
+		amount, err := op.ParseAmount()
+		if err != nil {
+			return "", err
+		}
+		normalizedAmount = amount.String()
+	}
+
+	payload := op.WalletID.String() + "|" + string(op.OperationType) + "|" + normalizedAmount
+	if op.TransactionID != nil {
+		payload += "|" + op.TransactionID.String()
+	}
+	if op.Currency != "" {
+		payload += "|" + op.Currency
+	}
+
+	hash := sha256.Sum256([]byte(payload))
+	return hex.EncodeToString(hash[:]), nil
+}
+
+// FieldError — ошибка валидации одного поля запроса
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ParseValidationErrors превращает ошибки валидатора в список ошибок по полям.
+// Для прочих ошибок возвращает nil.
+func ParseValidationErrors(err error) []FieldError {
 	var validationErrors validator.ValidationErrors
-	if errors.As(err, &validationErrors) {
-		var errorMessages []string
-		for _, ve := range validationErrors {
-			field := ve.Field() // Имя поля
-			tag := ve.Tag()     // Тег валидации, например, "required" или "oneof"
-			switch tag {
-			case "required":
-				errorMessages = append(errorMessages, fmt.Sprintf("%s is required", field))
-			case "uuid4":
-				errorMessages = append(errorMessages, fmt.Sprintf("%s must be a valid UUID", field))
-			case "oneof":
-				errorMessages = append(errorMessages, fmt.Sprintf("%s must be one of: %s", field, ve.Param()))
-			case "numeric":
-				errorMessages = append(errorMessages, fmt.Sprintf("%s must be a numeric value", field))
-			default:
-				errorMessages = append(errorMessages, fmt.Sprintf("%s is invalid", field))
-			}
+	if !errors.As(err, &validationErrors) {
+		return nil
+	}
+
+	fieldErrors := make([]FieldError, 0, len(validationErrors))
+	for _, ve := range validationErrors {
+		field := ve.Field() // Имя поля в запросе
+		var message string
+		switch ve.Tag() { // Тег валидации, например, "required" или "oneof"
+		case "required", "required_unless":
+			message = fmt.Sprintf("%s is required", field)
+		case "uuid4":
+			message = fmt.Sprintf("%s must be a valid UUID", field)
+		case "oneof":
+			message = fmt.Sprintf("%s must be one of: %s", field, ve.Param())
+		case "numeric":
+			message = fmt.Sprintf("%s must be a numeric value", field)
+		case "min":
+			message = fmt.Sprintf("%s must be at least %s%s", field, ve.Param(), sizeUnit(ve.Kind()))
+		case "max":
+			message = fmt.Sprintf("%s must be at most %s%s", field, ve.Param(), sizeUnit(ve.Kind()))
+		default:
+			message = fmt.Sprintf("%s is invalid", field)
 		}
-		return strings.Join(errorMessages, "; ")
+		fieldErrors = append(fieldErrors, FieldError{Field: field, Message: message})
+	}
+	return fieldErrors
+}
+
+// sizeUnit возвращает единицу, в которой min и max ограничивают значение данного вида
+func sizeUnit(kind reflect.Kind) string {
+	switch kind {
+	case reflect.String:
+		return " characters"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return " items"
 	}
-	return "Invalid input data"
+	return ""
 }
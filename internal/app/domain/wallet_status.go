@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"wallet-app/internal/app/app_errors"
+)
+
+// WalletStatus — состояние жизненного цикла кошелька
+type WalletStatus string
+
+const (
+	WalletActive  WalletStatus = "ACTIVE"  // Разрешены все операции
+	WalletFrozen  WalletStatus = "FROZEN"  // Запрещены списания, зачисления разрешены
+	WalletBlocked WalletStatus = "BLOCKED" // Запрещены любые движения средств
+	WalletClosed  WalletStatus = "CLOSED"  // Кошелек закрыт окончательно
+)
+
+// WalletStatusRequest — запрос на смену состояния кошелька; причина сохраняется для аудита
+type WalletStatusRequest struct {
+	Reason string `json:"reason" validate:"max=500"`
+}
+
+// WalletState — текущее состояние кошелька
+type WalletState struct {
+	WalletID        uuid.UUID    `json:"walletId"`
+	Status          WalletStatus `json:"status"`
+	Reason          string       `json:"reason,omitempty"`
+	StatusChangedAt *time.Time   `json:"statusChangedAt,omitempty"`
+}
+
+func (r *WalletStatusRequest) Validate() error {
+	return NewValidate.Struct(r)
+}
+
+// CheckMovement проверяет, разрешено ли в этом состоянии движение средств на сумму amount со знаком
+func (s WalletStatus) CheckMovement(amount decimal.Decimal) error {
+	switch s {
+	case WalletActive:
+		return nil
+	case WalletFrozen:
+		if amount.IsNegative() {
+			return app_errors.ErrWalletFrozen
+		}
+		return nil
+	case WalletBlocked:
+		return app_errors.ErrWalletBlocked
+	default:
+		return app_errors.ErrWalletClosed
+	}
+}
+
+// CheckTransition проверяет переход из состояния s в target. Закрытие возможно только
+// при нулевом балансе и отсутствии холдов; из закрытого состояния выйти нельзя.
+func (s WalletStatus) CheckTransition(target WalletStatus, balance, held decimal.Decimal) error {
+	if s == WalletClosed {
+		return app_errors.ErrWalletClosed
+	}
+
+	switch target {
+	case WalletActive, WalletFrozen, WalletBlocked:
+		return nil
+	case WalletClosed:
+		if !balance.IsZero() || !held.IsZero() {
+			return app_errors.ErrWalletBalanceNotZero
+		}
+		return nil
+	}
+
+	return app_errors.ErrInvalidStatusTransition
+}
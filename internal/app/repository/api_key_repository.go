@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+
+	"wallet-app/internal/app/app_errors"
+	"wallet-app/internal/app/domain"
+)
+
+// CreateAPIKey сохраняет ключ API с хешем hash
+func (r *WalletRepository) CreateAPIKey(ctx context.Context, key domain.APIKey, hash []byte) (_ domain.APIKey, err error) {
+	defer translateError(&err)
+
+	err = r.db.QueryRow(ctx,
+		`INSERT INTO api_keys(key_id, name, subject, roles, key_prefix, key_hash, expires_at)
+		VALUES($1, $2, $3, $4, $5, $6, $7) RETURNING created_at`,
+		key.ID, key.Name, key.Subject, key.Roles, key.Prefix, hash, key.ExpiresAt,
+	).Scan(&key.CreatedAt)
+	if err != nil {
+		return domain.APIKey{}, err
+	}
+
+	return key, nil
+}
+
+// GetAPIKeyByHash ищет ключ API по хешу; отозванные и просроченные ключи тоже возвращаются
+func (r *WalletRepository) GetAPIKeyByHash(ctx context.Context, hash []byte) (_ domain.APIKey, err error) {
+	defer translateError(&err)
+
+	var key domain.APIKey
+	err = r.db.QueryRow(ctx,
+		`SELECT key_id, name, subject, roles, key_prefix, created_at, expires_at, revoked_at
+		FROM api_keys WHERE key_hash = $1`,
+		hash,
+	).Scan(&key.ID, &key.Name, &key.Subject, &key.Roles, &key.Prefix, &key.CreatedAt, &key.ExpiresAt, &key.RevokedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.APIKey{}, app_errors.ErrNotFound
+	}
+	if err != nil {
+		return domain.APIKey{}, err
+	}
+
+	return key, nil
+}
@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"wallet-app/internal/app/app_errors"
+	"wallet-app/internal/app/domain"
+)
+
+// UpdateBalanceBatch применяет пакет изменений баланса одного кошелька в одной транзакции:
+// строка кошелька блокируется один раз, операции истории записываются одним запросом.
+// Операции применяются по порядку; отклоненная операция (например, списание сверх доступного остатка)
+// получает ошибку в своем результате и не влияет на остальные. Ошибка самой функции означает,
+// что не применена ни одна операция.
+func (r *WalletRepository) UpdateBalanceBatch(ctx context.Context, walletID uuid.UUID, updates []domain.BalanceUpdate) (_ []domain.BalanceUpdateResult, err error) {
+	defer translateError(&err)
+
+	shardCount, err := walletShardCount(ctx, r.db, walletID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Шардированный кошелек и так не сериализует зачисления, поэтому операции выполняются по отдельности
+	if shardCount > 0 {
+		results := make([]domain.BalanceUpdateResult, len(updates))
+		var wg sync.WaitGroup
+		for i, update := range updates {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				results[i].Transaction, results[i].Err = r.UpdateBalance(ctx, update)
+			}()
+		}
+		wg.Wait()
+		return results, nil
+	}
+
+	return runTx(ctx, r, pgx.Serializable, func(tx pgx.Tx) ([]domain.BalanceUpdateResult, error) {
+		return updateBalanceBatchTx(ctx, tx, walletID, updates)
+	})
+}
+
+// updateBalanceBatchTx выполняет UpdateBalanceBatch в транзакции tx
+func updateBalanceBatchTx(ctx context.Context, tx pgx.Tx, walletID uuid.UUID, updates []domain.BalanceUpdate) (_ []domain.BalanceUpdateResult, err error) {
+	wallet, err := lockWallet(ctx, tx, walletID)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]domain.BalanceUpdateResult, len(updates))
+	// Повтор ключа идемпотентности внутри пакета получает результат первой операции с этим ключом
+	firstByKey := make(map[idempotencyKey]int)
+	duplicateOf := make(map[int]int)
+
+	var entries []*domain.JournalEntry
+	var transactions []*domain.Transaction
+	var saved []int
+	balance := wallet.Balance
+	// Списания за день и месяц читаются из истории один раз на пакет, при первой необходимости;
+	// к ним прибавляются списания, уже принятые в этом пакете
+	var history *domain.WithdrawalUsage
+	var batchDebits domain.WithdrawalUsage
+
+	for i, update := range updates {
+		if update.IdempotencyKey != "" {
+			key := idempotencyKey{subject: update.IdempotencySubject, key: update.IdempotencyKey}
+			if first, ok := firstByKey[key]; ok {
+				if updates[first].Fingerprint != update.Fingerprint {
+					results[i].Err = app_errors.ErrIdempotencyKeyReused
+				} else {
+					duplicateOf[i] = first
+				}
+				continue
+			}
+			firstByKey[key] = i
+
+			found, err := findIdempotentResponse(ctx, tx, update.IdempotencySubject, update.IdempotencyKey, update.Fingerprint, &results[i].Transaction)
+			if errors.Is(err, app_errors.ErrIdempotencyKeyReused) {
+				results[i].Err = err
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			if found {
+				continue
+			}
+		}
+
+		// После первой примененной операции пакета версия кошелька уже не та, что видел клиент
+		if update.ExpectedVersion != nil && len(entries) > 0 {
+			results[i].Err = app_errors.ErrPreconditionFailed
+			continue
+		}
+		if err = wallet.CheckVersion(update.ExpectedVersion); err != nil {
+			results[i].Err = err
+			continue
+		}
+		if err = wallet.Status.CheckMovement(update.Amount); err != nil {
+			results[i].Err = err
+			continue
+		}
+		if err = wallet.CheckAmount(update.Currency, update.Amount); err != nil {
+			results[i].Err = err
+			continue
+		}
+
+		// Доступный остаток учитывает списания, уже принятые в этом пакете
+		if update.Amount.IsNegative() && balance.Sub(wallet.Held).Add(update.Amount).IsNegative() {
+			results[i].Err = app_errors.ErrInsufficientFunds
+			continue
+		}
+		var usage domain.WithdrawalUsage
+		if update.Amount.IsNegative() && update.Limits.LimitsWithdrawals() {
+			if history == nil {
+				loaded, err := withdrawalUsage(ctx, tx, walletID)
+				if err != nil {
+					return nil, err
+				}
+				history = &loaded
+			}
+			// Активные холды учитываются как будущие списания, как в checkLimits
+			usage = domain.WithdrawalUsage{
+				Daily:   history.Daily.Add(batchDebits.Daily).Add(wallet.Held),
+				Monthly: history.Monthly.Add(batchDebits.Monthly).Add(wallet.Held),
+			}
+		}
+		if err = update.Limits.Check(update.Amount, balance.Add(update.Amount), usage); err != nil {
+			results[i].Err = err
+			continue
+		}
+		if update.Amount.IsNegative() {
+			batchDebits = batchDebits.Add(update.Amount)
+		}
+		balance = balance.Add(update.Amount)
+
+		entry := domain.NewExternalEntry(update.OperationType, walletID, wallet.Currency, update.Amount)
+		entries = append(entries, &entry)
+
+		results[i].Transaction = domain.Transaction{
+			ID:            uuid.New(),
+			WalletID:      walletID,
+			OperationType: update.OperationType,
+			Amount:        update.Amount,
+			Currency:      wallet.Currency,
+			BalanceAfter:  wallet.BalanceAfter(balance),
+			EntryID:       &entry.ID,
+		}
+		transactions = append(transactions, &results[i].Transaction)
+		if update.IdempotencyKey != "" {
+			saved = append(saved, i)
+		}
+	}
+
+	if len(entries) > 0 {
+		if err = postJournalEntries(ctx, tx, entries, nil); err != nil {
+			return nil, err
+		}
+		if err = insertTransactions(ctx, tx, transactions); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(saved) > 0 {
+		batch := &pgx.Batch{}
+		for _, i := range saved {
+			if err = queueIdempotentResponse(batch, updates[i].IdempotencySubject, updates[i].IdempotencyKey, updates[i].Fingerprint, results[i].Transaction); err != nil {
+				return nil, err
+			}
+		}
+		if err = tx.SendBatch(ctx, batch).Close(); err != nil {
+			return nil, err
+		}
+	}
+
+	for i, first := range duplicateOf {
+		results[i] = results[first]
+	}
+
+	return results, nil
+}
@@ -0,0 +1,199 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+
+	"wallet-app/internal/app/app_errors"
+	"wallet-app/internal/app/domain"
+)
+
+// ApplyCorrection проводит сторно (REVERSAL) или возврат (REFUND) по исходной операции кошелька.
+// Суммарно по исходной операции нельзя вернуть больше ее суммы; строка исходной операции
+// блокируется, поэтому параллельные возвраты по ней выполняются последовательно.
+func (r *WalletRepository) ApplyCorrection(ctx context.Context, update domain.BalanceUpdate) (_ domain.Transaction, err error) {
+	defer translateError(&err)
+
+	if update.OriginalTransactionID == nil {
+		return domain.Transaction{}, app_errors.ErrOriginalTransactionRequired
+	}
+
+	return retryAfterDrain(ctx, r, update.WalletID, func() (domain.Transaction, error) {
+		return runTx(ctx, r, pgx.Serializable, func(tx pgx.Tx) (domain.Transaction, error) {
+			return applyCorrectionTx(ctx, tx, update)
+		})
+	})
+}
+
+// applyCorrectionTx выполняет ApplyCorrection в транзакции tx
+func applyCorrectionTx(ctx context.Context, tx pgx.Tx, update domain.BalanceUpdate) (_ domain.Transaction, err error) {
+	if update.IdempotencyKey != "" {
+		var stored domain.Transaction
+		found, err := findIdempotentResponse(ctx, tx, update.IdempotencySubject, update.IdempotencyKey, update.Fingerprint, &stored)
+		if err != nil {
+			return domain.Transaction{}, err
+		}
+		if found {
+			return stored, nil
+		}
+	}
+
+	wallet, err := lockWallet(ctx, tx, update.WalletID)
+	if err != nil {
+		return domain.Transaction{}, err
+	}
+
+	if err = wallet.CheckVersion(update.ExpectedVersion); err != nil {
+		return domain.Transaction{}, err
+	}
+
+	if err = wallet.CheckAmount(update.Currency, update.Amount); err != nil {
+		return domain.Transaction{}, err
+	}
+
+	original, err := lockOriginalTransaction(ctx, tx, update.WalletID, *update.OriginalTransactionID)
+	if err != nil {
+		return domain.Transaction{}, err
+	}
+
+	if !isCorrectable(update.OperationType, original) {
+		return domain.Transaction{}, app_errors.ErrTransactionNotReversible
+	}
+
+	// Сколько по исходной операции уже возвращено сторно и возвратами
+	var correctedStr string
+	err = tx.QueryRow(ctx,
+		"SELECT COALESCE(SUM(ABS(amount)), 0) FROM wallet_transactions WHERE original_transaction_id = $1",
+		original.ID,
+	).Scan(&correctedStr)
+	if err != nil {
+		return domain.Transaction{}, err
+	}
+	corrected, err := decimal.NewFromString(correctedStr)
+	if err != nil {
+		return domain.Transaction{}, err
+	}
+
+	remaining := original.Amount.Abs().Sub(corrected)
+	amount := update.Amount.Abs()
+	if amount.IsZero() {
+		amount = remaining
+	}
+	if amount.IsZero() || amount.GreaterThan(remaining) {
+		return domain.Transaction{}, app_errors.ErrRefundExceedsOriginal
+	}
+
+	// Движение по кошельку противоположно исходной операции
+	signedAmount := amount
+	if original.Amount.IsPositive() {
+		signedAmount = amount.Neg()
+	}
+
+	if err = wallet.Status.CheckMovement(signedAmount); err != nil {
+		return domain.Transaction{}, err
+	}
+
+	if signedAmount.IsNegative() && wallet.Available().Add(signedAmount).IsNegative() {
+		return domain.Transaction{}, app_errors.ErrInsufficientFunds
+	}
+	if err = checkLimits(ctx, tx, update.WalletID, wallet, update.Limits, signedAmount); err != nil {
+		return domain.Transaction{}, err
+	}
+
+	counterAccountID, err := correctionCounterAccount(ctx, tx, original)
+	if err != nil {
+		return domain.Transaction{}, err
+	}
+
+	entry := domain.NewCorrectionEntry(update.OperationType, update.WalletID, counterAccountID, wallet.Currency, signedAmount)
+	if err = postJournalEntry(ctx, tx, &entry); err != nil {
+		return domain.Transaction{}, err
+	}
+
+	transaction := domain.Transaction{
+		ID:                    uuid.New(),
+		WalletID:              update.WalletID,
+		OperationType:         update.OperationType,
+		Amount:                signedAmount,
+		Currency:              wallet.Currency,
+		BalanceAfter:          wallet.BalanceAfter(wallet.Balance.Add(signedAmount)),
+		EntryID:               &entry.ID,
+		OriginalTransactionID: &original.ID,
+	}
+	if err = insertTransaction(ctx, tx, &transaction); err != nil {
+		return domain.Transaction{}, err
+	}
+
+	if update.IdempotencyKey != "" {
+		err = saveIdempotentResponse(ctx, tx, update.IdempotencySubject, update.IdempotencyKey, update.Fingerprint, transaction)
+		if err != nil {
+			return domain.Transaction{}, err
+		}
+	}
+
+	return transaction, nil
+}
+
+// isCorrectable проверяет, можно ли применить сторно или возврат к исходной операции.
+// Возврат возможен только для списаний; переводы и сами корректировки не сторнируются.
+func isCorrectable(operationType domain.OperationType, original domain.Transaction) bool {
+	switch original.OperationType {
+	case domain.Deposit:
+		return operationType == domain.Reversal
+	case domain.Withdraw, domain.Capture:
+		return true
+	}
+	return false
+}
+
+// lockOriginalTransaction блокирует исходную операцию кошелька
+func lockOriginalTransaction(ctx context.Context, tx pgx.Tx, walletID, transactionID uuid.UUID) (domain.Transaction, error) {
+	var original domain.Transaction
+	var operationType, amountStr string
+
+	err := tx.QueryRow(ctx,
+		`SELECT transaction_id, operation_type, amount, entry_id FROM wallet_transactions
+		WHERE transaction_id = $1 AND wallet_id = $2 FOR UPDATE`,
+		transactionID, walletID,
+	).Scan(&original.ID, &operationType, &amountStr, &original.EntryID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.Transaction{}, app_errors.ErrTransactionNotFound
+	}
+	if err != nil {
+		return domain.Transaction{}, err
+	}
+
+	original.WalletID = walletID
+	original.OperationType = domain.OperationType(operationType)
+	if original.Amount, err = decimal.NewFromString(amountStr); err != nil {
+		return domain.Transaction{}, err
+	}
+
+	return original, nil
+}
+
+// correctionCounterAccount возвращает счет, против которого была проведена исходная операция.
+// Для операций, записанных до появления журнала, используется соответствующий внешний счет.
+func correctionCounterAccount(ctx context.Context, tx pgx.Tx, original domain.Transaction) (uuid.UUID, error) {
+	if original.EntryID == nil {
+		if original.Amount.IsPositive() {
+			return domain.SystemAccountCashIn, nil
+		}
+		return domain.SystemAccountCashOut, nil
+	}
+
+	var accountID uuid.UUID
+	err := tx.QueryRow(ctx,
+		"SELECT account_id FROM ledger_postings WHERE entry_id = $1 AND account_id <> $2 LIMIT 1",
+		*original.EntryID, original.WalletID,
+	).Scan(&accountID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	return accountID, nil
+}
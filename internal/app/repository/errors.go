@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"net"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"wallet-app/internal/app/app_errors"
+)
+
+// translateError переводит ошибки pgx и Postgres в ошибки app_errors.
+// Ошибки приложения и отмена контекста возвращаются без изменений.
+func translateError(err *error) {
+	if *err == nil {
+		return
+	}
+
+	var appErr *app_errors.Error
+	if errors.As(*err, &appErr) || errors.Is(*err, context.Canceled) {
+		return
+	}
+
+	if errors.Is(*err, pgx.ErrNoRows) {
+		*err = app_errors.ErrNotFound.Wrap(*err)
+		return
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(*err, &pgErr) {
+		*err = translatePgError(pgErr)
+		return
+	}
+
+	var netErr net.Error
+	var connectErr *pgconn.ConnectError
+	if errors.As(*err, &connectErr) || errors.As(*err, &netErr) || pgconn.Timeout(*err) {
+		*err = app_errors.ErrUnavailable.Wrap(*err)
+		return
+	}
+
+	*err = app_errors.ErrInternal.Wrap(*err)
+}
+
+// translatePgError выбирает ошибку приложения по SQLSTATE
+func translatePgError(pgErr *pgconn.PgError) error {
+	switch pgErr.Code {
+	case "40001", "40P01": // serialization_failure, deadlock_detected
+		return app_errors.ErrConcurrentUpdate.Wrap(pgErr)
+	case "23505": // unique_violation
+		return app_errors.ErrConflict.Wrap(pgErr)
+	case "23503": // foreign_key_violation
+		return app_errors.ErrNotFound.Wrap(pgErr)
+	case "55P03": // lock_not_available
+		return app_errors.ErrConcurrentUpdate.Wrap(pgErr)
+	}
+
+	switch pgErr.Code[:2] {
+	case "08", "53", "57": // connection_exception, insufficient_resources, operator_intervention
+		return app_errors.ErrUnavailable.Wrap(pgErr)
+	}
+
+	return app_errors.ErrInternal.Wrap(pgErr)
+}
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+
+	"wallet-app/internal/app/app_errors"
+	"wallet-app/internal/app/domain"
+)
+
+// CreateQuote сохраняет котировку с зафиксированным курсом
+func (r *WalletRepository) CreateQuote(ctx context.Context, quote domain.FXQuote) (_ domain.FXQuote, err error) {
+	defer translateError(&err)
+
+	err = r.db.QueryRow(ctx,
+		`INSERT INTO fx_quotes(quote_id, from_currency, to_currency, rate, expires_at)
+		VALUES($1, $2, $3, $4, $5) RETURNING created_at`,
+		quote.ID, quote.FromCurrency, quote.ToCurrency, quote.Rate.String(), quote.ExpiresAt,
+	).Scan(&quote.CreatedAt)
+	if err != nil {
+		return domain.FXQuote{}, err
+	}
+
+	return quote, nil
+}
+
+// GetQuote возвращает котировку по идентификатору
+func (r *WalletRepository) GetQuote(ctx context.Context, quoteID uuid.UUID) (_ domain.FXQuote, err error) {
+	defer translateError(&err)
+
+	var quote domain.FXQuote
+	var rateStr string
+
+	err = r.db.QueryRow(ctx,
+		`SELECT quote_id, from_currency, to_currency, rate, expires_at, created_at
+		FROM fx_quotes WHERE quote_id = $1`,
+		quoteID,
+	).Scan(&quote.ID, &quote.FromCurrency, &quote.ToCurrency, &rateStr, &quote.ExpiresAt, &quote.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.FXQuote{}, app_errors.ErrQuoteNotFound
+	}
+	if err != nil {
+		return domain.FXQuote{}, err
+	}
+
+	if quote.Rate, err = decimal.NewFromString(rateStr); err != nil {
+		return domain.FXQuote{}, err
+	}
+
+	return quote, nil
+}
+
+// claimQuote привязывает действующую котировку к переводу; повторно использовать ее нельзя.
+// Проверяется, что котировка выписана для валют кошельков перевода и по тому же курсу.
+func claimQuote(ctx context.Context, tx pgx.Tx, conversion domain.Conversion, transferID uuid.UUID, fromCurrency, toCurrency string) error {
+	var quoteFrom, quoteTo, rateStr string
+	err := tx.QueryRow(ctx,
+		`UPDATE fx_quotes SET transfer_id = $1
+		WHERE quote_id = $2 AND transfer_id IS NULL AND expires_at > now()
+		RETURNING from_currency, to_currency, rate`,
+		transferID, conversion.QuoteID,
+	).Scan(&quoteFrom, &quoteTo, &rateStr)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return app_errors.ErrQuoteNotActive
+	}
+	if err != nil {
+		return err
+	}
+
+	if quoteFrom != fromCurrency || quoteTo != toCurrency || quoteTo != conversion.ToCurrency {
+		return app_errors.ErrCurrencyMismatch
+	}
+
+	rate, err := decimal.NewFromString(rateStr)
+	if err != nil {
+		return err
+	}
+	if !rate.Equal(conversion.Rate) {
+		return app_errors.ErrQuoteNotActive
+	}
+
+	return nil
+}
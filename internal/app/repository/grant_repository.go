@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"wallet-app/internal/app/app_errors"
+	"wallet-app/internal/app/domain"
+)
+
+// GetWalletAccess возвращает владельца кошелька и роль, выданную на него клиенту subject
+func (r *WalletRepository) GetWalletAccess(ctx context.Context, walletID uuid.UUID, subject string) (_ domain.WalletAccess, err error) {
+	defer translateError(&err)
+
+	var ownerID, role *string
+	err = r.db.QueryRow(ctx,
+		`SELECT w.owner_id, g.role FROM wallets w
+		LEFT JOIN wallet_grants g ON g.wallet_id = w.wallet_id AND g.subject = $2
+		WHERE w.wallet_id = $1`,
+		walletID, subject,
+	).Scan(&ownerID, &role)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.WalletAccess{}, app_errors.ErrWalletNotFound
+	}
+	if err != nil {
+		return domain.WalletAccess{}, err
+	}
+
+	var access domain.WalletAccess
+	if ownerID != nil {
+		access.OwnerID = *ownerID
+	}
+	if role != nil {
+		access.Role = domain.Role(*role)
+	}
+	return access, nil
+}
+
+// ListWalletGrants возвращает роли, выданные на кошелек, в порядке выдачи
+func (r *WalletRepository) ListWalletGrants(ctx context.Context, walletID uuid.UUID) (_ []domain.WalletGrant, err error) {
+	defer translateError(&err)
+
+	var exists bool
+	if err = r.db.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM wallets WHERE wallet_id = $1)`, walletID).Scan(&exists); err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, app_errors.ErrWalletNotFound
+	}
+
+	rows, err := r.db.Query(ctx,
+		`SELECT subject, role, granted_by, created_at FROM wallet_grants
+		WHERE wallet_id = $1 ORDER BY created_at, subject`,
+		walletID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	grants := []domain.WalletGrant{}
+	for rows.Next() {
+		grant := domain.WalletGrant{WalletID: walletID}
+		var role string
+		var grantedBy *string
+		if err = rows.Scan(&grant.Subject, &role, &grantedBy, &grant.CreatedAt); err != nil {
+			return nil, err
+		}
+		grant.Role = domain.Role(role)
+		if grantedBy != nil {
+			grant.GrantedBy = *grantedBy
+		}
+		grants = append(grants, grant)
+	}
+
+	return grants, rows.Err()
+}
+
+// PutWalletGrant выдает клиенту роль на кошелек; выданная ранее роль заменяется
+func (r *WalletRepository) PutWalletGrant(ctx context.Context, grant domain.WalletGrant) (_ domain.WalletGrant, err error) {
+	defer translateError(&err)
+
+	// Роль вставляется выборкой из wallets: если кошелька нет, запрос не вернет строк
+	err = r.db.QueryRow(ctx,
+		`INSERT INTO wallet_grants(wallet_id, subject, role, granted_by)
+		SELECT wallet_id, $2, $3, $4 FROM wallets WHERE wallet_id = $1
+		ON CONFLICT (wallet_id, subject) DO UPDATE SET role = EXCLUDED.role, granted_by = EXCLUDED.granted_by
+		RETURNING created_at`,
+		grant.WalletID, grant.Subject, string(grant.Role), nullableString(grant.GrantedBy),
+	).Scan(&grant.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.WalletGrant{}, app_errors.ErrWalletNotFound
+	}
+	if err != nil {
+		return domain.WalletGrant{}, err
+	}
+
+	return grant, nil
+}
+
+// DeleteWalletGrant отзывает роль клиента на кошелек
+func (r *WalletRepository) DeleteWalletGrant(ctx context.Context, walletID uuid.UUID, subject string) (err error) {
+	defer translateError(&err)
+
+	tag, err := r.db.Exec(ctx, `DELETE FROM wallet_grants WHERE wallet_id = $1 AND subject = $2`, walletID, subject)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return app_errors.ErrGrantNotFound
+	}
+	return nil
+}
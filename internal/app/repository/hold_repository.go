@@ -0,0 +1,291 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+
+	"wallet-app/internal/app/app_errors"
+	"wallet-app/internal/app/domain"
+)
+
+const holdColumns = "hold_id, wallet_id, amount, captured_amount, status, expires_at, created_at, updated_at"
+
+// CreateHold резервирует средства кошелька до expiresAt, проверяя резерв по ограничениям limits как списание
+func (r *WalletRepository) CreateHold(ctx context.Context, walletID uuid.UUID, amount decimal.Decimal, expiresAt time.Time, limits domain.TransactionLimits) (_ domain.Hold, err error) {
+	defer translateError(&err)
+
+	return retryAfterDrain(ctx, r, walletID, func() (domain.Hold, error) {
+		return runTx(ctx, r, pgx.Serializable, func(tx pgx.Tx) (domain.Hold, error) {
+			return createHoldTx(ctx, tx, walletID, amount, expiresAt, limits)
+		})
+	})
+}
+
+// createHoldTx выполняет CreateHold в транзакции tx
+func createHoldTx(ctx context.Context, tx pgx.Tx, walletID uuid.UUID, amount decimal.Decimal, expiresAt time.Time, limits domain.TransactionLimits) (_ domain.Hold, err error) {
+	wallet, err := lockWallet(ctx, tx, walletID)
+	if err != nil {
+		return domain.Hold{}, err
+	}
+
+	// Резервирование — будущее списание, поэтому подчиняется тем же ограничениям
+	if err = wallet.Status.CheckMovement(amount.Neg()); err != nil {
+		return domain.Hold{}, err
+	}
+
+	if err = wallet.CheckAmount("", amount); err != nil {
+		return domain.Hold{}, err
+	}
+
+	if wallet.Available().LessThan(amount) {
+		return domain.Hold{}, app_errors.ErrInsufficientFunds
+	}
+	if err = checkLimits(ctx, tx, walletID, wallet, limits, amount.Neg()); err != nil {
+		return domain.Hold{}, err
+	}
+
+	_, err = tx.Exec(ctx, "UPDATE wallets SET held = held + $1 WHERE wallet_id = $2", amount.String(), walletID)
+	if err != nil {
+		return domain.Hold{}, err
+	}
+
+	hold, err := scanHold(tx.QueryRow(ctx,
+		`INSERT INTO wallet_holds(hold_id, wallet_id, amount, status, expires_at)
+		VALUES($1, $2, $3, $4, $5) RETURNING `+holdColumns,
+		uuid.New(), walletID, amount.String(), string(domain.HoldActive), expiresAt,
+	))
+	if err != nil {
+		return domain.Hold{}, err
+	}
+
+	return hold, nil
+}
+
+// CaptureHold списывает зарезервированные средства. amount == nil означает списание всего холда;
+// при частичном списании остаток резерва освобождается. Списание проверяется по ограничениям limits:
+// они могли измениться после создания холда.
+func (r *WalletRepository) CaptureHold(ctx context.Context, walletID, holdID uuid.UUID, amount *decimal.Decimal, limits domain.TransactionLimits) (_ domain.HoldCaptureResult, err error) {
+	defer translateError(&err)
+
+	return runTx(ctx, r, pgx.Serializable, func(tx pgx.Tx) (domain.HoldCaptureResult, error) {
+		return captureHoldTx(ctx, tx, walletID, holdID, amount, limits)
+	})
+}
+
+// captureHoldTx выполняет CaptureHold в транзакции tx
+func captureHoldTx(ctx context.Context, tx pgx.Tx, walletID, holdID uuid.UUID, amount *decimal.Decimal, limits domain.TransactionLimits) (_ domain.HoldCaptureResult, err error) {
+	wallet, hold, err := lockActiveHold(ctx, tx, walletID, holdID)
+	if err != nil {
+		return domain.HoldCaptureResult{}, err
+	}
+
+	captureAmount := hold.Amount
+	if amount != nil {
+		if amount.GreaterThan(hold.Amount) {
+			return domain.HoldCaptureResult{}, app_errors.ErrCaptureExceedsHold
+		}
+		if err = wallet.CheckAmount("", *amount); err != nil {
+			return domain.HoldCaptureResult{}, err
+		}
+		captureAmount = *amount
+	}
+
+	if err = wallet.Status.CheckMovement(captureAmount.Neg()); err != nil {
+		return domain.HoldCaptureResult{}, err
+	}
+
+	// Собственный резерв холда не считается будущим списанием: его заменяет само списание
+	released := wallet
+	released.Held = wallet.Held.Sub(hold.Amount)
+	if err = checkLimits(ctx, tx, walletID, released, limits, captureAmount.Neg()); err != nil {
+		return domain.HoldCaptureResult{}, err
+	}
+
+	// Снимаем резерв целиком и списываем захваченную сумму по журналу
+	_, err = tx.Exec(ctx, "UPDATE wallets SET held = held - $1 WHERE wallet_id = $2", hold.Amount.String(), walletID)
+	if err != nil {
+		return domain.HoldCaptureResult{}, err
+	}
+
+	entry := domain.NewExternalEntry(domain.Capture, walletID, wallet.Currency, captureAmount.Neg())
+	if err = postJournalEntry(ctx, tx, &entry); err != nil {
+		return domain.HoldCaptureResult{}, err
+	}
+
+	transaction := domain.Transaction{
+		ID:            uuid.New(),
+		WalletID:      walletID,
+		OperationType: domain.Capture,
+		Amount:        captureAmount.Neg(),
+		Currency:      wallet.Currency,
+		BalanceAfter:  wallet.BalanceAfter(wallet.Balance.Sub(captureAmount)),
+		EntryID:       &entry.ID,
+	}
+	if err = insertTransaction(ctx, tx, &transaction); err != nil {
+		return domain.HoldCaptureResult{}, err
+	}
+
+	hold, err = scanHold(tx.QueryRow(ctx,
+		`UPDATE wallet_holds SET status = $1, captured_amount = $2, updated_at = now()
+		WHERE hold_id = $3 RETURNING `+holdColumns,
+		string(domain.HoldCaptured), captureAmount.String(), holdID,
+	))
+	if err != nil {
+		return domain.HoldCaptureResult{}, err
+	}
+
+	return domain.HoldCaptureResult{Hold: hold, Transaction: transaction}, nil
+}
+
+// ReleaseHold освобождает зарезервированные средства без списания
+func (r *WalletRepository) ReleaseHold(ctx context.Context, walletID, holdID uuid.UUID) (_ domain.Hold, err error) {
+	defer translateError(&err)
+
+	return r.releaseHold(ctx, walletID, holdID, domain.HoldReleased)
+}
+
+// ExpireHolds освобождает не более limit просроченных холдов и возвращает их количество.
+// Каждый холд снимается в отдельной транзакции, чтобы не держать блокировки многих кошельков сразу.
+func (r *WalletRepository) ExpireHolds(ctx context.Context, limit int) (_ int, err error) {
+	defer translateError(&err)
+
+	rows, err := r.db.Query(ctx,
+		`SELECT hold_id, wallet_id FROM wallet_holds
+		WHERE status = $1 AND expires_at <= now()
+		ORDER BY expires_at
+		LIMIT $2`,
+		string(domain.HoldActive), limit,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	type expiredHold struct {
+		holdID   uuid.UUID
+		walletID uuid.UUID
+	}
+	var expired []expiredHold
+	for rows.Next() {
+		var hold expiredHold
+		if err = rows.Scan(&hold.holdID, &hold.walletID); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		expired = append(expired, hold)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, hold := range expired {
+		_, err = r.releaseHold(ctx, hold.walletID, hold.holdID, domain.HoldExpired)
+		// Холд мог быть списан или освобожден после выборки
+		if errors.Is(err, app_errors.ErrHoldNotActive) {
+			continue
+		}
+		if err != nil {
+			return count, err
+		}
+		count++
+	}
+
+	return count, nil
+}
+
+// releaseHold снимает резерв и переводит холд в статус status (RELEASED или EXPIRED)
+func (r *WalletRepository) releaseHold(ctx context.Context, walletID, holdID uuid.UUID, status domain.HoldStatus) (domain.Hold, error) {
+	return runTx(ctx, r, pgx.Serializable, func(tx pgx.Tx) (domain.Hold, error) {
+		return releaseHoldTx(ctx, tx, walletID, holdID, status)
+	})
+}
+
+// releaseHoldTx выполняет releaseHold в транзакции tx
+func releaseHoldTx(ctx context.Context, tx pgx.Tx, walletID, holdID uuid.UUID, status domain.HoldStatus) (_ domain.Hold, err error) {
+	_, hold, err := lockActiveHold(ctx, tx, walletID, holdID)
+	// Просроченный холд может быть освобожден вручную или фоновой задачей
+	if errors.Is(err, app_errors.ErrHoldNotActive) && hold.Status == domain.HoldActive {
+		err = nil
+	}
+	if err != nil {
+		return domain.Hold{}, err
+	}
+
+	_, err = tx.Exec(ctx, "UPDATE wallets SET held = held - $1 WHERE wallet_id = $2", hold.Amount.String(), walletID)
+	if err != nil {
+		return domain.Hold{}, err
+	}
+
+	hold, err = scanHold(tx.QueryRow(ctx,
+		`UPDATE wallet_holds SET status = $1, updated_at = now()
+		WHERE hold_id = $2 RETURNING `+holdColumns,
+		string(status), holdID,
+	))
+	if err != nil {
+		return domain.Hold{}, err
+	}
+
+	return hold, nil
+}
+
+// lockActiveHold блокирует кошелек и его холд (в этом порядке, как и остальные операции с кошельком).
+// Для неактивного или просроченного холда возвращает ErrHoldNotActive вместе с прочитанным холдом.
+// Срок сравнивается с часами базы, как и при истечении холдов, чтобы расхождение часов экземпляров
+// не позволяло списать холд, который уже считается истекшим.
+func lockActiveHold(ctx context.Context, tx pgx.Tx, walletID, holdID uuid.UUID) (lockedWallet, domain.Hold, error) {
+	wallet, err := lockWallet(ctx, tx, walletID)
+	if err != nil {
+		return lockedWallet{}, domain.Hold{}, err
+	}
+
+	var unexpired bool
+	hold, err := scanHold(tx.QueryRow(ctx,
+		"SELECT "+holdColumns+", expires_at > now() FROM wallet_holds WHERE hold_id = $1 AND wallet_id = $2 FOR UPDATE",
+		holdID, walletID,
+	), &unexpired)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return lockedWallet{}, domain.Hold{}, app_errors.ErrHoldNotFound
+	}
+	if err != nil {
+		return lockedWallet{}, domain.Hold{}, err
+	}
+
+	if hold.Status != domain.HoldActive || !unexpired {
+		return wallet, hold, app_errors.ErrHoldNotActive
+	}
+
+	return wallet, hold, nil
+}
+
+// scanHold читает холд из колонок holdColumns; значения колонок, выбранных после них, попадают в extra
+func scanHold(row pgx.Row, extra ...any) (domain.Hold, error) {
+	var hold domain.Hold
+	var amountStr, status string
+	var capturedStr *string
+
+	dest := []any{&hold.ID, &hold.WalletID, &amountStr, &capturedStr, &status, &hold.ExpiresAt, &hold.CreatedAt, &hold.UpdatedAt}
+	err := row.Scan(append(dest, extra...)...)
+	if err != nil {
+		return domain.Hold{}, err
+	}
+
+	hold.Status = domain.HoldStatus(status)
+	if hold.Amount, err = decimal.NewFromString(amountStr); err != nil {
+		return domain.Hold{}, err
+	}
+	if capturedStr != nil {
+		captured, err := decimal.NewFromString(*capturedStr)
+		if err != nil {
+			return domain.Hold{}, err
+		}
+		hold.CapturedAmount = &captured
+	}
+
+	return hold, nil
+}
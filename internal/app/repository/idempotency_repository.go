@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+
+	"wallet-app/internal/app/app_errors"
+)
+
+// idempotencyKey — ключ идемпотентности в пределах клиента: одинаковые ключи разных клиентов не пересекаются
+type idempotencyKey struct {
+	subject string
+	key     string
+}
+
+// findIdempotentResponse ищет сохраненный ответ по ключу идемпотентности клиента subject и декодирует его в response.
+// Ключ, использованный с другим телом запроса, отклоняется.
+func findIdempotentResponse(ctx context.Context, tx pgx.Tx, subject, key, fingerprint string, response any) (bool, error) {
+	var storedFingerprint string
+	var storedResponse []byte
+
+	err := tx.QueryRow(ctx,
+		"SELECT request_fingerprint, response FROM idempotency_keys WHERE subject=$1 AND idempotency_key=$2", subject, key,
+	).Scan(&storedFingerprint, &storedResponse)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if storedFingerprint != fingerprint {
+		return false, app_errors.ErrIdempotencyKeyReused
+	}
+
+	if err = json.Unmarshal(storedResponse, response); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// saveIdempotentResponse сохраняет ответ на запрос в той же транзакции, что и изменение баланса.
+// Параллельный запрос с тем же ключом получит ошибку уникальности или сериализации и не применит операцию повторно.
+func saveIdempotentResponse(ctx context.Context, tx pgx.Tx, subject, key, fingerprint string, response any) error {
+	encoded, err := json.Marshal(response)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx,
+		"INSERT INTO idempotency_keys(subject, idempotency_key, request_fingerprint, response) VALUES($1, $2, $3, $4)",
+		subject, key, fingerprint, encoded,
+	)
+	return err
+}
+
+// queueIdempotentResponse добавляет сохранение ответа в пакет запросов batch, как saveIdempotentResponse
+func queueIdempotentResponse(batch *pgx.Batch, subject, key, fingerprint string, response any) error {
+	encoded, err := json.Marshal(response)
+	if err != nil {
+		return err
+	}
+
+	batch.Queue(
+		"INSERT INTO idempotency_keys(subject, idempotency_key, request_fingerprint, response) VALUES($1, $2, $3, $4)",
+		subject, key, fingerprint, encoded,
+	)
+	return nil
+}
@@ -0,0 +1,196 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+
+	"wallet-app/internal/app/domain"
+)
+
+// postJournalEntry записывает сбалансированную запись журнала и обновляет проекции балансов кошельков.
+// Строки кошельков должны быть заблокированы вызывающим кодом; балансы системных счетов
+// не материализуются и вычисляются по проводкам, чтобы не создавать общую горячую строку.
+func postJournalEntry(ctx context.Context, tx pgx.Tx, entry *domain.JournalEntry) error {
+	return postJournalEntries(ctx, tx, []*domain.JournalEntry{entry}, nil)
+}
+
+// postJournalEntryWithShard записывает запись журнала, как postJournalEntry, но проводка по кошельку
+// shard.WalletID увеличивает его шард, а строка кошелька не изменяется
+func postJournalEntryWithShard(ctx context.Context, tx pgx.Tx, entry *domain.JournalEntry, shard *shardTarget) error {
+	return postJournalEntries(ctx, tx, []*domain.JournalEntry{entry}, shard)
+}
+
+// postJournalEntries записывает несколько записей журнала за один обмен с базой.
+// Проекция баланса каждого кошелька обновляется одним запросом на суммарное изменение по всем записям.
+func postJournalEntries(ctx context.Context, tx pgx.Tx, entries []*domain.JournalEntry, shard *shardTarget) error {
+	batch := &pgx.Batch{}
+	deltas := make(map[uuid.UUID]decimal.Decimal)
+	var accounts []uuid.UUID
+
+	for _, entry := range entries {
+		if err := entry.Validate(); err != nil {
+			return err
+		}
+
+		batch.Queue(
+			"INSERT INTO journal_entries(entry_id, operation_type) VALUES($1, $2) RETURNING created_at",
+			entry.ID, string(entry.OperationType),
+		).QueryRow(func(row pgx.Row) error {
+			return row.Scan(&entry.CreatedAt)
+		})
+
+		for _, posting := range entry.Postings {
+			batch.Queue(
+				"INSERT INTO ledger_postings(entry_id, account_id, amount, currency) VALUES($1, $2, $3, $4)",
+				entry.ID, posting.AccountID, posting.Amount.String(), posting.Currency,
+			)
+			// Балансы системных счетов не материализуются
+			if domain.IsSystemAccount(posting.AccountID) {
+				continue
+			}
+			if _, ok := deltas[posting.AccountID]; !ok {
+				accounts = append(accounts, posting.AccountID)
+			}
+			deltas[posting.AccountID] = deltas[posting.AccountID].Add(posting.Amount)
+		}
+	}
+
+	for _, accountID := range accounts {
+		if shard != nil && accountID == shard.WalletID {
+			batch.Queue(
+				"UPDATE wallet_balance_shards SET balance = balance + $1 WHERE wallet_id = $2 AND shard_id = $3",
+				deltas[accountID].String(), shard.WalletID, shard.ShardID,
+			)
+			continue
+		}
+		batch.Queue(
+			"UPDATE wallets SET balance = balance + $1 WHERE wallet_id = $2",
+			deltas[accountID].String(), accountID,
+		)
+	}
+
+	return tx.SendBatch(ctx, batch).Close()
+}
+
+// VerifyLedger сверяет журнал на согласованном снимке данных
+func (r *WalletRepository) VerifyLedger(ctx context.Context) (_ domain.LedgerReport, err error) {
+	defer translateError(&err)
+
+	tx, err := r.db.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return domain.LedgerReport{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	var report domain.LedgerReport
+
+	// Суммы проводок по валютам; суммировать разные валюты между собой нельзя
+	rows, err := tx.Query(ctx, "SELECT currency, SUM(amount) FROM ledger_postings GROUP BY currency ORDER BY currency")
+	if err != nil {
+		return domain.LedgerReport{}, err
+	}
+
+	report.Totals = []domain.CurrencyTotal{}
+	totalsBalanced := true
+	for rows.Next() {
+		var total domain.CurrencyTotal
+		var totalStr string
+		if err = rows.Scan(&total.Currency, &totalStr); err != nil {
+			rows.Close()
+			return domain.LedgerReport{}, err
+		}
+		if total.Total, err = decimal.NewFromString(totalStr); err != nil {
+			rows.Close()
+			return domain.LedgerReport{}, err
+		}
+		totalsBalanced = totalsBalanced && total.Total.IsZero()
+		report.Totals = append(report.Totals, total)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return domain.LedgerReport{}, err
+	}
+
+	err = tx.QueryRow(ctx,
+		`SELECT COUNT(DISTINCT entry_id) FROM (
+			SELECT entry_id FROM ledger_postings GROUP BY entry_id, currency HAVING SUM(amount) <> 0
+		) AS unbalanced`,
+	).Scan(&report.UnbalancedEntries)
+	if err != nil {
+		return domain.LedgerReport{}, err
+	}
+
+	// Балансы системных счетов в разрезе валют
+	rows, err = tx.Query(ctx,
+		`SELECT a.account_id, a.code, p.currency, SUM(p.amount)
+		FROM ledger_accounts a
+		JOIN ledger_postings p ON p.account_id = a.account_id
+		WHERE a.account_type = 'SYSTEM'
+		GROUP BY a.account_id, a.code, p.currency
+		ORDER BY a.code, p.currency`,
+	)
+	if err != nil {
+		return domain.LedgerReport{}, err
+	}
+
+	report.SystemAccounts = []domain.AccountBalance{}
+	for rows.Next() {
+		var account domain.AccountBalance
+		var balanceStr string
+		if err = rows.Scan(&account.AccountID, &account.Code, &account.Currency, &balanceStr); err != nil {
+			rows.Close()
+			return domain.LedgerReport{}, err
+		}
+		if account.Balance, err = decimal.NewFromString(balanceStr); err != nil {
+			rows.Close()
+			return domain.LedgerReport{}, err
+		}
+		report.SystemAccounts = append(report.SystemAccounts, account)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return domain.LedgerReport{}, err
+	}
+
+	// Кошельки, у которых проекция баланса (вместе с шардами) разошлась с журналом
+	rows, err = tx.Query(ctx,
+		`SELECT w.wallet_id, w.balance + COALESCE(sh.total, 0), COALESCE(SUM(p.amount), 0)
+		FROM wallets w
+		LEFT JOIN (
+			SELECT wallet_id, SUM(balance) AS total FROM wallet_balance_shards GROUP BY wallet_id
+		) sh ON sh.wallet_id = w.wallet_id
+		LEFT JOIN ledger_postings p ON p.account_id = w.wallet_id
+		GROUP BY w.wallet_id, w.balance, sh.total
+		HAVING w.balance + COALESCE(sh.total, 0) <> COALESCE(SUM(p.amount), 0)`,
+	)
+	if err != nil {
+		return domain.LedgerReport{}, err
+	}
+	defer rows.Close()
+
+	report.WalletDiscrepancies = []domain.WalletDiscrepancy{}
+	for rows.Next() {
+		var discrepancy domain.WalletDiscrepancy
+		var projectedStr, ledgerStr string
+		if err = rows.Scan(&discrepancy.WalletID, &projectedStr, &ledgerStr); err != nil {
+			return domain.LedgerReport{}, err
+		}
+		if discrepancy.ProjectedBalance, err = decimal.NewFromString(projectedStr); err != nil {
+			return domain.LedgerReport{}, err
+		}
+		if discrepancy.LedgerBalance, err = decimal.NewFromString(ledgerStr); err != nil {
+			return domain.LedgerReport{}, err
+		}
+		report.WalletDiscrepancies = append(report.WalletDiscrepancies, discrepancy)
+	}
+	if err = rows.Err(); err != nil {
+		return domain.LedgerReport{}, err
+	}
+
+	report.Balanced = totalsBalanced && report.UnbalancedEntries == 0 && len(report.WalletDiscrepancies) == 0
+
+	return report, nil
+}
@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+
+	"wallet-app/internal/app/app_errors"
+	"wallet-app/internal/app/domain"
+)
+
+// GetWalletLimits возвращает уровень и собственные ограничения кошелька; Effective не заполняется
+func (r *WalletRepository) GetWalletLimits(ctx context.Context, walletID uuid.UUID) (_ domain.WalletLimits, err error) {
+	defer translateError(&err)
+
+	limits := domain.WalletLimits{WalletID: walletID}
+	var tier, maxTransaction, dailyWithdrawal, monthlyWithdrawal, maxBalance *string
+	err = r.db.QueryRow(ctx,
+		`SELECT w.currency, l.tier, l.max_transaction, l.daily_withdrawal, l.monthly_withdrawal, l.max_balance, l.updated_at
+		FROM wallets w LEFT JOIN wallet_limits l ON l.wallet_id = w.wallet_id
+		WHERE w.wallet_id = $1`,
+		walletID,
+	).Scan(&limits.Currency, &tier, &maxTransaction, &dailyWithdrawal, &monthlyWithdrawal, &maxBalance, &limits.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.WalletLimits{}, app_errors.ErrWalletNotFound
+	}
+	if err != nil {
+		return domain.WalletLimits{}, err
+	}
+
+	if tier != nil {
+		limits.Tier = *tier
+	}
+	for _, field := range []struct {
+		value  *string
+		target **decimal.Decimal
+	}{
+		{maxTransaction, &limits.Overrides.MaxTransaction},
+		{dailyWithdrawal, &limits.Overrides.DailyWithdrawal},
+		{monthlyWithdrawal, &limits.Overrides.MonthlyWithdrawal},
+		{maxBalance, &limits.Overrides.MaxBalance},
+	} {
+		if *field.target, err = parseNullableDecimal(field.value); err != nil {
+			return domain.WalletLimits{}, err
+		}
+	}
+
+	return limits, nil
+}
+
+// PutWalletLimits заменяет уровень и собственные ограничения кошелька
+func (r *WalletRepository) PutWalletLimits(ctx context.Context, limits domain.WalletLimits) (_ domain.WalletLimits, err error) {
+	defer translateError(&err)
+
+	// Ограничения вставляются выборкой из wallets: если кошелька нет, запрос не вернет строк
+	var updatedAt time.Time
+	err = r.db.QueryRow(ctx,
+		`INSERT INTO wallet_limits(wallet_id, tier, max_transaction, daily_withdrawal, monthly_withdrawal, max_balance)
+		SELECT wallet_id, $2, $3, $4, $5, $6 FROM wallets WHERE wallet_id = $1
+		ON CONFLICT (wallet_id) DO UPDATE SET tier = EXCLUDED.tier, max_transaction = EXCLUDED.max_transaction,
+			daily_withdrawal = EXCLUDED.daily_withdrawal, monthly_withdrawal = EXCLUDED.monthly_withdrawal,
+			max_balance = EXCLUDED.max_balance, updated_at = now()
+		RETURNING updated_at, (SELECT currency FROM wallets WHERE wallet_id = $1)`,
+		limits.WalletID, nullableString(limits.Tier), nullableDecimal(limits.Overrides.MaxTransaction),
+		nullableDecimal(limits.Overrides.DailyWithdrawal), nullableDecimal(limits.Overrides.MonthlyWithdrawal),
+		nullableDecimal(limits.Overrides.MaxBalance),
+	).Scan(&updatedAt, &limits.Currency)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.WalletLimits{}, app_errors.ErrWalletNotFound
+	}
+	if err != nil {
+		return domain.WalletLimits{}, err
+	}
+
+	limits.UpdatedAt = &updatedAt
+	return limits, nil
+}
+
+// withdrawalUsage возвращает сумму списаний кошелька за текущие календарные день и месяц в UTC.
+// Границы периодов считаются по часам базы, как и время создания операций в истории.
+func withdrawalUsage(ctx context.Context, tx pgx.Tx, walletID uuid.UUID) (domain.WithdrawalUsage, error) {
+	withdrawalTypes := make([]string, len(domain.WithdrawalTypes))
+	for i, operationType := range domain.WithdrawalTypes {
+		withdrawalTypes[i] = string(operationType)
+	}
+
+	var dailyStr, monthlyStr string
+	err := tx.QueryRow(ctx,
+		`SELECT COALESCE(SUM(-amount) FILTER (WHERE created_at >= date_trunc('day', now(), 'UTC')), 0),
+			COALESCE(SUM(-amount), 0)
+		FROM wallet_transactions
+		WHERE wallet_id = $1 AND amount < 0 AND operation_type = ANY($2)
+			AND created_at >= date_trunc('month', now(), 'UTC')`,
+		walletID, withdrawalTypes,
+	).Scan(&dailyStr, &monthlyStr)
+	if err != nil {
+		return domain.WithdrawalUsage{}, err
+	}
+
+	var usage domain.WithdrawalUsage
+	if usage.Daily, err = decimal.NewFromString(dailyStr); err != nil {
+		return domain.WithdrawalUsage{}, err
+	}
+	if usage.Monthly, err = decimal.NewFromString(monthlyStr); err != nil {
+		return domain.WithdrawalUsage{}, err
+	}
+	return usage, nil
+}
+
+// checkLimits проверяет операцию на сумму amount по ограничениям limits кошелька, заблокированного в tx.
+// История операций читается, только если ограничены списания, а шарды — только если ограничен баланс.
+// Активные холды кошелька (wallet.Held) учитываются в списаниях за день и месяц как будущие списания.
+func checkLimits(ctx context.Context, tx pgx.Tx, walletID uuid.UUID, wallet lockedWallet, limits domain.TransactionLimits, amount decimal.Decimal) error {
+	if limits.IsZero() {
+		return nil
+	}
+
+	var usage domain.WithdrawalUsage
+	if amount.IsNegative() && limits.LimitsWithdrawals() {
+		var err error
+		if usage, err = withdrawalUsage(ctx, tx, walletID); err != nil {
+			return err
+		}
+		usage = usage.Add(wallet.Held.Neg())
+	}
+
+	balance := wallet.Balance
+	if amount.IsPositive() && limits.MaxBalance != nil && wallet.ShardCount > 0 {
+		shardTotal, err := lockShardTotal(ctx, tx, walletID)
+		if err != nil {
+			return err
+		}
+		balance = balance.Add(shardTotal)
+	}
+
+	return limits.Check(amount, balance.Add(amount), usage)
+}
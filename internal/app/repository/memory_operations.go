@@ -0,0 +1,604 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"wallet-app/internal/app/app_errors"
+	"wallet-app/internal/app/domain"
+)
+
+// UpdateBalance изменяет баланс кошелька и записывает операцию в историю
+func (r *MemoryRepository) UpdateBalance(ctx context.Context, update domain.BalanceUpdate) (domain.Transaction, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.Transaction{}, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.updateBalance(update)
+}
+
+// UpdateBalanceBatch применяет пакет изменений баланса одного кошелька по порядку под одной блокировкой.
+// Отклоненная операция получает ошибку в своем результате; ошибка самой функции означает,
+// что не применена ни одна операция.
+func (r *MemoryRepository) UpdateBalanceBatch(ctx context.Context, walletID uuid.UUID, updates []domain.BalanceUpdate) ([]domain.BalanceUpdateResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, err := r.wallet(walletID); err != nil {
+		return nil, err
+	}
+
+	results := make([]domain.BalanceUpdateResult, len(updates))
+	for i, update := range updates {
+		results[i].Transaction, results[i].Err = r.updateBalance(update)
+	}
+
+	return results, nil
+}
+
+// updateBalance выполняет UpdateBalance под блокировкой r.mu
+func (r *MemoryRepository) updateBalance(update domain.BalanceUpdate) (domain.Transaction, error) {
+	if update.IdempotencyKey != "" {
+		var stored domain.Transaction
+		found, err := r.findIdempotentResponse(update.IdempotencySubject, update.IdempotencyKey, update.Fingerprint, &stored)
+		if err != nil {
+			return domain.Transaction{}, err
+		}
+		if found {
+			return stored, nil
+		}
+	}
+
+	wallet, err := r.wallet(update.WalletID)
+	if err != nil {
+		return domain.Transaction{}, err
+	}
+
+	locked := wallet.locked()
+	if err = locked.CheckVersion(update.ExpectedVersion); err != nil {
+		return domain.Transaction{}, err
+	}
+	if err = locked.Status.CheckMovement(update.Amount); err != nil {
+		return domain.Transaction{}, err
+	}
+	if err = locked.CheckAmount(update.Currency, update.Amount); err != nil {
+		return domain.Transaction{}, err
+	}
+
+	// Списание не может затрагивать зарезервированные холдами средства
+	if update.Amount.IsNegative() && locked.Available().Add(update.Amount).IsNegative() {
+		return domain.Transaction{}, app_errors.ErrInsufficientFunds
+	}
+	if err = wallet.checkLimits(update.Limits, wallet.held, update.Amount); err != nil {
+		return domain.Transaction{}, err
+	}
+
+	now := memoryNow()
+	entry := domain.NewExternalEntry(update.OperationType, update.WalletID, locked.Currency, update.Amount)
+	if err = r.postEntry(&entry, now); err != nil {
+		return domain.Transaction{}, err
+	}
+
+	transaction := &domain.Transaction{
+		ID:            uuid.New(),
+		WalletID:      update.WalletID,
+		OperationType: update.OperationType,
+		Amount:        update.Amount,
+		Currency:      locked.Currency,
+		BalanceAfter:  locked.BalanceAfter(locked.Balance.Add(update.Amount)),
+		EntryID:       &entry.ID,
+	}
+	r.addTransaction(transaction, now)
+
+	if update.IdempotencyKey != "" {
+		if err = r.saveIdempotentResponse(update.IdempotencySubject, update.IdempotencyKey, update.Fingerprint, transaction); err != nil {
+			return domain.Transaction{}, err
+		}
+	}
+
+	return *transaction, nil
+}
+
+// ApplyCorrection проводит сторно или возврат исходной операции кошелька
+func (r *MemoryRepository) ApplyCorrection(ctx context.Context, update domain.BalanceUpdate) (domain.Transaction, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.Transaction{}, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if update.IdempotencyKey != "" {
+		var stored domain.Transaction
+		found, err := r.findIdempotentResponse(update.IdempotencySubject, update.IdempotencyKey, update.Fingerprint, &stored)
+		if err != nil {
+			return domain.Transaction{}, err
+		}
+		if found {
+			return stored, nil
+		}
+	}
+
+	wallet, err := r.wallet(update.WalletID)
+	if err != nil {
+		return domain.Transaction{}, err
+	}
+
+	locked := wallet.locked()
+	if err = locked.CheckVersion(update.ExpectedVersion); err != nil {
+		return domain.Transaction{}, err
+	}
+	if err = locked.CheckAmount(update.Currency, update.Amount); err != nil {
+		return domain.Transaction{}, err
+	}
+
+	original, ok := r.transactions[*update.OriginalTransactionID]
+	if !ok || original.WalletID != update.WalletID {
+		return domain.Transaction{}, app_errors.ErrTransactionNotFound
+	}
+	if !isCorrectable(update.OperationType, *original) {
+		return domain.Transaction{}, app_errors.ErrTransactionNotReversible
+	}
+
+	// Сколько по исходной операции уже возвращено сторно и возвратами
+	corrected := decimal.Zero
+	for _, transaction := range wallet.transactions {
+		if transaction.OriginalTransactionID != nil && *transaction.OriginalTransactionID == original.ID {
+			corrected = corrected.Add(transaction.Amount.Abs())
+		}
+	}
+
+	remaining := original.Amount.Abs().Sub(corrected)
+	amount := update.Amount.Abs()
+	if amount.IsZero() {
+		amount = remaining
+	}
+	if amount.IsZero() || amount.GreaterThan(remaining) {
+		return domain.Transaction{}, app_errors.ErrRefundExceedsOriginal
+	}
+
+	// Движение по кошельку противоположно исходной операции
+	signedAmount := amount
+	if original.Amount.IsPositive() {
+		signedAmount = amount.Neg()
+	}
+
+	if err = locked.Status.CheckMovement(signedAmount); err != nil {
+		return domain.Transaction{}, err
+	}
+	if signedAmount.IsNegative() && locked.Available().Add(signedAmount).IsNegative() {
+		return domain.Transaction{}, app_errors.ErrInsufficientFunds
+	}
+	if err = wallet.checkLimits(update.Limits, wallet.held, signedAmount); err != nil {
+		return domain.Transaction{}, err
+	}
+
+	now := memoryNow()
+	entry := domain.NewCorrectionEntry(update.OperationType, update.WalletID, r.correctionCounterAccount(original),
+		locked.Currency, signedAmount)
+	if err = r.postEntry(&entry, now); err != nil {
+		return domain.Transaction{}, err
+	}
+
+	transaction := &domain.Transaction{
+		ID:                    uuid.New(),
+		WalletID:              update.WalletID,
+		OperationType:         update.OperationType,
+		Amount:                signedAmount,
+		Currency:              locked.Currency,
+		BalanceAfter:          locked.BalanceAfter(locked.Balance.Add(signedAmount)),
+		EntryID:               &entry.ID,
+		OriginalTransactionID: &original.ID,
+	}
+	r.addTransaction(transaction, now)
+
+	if update.IdempotencyKey != "" {
+		if err = r.saveIdempotentResponse(update.IdempotencySubject, update.IdempotencyKey, update.Fingerprint, transaction); err != nil {
+			return domain.Transaction{}, err
+		}
+	}
+
+	return *transaction, nil
+}
+
+// correctionCounterAccount возвращает счет, против которого была проведена исходная операция
+func (r *MemoryRepository) correctionCounterAccount(original *domain.Transaction) uuid.UUID {
+	if original.EntryID != nil {
+		for _, posting := range r.entries[*original.EntryID] {
+			if posting.AccountID != original.WalletID {
+				return posting.AccountID
+			}
+		}
+	}
+	if original.Amount.IsPositive() {
+		return domain.SystemAccountCashIn
+	}
+	return domain.SystemAccountCashOut
+}
+
+// Transfer списывает средства с одного кошелька и зачисляет на другой.
+// Перевод между кошельками в разных валютах выполняется только по котировке (update.Conversion).
+func (r *MemoryRepository) Transfer(ctx context.Context, update domain.TransferUpdate) (domain.TransferResult, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.TransferResult{}, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if update.IdempotencyKey != "" {
+		var stored domain.TransferResult
+		found, err := r.findIdempotentResponse(update.IdempotencySubject, update.IdempotencyKey, update.Fingerprint, &stored)
+		if err != nil {
+			return domain.TransferResult{}, err
+		}
+		if found {
+			return stored, nil
+		}
+	}
+
+	// Кошельки проверяются в том же порядке, в каком WalletRepository их блокирует
+	lockOrder := []uuid.UUID{update.FromWalletID, update.ToWalletID}
+	if bytes.Compare(lockOrder[0][:], lockOrder[1][:]) > 0 {
+		lockOrder[0], lockOrder[1] = lockOrder[1], lockOrder[0]
+	}
+	wallets := make(map[uuid.UUID]lockedWallet, len(lockOrder))
+	for _, walletID := range lockOrder {
+		wallet, err := r.wallet(walletID)
+		if err != nil {
+			return domain.TransferResult{}, err
+		}
+		wallets[walletID] = wallet.locked()
+	}
+
+	fromWallet, toWallet := wallets[update.FromWalletID], wallets[update.ToWalletID]
+	if err := fromWallet.Status.CheckMovement(update.Amount.Neg()); err != nil {
+		return domain.TransferResult{}, err
+	}
+	if err := fromWallet.CheckAmount(update.Currency, update.Amount); err != nil {
+		return domain.TransferResult{}, err
+	}
+
+	creditAmount := update.Amount
+	if update.Conversion != nil {
+		creditAmount = update.Conversion.ConvertedAmount
+	} else if fromWallet.Currency != toWallet.Currency {
+		return domain.TransferResult{}, app_errors.ErrCurrencyMismatch
+	}
+	if err := toWallet.Status.CheckMovement(creditAmount); err != nil {
+		return domain.TransferResult{}, err
+	}
+
+	// Переводить можно только незарезервированные средства
+	if fromWallet.Available().LessThan(update.Amount) {
+		return domain.TransferResult{}, app_errors.ErrInsufficientFunds
+	}
+	if err := r.wallets[update.FromWalletID].checkLimits(update.FromLimits, fromWallet.Held, update.Amount.Neg()); err != nil {
+		return domain.TransferResult{}, err
+	}
+	if err := r.wallets[update.ToWalletID].checkLimits(update.ToLimits, toWallet.Held, creditAmount); err != nil {
+		return domain.TransferResult{}, err
+	}
+
+	result := domain.TransferResult{
+		ID:           uuid.New(),
+		FromWalletID: update.FromWalletID,
+		ToWalletID:   update.ToWalletID,
+		Amount:       update.Amount,
+		Currency:     fromWallet.Currency,
+		Conversion:   update.Conversion,
+	}
+
+	entry := domain.NewTransferEntry(update.FromWalletID, update.ToWalletID, fromWallet.Currency, update.Amount)
+	var quote *memoryQuote
+	if update.Conversion != nil {
+		var err error
+		if quote, err = r.activeQuote(*update.Conversion, fromWallet.Currency, toWallet.Currency); err != nil {
+			return domain.TransferResult{}, err
+		}
+		entry = domain.NewConversionEntry(update.FromWalletID, update.ToWalletID,
+			fromWallet.Currency, update.Amount, toWallet.Currency, creditAmount)
+	}
+
+	now := memoryNow()
+	if err := r.postEntry(&entry, now); err != nil {
+		return domain.TransferResult{}, err
+	}
+	if quote != nil {
+		quote.transferID = &result.ID
+	}
+
+	result.Debit = domain.Transaction{
+		ID:                   uuid.New(),
+		WalletID:             update.FromWalletID,
+		OperationType:        domain.Transfer,
+		Amount:               update.Amount.Neg(),
+		Currency:             fromWallet.Currency,
+		BalanceAfter:         fromWallet.BalanceAfter(fromWallet.Balance.Sub(update.Amount)),
+		TransferID:           &result.ID,
+		CounterpartyWalletID: &result.ToWalletID,
+		EntryID:              &entry.ID,
+	}
+	result.Credit = domain.Transaction{
+		ID:                   uuid.New(),
+		WalletID:             update.ToWalletID,
+		OperationType:        domain.Transfer,
+		Amount:               creditAmount,
+		Currency:             toWallet.Currency,
+		BalanceAfter:         toWallet.BalanceAfter(toWallet.Balance.Add(creditAmount)),
+		TransferID:           &result.ID,
+		CounterpartyWalletID: &result.FromWalletID,
+		EntryID:              &entry.ID,
+	}
+	if update.Conversion != nil {
+		rate := update.Conversion.Rate
+		result.Debit.ExchangeRate, result.Debit.CounterAmount = &rate, &creditAmount
+		result.Debit.CounterCurrency = toWallet.Currency
+		result.Credit.ExchangeRate, result.Credit.CounterAmount = &rate, &result.Amount
+		result.Credit.CounterCurrency = fromWallet.Currency
+	}
+
+	debit, credit := result.Debit, result.Credit
+	r.addTransaction(&debit, now)
+	r.addTransaction(&credit, now)
+	result.Debit.CreatedAt, result.Credit.CreatedAt = now, now
+
+	if update.IdempotencyKey != "" {
+		if err := r.saveIdempotentResponse(update.IdempotencySubject, update.IdempotencyKey, update.Fingerprint, result); err != nil {
+			return domain.TransferResult{}, err
+		}
+	}
+
+	return result, nil
+}
+
+// activeQuote проверяет, что котировку можно использовать для перевода, как claimQuote
+func (r *MemoryRepository) activeQuote(conversion domain.Conversion, fromCurrency, toCurrency string) (*memoryQuote, error) {
+	quote, ok := r.quotes[conversion.QuoteID]
+	if !ok || quote.transferID != nil || !quote.quote.ExpiresAt.After(time.Now()) {
+		return nil, app_errors.ErrQuoteNotActive
+	}
+	if quote.quote.FromCurrency != fromCurrency || quote.quote.ToCurrency != toCurrency || quote.quote.ToCurrency != conversion.ToCurrency {
+		return nil, app_errors.ErrCurrencyMismatch
+	}
+	if !quote.quote.Rate.Equal(conversion.Rate) {
+		return nil, app_errors.ErrQuoteNotActive
+	}
+	return quote, nil
+}
+
+// CreateQuote сохраняет котировку с зафиксированным курсом
+func (r *MemoryRepository) CreateQuote(ctx context.Context, quote domain.FXQuote) (domain.FXQuote, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.FXQuote{}, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.quotes[quote.ID]; ok {
+		return domain.FXQuote{}, app_errors.ErrConflict
+	}
+	quote.CreatedAt = memoryNow()
+	r.quotes[quote.ID] = &memoryQuote{quote: quote}
+
+	return quote, nil
+}
+
+// GetQuote возвращает котировку по идентификатору
+func (r *MemoryRepository) GetQuote(ctx context.Context, quoteID uuid.UUID) (domain.FXQuote, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.FXQuote{}, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	quote, ok := r.quotes[quoteID]
+	if !ok {
+		return domain.FXQuote{}, app_errors.ErrQuoteNotFound
+	}
+	return quote.quote, nil
+}
+
+// CreateHold резервирует средства кошелька до expiresAt, проверяя резерв по ограничениям limits как списание
+func (r *MemoryRepository) CreateHold(ctx context.Context, walletID uuid.UUID, amount decimal.Decimal, expiresAt time.Time, limits domain.TransactionLimits) (domain.Hold, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.Hold{}, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	wallet, err := r.wallet(walletID)
+	if err != nil {
+		return domain.Hold{}, err
+	}
+
+	locked := wallet.locked()
+	if err = locked.Status.CheckMovement(amount.Neg()); err != nil {
+		return domain.Hold{}, err
+	}
+	if err = locked.CheckAmount("", amount); err != nil {
+		return domain.Hold{}, err
+	}
+	if locked.Available().LessThan(amount) {
+		return domain.Hold{}, app_errors.ErrInsufficientFunds
+	}
+	if err = wallet.checkLimits(limits, wallet.held, amount.Neg()); err != nil {
+		return domain.Hold{}, err
+	}
+
+	now := memoryNow()
+	hold := &domain.Hold{
+		ID:        uuid.New(),
+		WalletID:  walletID,
+		Amount:    amount,
+		Status:    domain.HoldActive,
+		ExpiresAt: expiresAt,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	r.holds[hold.ID] = hold
+	wallet.held = wallet.held.Add(amount)
+	wallet.touch(now)
+
+	return *hold, nil
+}
+
+// CaptureHold списывает зарезервированные средства. amount == nil означает списание всего холда;
+// при частичном списании остаток резерва освобождается. Списание проверяется по ограничениям limits.
+func (r *MemoryRepository) CaptureHold(ctx context.Context, walletID, holdID uuid.UUID, amount *decimal.Decimal, limits domain.TransactionLimits) (domain.HoldCaptureResult, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.HoldCaptureResult{}, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	wallet, hold, err := r.activeHold(walletID, holdID)
+	if err != nil {
+		return domain.HoldCaptureResult{}, err
+	}
+
+	locked := wallet.locked()
+	captureAmount := hold.Amount
+	if amount != nil {
+		if amount.GreaterThan(hold.Amount) {
+			return domain.HoldCaptureResult{}, app_errors.ErrCaptureExceedsHold
+		}
+		if err = locked.CheckAmount("", *amount); err != nil {
+			return domain.HoldCaptureResult{}, err
+		}
+		captureAmount = *amount
+	}
+
+	if err = locked.Status.CheckMovement(captureAmount.Neg()); err != nil {
+		return domain.HoldCaptureResult{}, err
+	}
+	// Собственный резерв холда не считается будущим списанием: его заменяет само списание
+	if err = wallet.checkLimits(limits, wallet.held.Sub(hold.Amount), captureAmount.Neg()); err != nil {
+		return domain.HoldCaptureResult{}, err
+	}
+
+	now := memoryNow()
+	entry := domain.NewExternalEntry(domain.Capture, walletID, locked.Currency, captureAmount.Neg())
+	if err = r.postEntry(&entry, now); err != nil {
+		return domain.HoldCaptureResult{}, err
+	}
+	wallet.held = wallet.held.Sub(hold.Amount)
+
+	transaction := &domain.Transaction{
+		ID:            uuid.New(),
+		WalletID:      walletID,
+		OperationType: domain.Capture,
+		Amount:        captureAmount.Neg(),
+		Currency:      locked.Currency,
+		BalanceAfter:  locked.BalanceAfter(locked.Balance.Sub(captureAmount)),
+		EntryID:       &entry.ID,
+	}
+	r.addTransaction(transaction, now)
+
+	hold.Status = domain.HoldCaptured
+	hold.CapturedAmount = &captureAmount
+	hold.UpdatedAt = now
+
+	return domain.HoldCaptureResult{Hold: *hold, Transaction: *transaction}, nil
+}
+
+// ReleaseHold освобождает зарезервированные средства без списания
+func (r *MemoryRepository) ReleaseHold(ctx context.Context, walletID, holdID uuid.UUID) (domain.Hold, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.Hold{}, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.releaseHold(walletID, holdID, domain.HoldReleased)
+}
+
+// ExpireHolds освобождает не более limit просроченных холдов и возвращает их количество
+func (r *MemoryRepository) ExpireHolds(ctx context.Context, limit int) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	var expired []*domain.Hold
+	for _, hold := range r.holds {
+		if hold.Status == domain.HoldActive && !hold.ExpiresAt.After(now) {
+			expired = append(expired, hold)
+		}
+	}
+	sort.Slice(expired, func(i, j int) bool {
+		return expired[i].ExpiresAt.Before(expired[j].ExpiresAt)
+	})
+	if len(expired) > limit {
+		expired = expired[:limit]
+	}
+
+	for _, hold := range expired {
+		if _, err := r.releaseHold(hold.WalletID, hold.ID, domain.HoldExpired); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(expired), nil
+}
+
+// releaseHold снимает резерв и переводит холд в статус status (RELEASED или EXPIRED)
+func (r *MemoryRepository) releaseHold(walletID, holdID uuid.UUID, status domain.HoldStatus) (domain.Hold, error) {
+	wallet, hold, err := r.activeHold(walletID, holdID)
+	// Просроченный холд может быть освобожден вручную или фоновой задачей
+	if errors.Is(err, app_errors.ErrHoldNotActive) && hold.Status == domain.HoldActive {
+		err = nil
+	}
+	if err != nil {
+		return domain.Hold{}, err
+	}
+
+	now := memoryNow()
+	wallet.held = wallet.held.Sub(hold.Amount)
+	wallet.touch(now)
+	hold.Status = status
+	hold.UpdatedAt = now
+
+	return *hold, nil
+}
+
+// activeHold находит кошелек и его холд. Для неактивного или просроченного холда
+// возвращает ErrHoldNotActive вместе с холдом, как lockActiveHold.
+func (r *MemoryRepository) activeHold(walletID, holdID uuid.UUID) (*memoryWallet, *domain.Hold, error) {
+	wallet, err := r.wallet(walletID)
+	if err != nil {
+		return nil, &domain.Hold{}, err
+	}
+
+	hold, ok := r.holds[holdID]
+	if !ok || hold.WalletID != walletID {
+		return nil, &domain.Hold{}, app_errors.ErrHoldNotFound
+	}
+
+	if hold.Status != domain.HoldActive || !hold.ExpiresAt.After(time.Now()) {
+		return wallet, hold, app_errors.ErrHoldNotActive
+	}
+
+	return wallet, hold, nil
+}
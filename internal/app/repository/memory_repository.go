@@ -0,0 +1,706 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"maps"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"wallet-app/internal/app/app_errors"
+	"wallet-app/internal/app/domain"
+)
+
+// MemoryRepository — хранилище кошельков в памяти процесса для тестов, демонстраций и режима --storage=memory.
+// Проверки и порядок их выполнения совпадают с WalletRepository. Операции записи выполняются под общей
+// блокировкой и применяются целиком или не применяются вовсе, что соответствует уровню SERIALIZABLE.
+// Шардированный кошелек хранит весь баланс в одной записи, но в остальном ведет себя как в Postgres:
+// не возвращает версию и не заполняет balanceAfter.
+type MemoryRepository struct {
+	mu sync.RWMutex
+
+	wallets      map[uuid.UUID]*memoryWallet
+	transactions map[uuid.UUID]*domain.Transaction
+	holds        map[uuid.UUID]*domain.Hold
+	quotes       map[uuid.UUID]*memoryQuote
+	idempotency  map[idempotencyKey]memoryResponse
+	// Ключи API по хешу в шестнадцатеричной записи
+	apiKeys map[string]domain.APIKey
+	// Роли, выданные на кошельки, по кошельку и клиенту
+	grants map[uuid.UUID]map[string]domain.WalletGrant
+	// Уровни и собственные ограничения кошельков, которым они назначались
+	limits map[uuid.UUID]domain.WalletLimits
+	// Использованные nonce подписанных запросов и срок их хранения
+	nonces      map[string]time.Time
+	noncesSwept time.Time
+
+	// Журнал: проводки в порядке записи и счета, против которых проведена каждая запись
+	postings []memoryPosting
+	entries  map[uuid.UUID][]domain.Posting
+}
+
+type memoryWallet struct {
+	wallet          domain.Wallet
+	held            decimal.Decimal
+	version         int64
+	statusReason    string
+	statusChangedAt *time.Time
+	// Операции кошелька в порядке создания
+	transactions []*domain.Transaction
+}
+
+type memoryQuote struct {
+	quote      domain.FXQuote
+	transferID *uuid.UUID
+}
+
+type memoryResponse struct {
+	fingerprint string
+	response    []byte
+}
+
+type memoryPosting struct {
+	entryID uuid.UUID
+	domain.Posting
+}
+
+// systemAccountCodes — коды системных счетов, как в таблице ledger_accounts
+var systemAccountCodes = map[uuid.UUID]string{
+	domain.SystemAccountCashIn:         "external_cash_in",
+	domain.SystemAccountCashOut:        "external_cash_out",
+	domain.SystemAccountOpeningBalance: "opening_balance",
+	domain.SystemAccountFXConversion:   "fx_conversion",
+}
+
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{
+		wallets:      make(map[uuid.UUID]*memoryWallet),
+		transactions: make(map[uuid.UUID]*domain.Transaction),
+		holds:        make(map[uuid.UUID]*domain.Hold),
+		quotes:       make(map[uuid.UUID]*memoryQuote),
+		idempotency:  make(map[idempotencyKey]memoryResponse),
+		apiKeys:      make(map[string]domain.APIKey),
+		grants:       make(map[uuid.UUID]map[string]domain.WalletGrant),
+		limits:       make(map[uuid.UUID]domain.WalletLimits),
+		nonces:       make(map[string]time.Time),
+		entries:      make(map[uuid.UUID][]domain.Posting),
+	}
+}
+
+// memoryNow возвращает текущее время с точностью timestamptz
+func memoryNow() time.Time {
+	return time.Now().UTC().Truncate(time.Microsecond)
+}
+
+// CreateWallet создает новый кошелек с нулевым балансом
+func (r *MemoryRepository) CreateWallet(ctx context.Context, req domain.CreateWalletRequest) (domain.Wallet, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.Wallet{}, err
+	}
+
+	metadata := maps.Clone(req.Metadata)
+	if metadata == nil {
+		metadata = map[string]any{}
+	}
+	labels := slices.Clone(req.Labels)
+	if labels == nil {
+		labels = []string{}
+	}
+
+	now := memoryNow()
+	wallet := &memoryWallet{
+		wallet: domain.Wallet{
+			ID:          uuid.New(),
+			Balance:     decimal.Zero,
+			Currency:    req.GetCurrency(),
+			Status:      domain.WalletActive,
+			Shards:      req.Shards,
+			OwnerID:     req.OwnerID,
+			DisplayName: req.DisplayName,
+			Metadata:    metadata,
+			Labels:      labels,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		},
+		version: 1,
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.wallets[wallet.wallet.ID] = wallet
+	return wallet.snapshot(), nil
+}
+
+// ListWalletsByOwner возвращает все кошельки владельца в порядке создания
+func (r *MemoryRepository) ListWalletsByOwner(ctx context.Context, ownerID string) ([]domain.Wallet, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	wallets := []domain.Wallet{}
+	for _, wallet := range r.wallets {
+		if wallet.wallet.OwnerID == ownerID {
+			wallets = append(wallets, wallet.snapshot())
+		}
+	}
+	sort.Slice(wallets, func(i, j int) bool {
+		if !wallets[i].CreatedAt.Equal(wallets[j].CreatedAt) {
+			return wallets[i].CreatedAt.Before(wallets[j].CreatedAt)
+		}
+		return bytes.Compare(wallets[i].ID[:], wallets[j].ID[:]) < 0
+	})
+
+	return wallets, nil
+}
+
+// GetBalance возвращает баланс кошелька с учетом активных холдов
+func (r *MemoryRepository) GetBalance(ctx context.Context, walletID uuid.UUID) (domain.WalletBalance, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.WalletBalance{}, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	wallet, err := r.wallet(walletID)
+	if err != nil {
+		return domain.WalletBalance{}, err
+	}
+
+	return wallet.locked().WalletBalance(), nil
+}
+
+// SetWalletStatus переводит кошелек в состояние status
+func (r *MemoryRepository) SetWalletStatus(ctx context.Context, walletID uuid.UUID, status domain.WalletStatus, reason string) (domain.WalletState, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.WalletState{}, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	wallet, err := r.wallet(walletID)
+	if err != nil {
+		return domain.WalletState{}, err
+	}
+
+	locked := wallet.locked()
+	if err = locked.Status.CheckTransition(status, locked.Balance, locked.Held); err != nil {
+		return domain.WalletState{}, err
+	}
+
+	now := memoryNow()
+	wallet.wallet.Status = status
+	wallet.statusReason = reason
+	wallet.statusChangedAt = &now
+	wallet.touch(now)
+
+	return domain.WalletState{
+		WalletID:        walletID,
+		Status:          status,
+		Reason:          reason,
+		StatusChangedAt: wallet.statusChangedAt,
+	}, nil
+}
+
+// ListTransactions возвращает страницу истории операций кошелька в порядке убывания (created_at, transaction_id)
+func (r *MemoryRepository) ListTransactions(ctx context.Context, walletID uuid.UUID, filter domain.TransactionFilter) (domain.TransactionPage, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.TransactionPage{}, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	wallet, err := r.wallet(walletID)
+	if err != nil {
+		return domain.TransactionPage{}, err
+	}
+
+	minAmount, maxAmount, err := filter.ParseAmountRange()
+	if err != nil {
+		return domain.TransactionPage{}, app_errors.ErrInvalidAmount
+	}
+	cursor, err := filter.ParseCursor()
+	if err != nil {
+		return domain.TransactionPage{}, err
+	}
+
+	matches := func(transaction *domain.Transaction) bool {
+		if filter.OperationType != "" && transaction.OperationType != filter.OperationType {
+			return false
+		}
+		if minAmount != nil && transaction.Amount.Abs().LessThan(*minAmount) {
+			return false
+		}
+		if maxAmount != nil && transaction.Amount.Abs().GreaterThan(*maxAmount) {
+			return false
+		}
+		if !filter.From.IsZero() && transaction.CreatedAt.Before(filter.From) {
+			return false
+		}
+		if !filter.To.IsZero() && !transaction.CreatedAt.Before(filter.To) {
+			return false
+		}
+		if cursor != nil && !transactionBefore(transaction, cursor.CreatedAt, cursor.TransactionID) {
+			return false
+		}
+		return true
+	}
+
+	var selected []domain.Transaction
+	for _, transaction := range wallet.transactions {
+		if matches(transaction) {
+			selected = append(selected, *transaction)
+		}
+	}
+	sort.Slice(selected, func(i, j int) bool {
+		return transactionBefore(&selected[j], selected[i].CreatedAt, selected[i].ID)
+	})
+
+	limit := filter.GetLimit()
+	page := domain.TransactionPage{Transactions: selected}
+	if page.Transactions == nil {
+		page.Transactions = []domain.Transaction{}
+	}
+	if len(selected) > limit {
+		page.Transactions = selected[:limit]
+		last := page.Transactions[limit-1]
+		page.NextCursor = domain.TransactionCursor{
+			CreatedAt:     last.CreatedAt,
+			TransactionID: last.ID,
+		}.Encode()
+	}
+
+	return page, nil
+}
+
+// transactionBefore сообщает, что операция предшествует позиции (createdAt, transactionID) в порядке возрастания
+func transactionBefore(transaction *domain.Transaction, createdAt time.Time, transactionID uuid.UUID) bool {
+	if !transaction.CreatedAt.Equal(createdAt) {
+		return transaction.CreatedAt.Before(createdAt)
+	}
+	return bytes.Compare(transaction.ID[:], transactionID[:]) < 0
+}
+
+// VerifyLedger сверяет журнал
+func (r *MemoryRepository) VerifyLedger(ctx context.Context) (domain.LedgerReport, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.LedgerReport{}, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	report := domain.LedgerReport{
+		Totals:              []domain.CurrencyTotal{},
+		SystemAccounts:      []domain.AccountBalance{},
+		WalletDiscrepancies: []domain.WalletDiscrepancy{},
+	}
+
+	type accountCurrency struct {
+		accountID uuid.UUID
+		currency  string
+	}
+	totals := make(map[string]decimal.Decimal)
+	entryTotals := make(map[uuid.UUID]map[string]decimal.Decimal)
+	accounts := make(map[accountCurrency]decimal.Decimal)
+	walletTotals := make(map[uuid.UUID]decimal.Decimal)
+
+	for _, posting := range r.postings {
+		totals[posting.Currency] = totals[posting.Currency].Add(posting.Amount)
+
+		if entryTotals[posting.entryID] == nil {
+			entryTotals[posting.entryID] = make(map[string]decimal.Decimal)
+		}
+		entryTotals[posting.entryID][posting.Currency] = entryTotals[posting.entryID][posting.Currency].Add(posting.Amount)
+
+		if domain.IsSystemAccount(posting.AccountID) {
+			key := accountCurrency{posting.AccountID, posting.Currency}
+			accounts[key] = accounts[key].Add(posting.Amount)
+		} else {
+			walletTotals[posting.AccountID] = walletTotals[posting.AccountID].Add(posting.Amount)
+		}
+	}
+
+	totalsBalanced := true
+	for _, currency := range slices.Sorted(maps.Keys(totals)) {
+		totalsBalanced = totalsBalanced && totals[currency].IsZero()
+		report.Totals = append(report.Totals, domain.CurrencyTotal{Currency: currency, Total: totals[currency]})
+	}
+
+	for _, byCurrency := range entryTotals {
+		for _, total := range byCurrency {
+			if !total.IsZero() {
+				report.UnbalancedEntries++
+				break
+			}
+		}
+	}
+
+	for key, balance := range accounts {
+		report.SystemAccounts = append(report.SystemAccounts, domain.AccountBalance{
+			AccountID: key.accountID,
+			Code:      systemAccountCodes[key.accountID],
+			Currency:  key.currency,
+			Balance:   balance,
+		})
+	}
+	sort.Slice(report.SystemAccounts, func(i, j int) bool {
+		a, b := report.SystemAccounts[i], report.SystemAccounts[j]
+		if a.Code != b.Code {
+			return a.Code < b.Code
+		}
+		return a.Currency < b.Currency
+	})
+
+	for walletID, wallet := range r.wallets {
+		if !wallet.wallet.Balance.Equal(walletTotals[walletID]) {
+			report.WalletDiscrepancies = append(report.WalletDiscrepancies, domain.WalletDiscrepancy{
+				WalletID:         walletID,
+				ProjectedBalance: wallet.wallet.Balance,
+				LedgerBalance:    walletTotals[walletID],
+			})
+		}
+	}
+
+	report.Balanced = totalsBalanced && report.UnbalancedEntries == 0 && len(report.WalletDiscrepancies) == 0
+
+	return report, nil
+}
+
+// CreateAPIKey сохраняет ключ API с хешем hash
+func (r *MemoryRepository) CreateAPIKey(ctx context.Context, key domain.APIKey, hash []byte) (domain.APIKey, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.APIKey{}, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	hashKey := hex.EncodeToString(hash)
+	if _, ok := r.apiKeys[hashKey]; ok {
+		return domain.APIKey{}, app_errors.ErrConflict
+	}
+
+	key.Roles = slices.Clone(key.Roles)
+	key.CreatedAt = memoryNow()
+	r.apiKeys[hashKey] = key
+	return key, nil
+}
+
+// GetAPIKeyByHash ищет ключ API по хешу
+func (r *MemoryRepository) GetAPIKeyByHash(ctx context.Context, hash []byte) (domain.APIKey, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.APIKey{}, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	key, ok := r.apiKeys[hex.EncodeToString(hash)]
+	if !ok {
+		return domain.APIKey{}, app_errors.ErrNotFound
+	}
+	key.Roles = slices.Clone(key.Roles)
+	return key, nil
+}
+
+// GetWalletAccess возвращает владельца кошелька и роль, выданную на него клиенту subject
+func (r *MemoryRepository) GetWalletAccess(ctx context.Context, walletID uuid.UUID, subject string) (domain.WalletAccess, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.WalletAccess{}, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	wallet, err := r.wallet(walletID)
+	if err != nil {
+		return domain.WalletAccess{}, err
+	}
+
+	return domain.WalletAccess{OwnerID: wallet.wallet.OwnerID, Role: r.grants[walletID][subject].Role}, nil
+}
+
+// ListWalletGrants возвращает роли, выданные на кошелек, в порядке выдачи
+func (r *MemoryRepository) ListWalletGrants(ctx context.Context, walletID uuid.UUID) ([]domain.WalletGrant, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if _, err := r.wallet(walletID); err != nil {
+		return nil, err
+	}
+
+	grants := slices.Collect(maps.Values(r.grants[walletID]))
+	if grants == nil {
+		grants = []domain.WalletGrant{}
+	}
+	sort.Slice(grants, func(i, j int) bool {
+		if !grants[i].CreatedAt.Equal(grants[j].CreatedAt) {
+			return grants[i].CreatedAt.Before(grants[j].CreatedAt)
+		}
+		return grants[i].Subject < grants[j].Subject
+	})
+
+	return grants, nil
+}
+
+// PutWalletGrant выдает клиенту роль на кошелек; выданная ранее роль заменяется
+func (r *MemoryRepository) PutWalletGrant(ctx context.Context, grant domain.WalletGrant) (domain.WalletGrant, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.WalletGrant{}, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, err := r.wallet(grant.WalletID); err != nil {
+		return domain.WalletGrant{}, err
+	}
+
+	grants, ok := r.grants[grant.WalletID]
+	if !ok {
+		grants = make(map[string]domain.WalletGrant)
+		r.grants[grant.WalletID] = grants
+	}
+	// Как и в Postgres, при замене роли сохраняется время первой выдачи
+	if existing, ok := grants[grant.Subject]; ok {
+		grant.CreatedAt = existing.CreatedAt
+	} else {
+		grant.CreatedAt = memoryNow()
+	}
+	grants[grant.Subject] = grant
+
+	return grant, nil
+}
+
+// DeleteWalletGrant отзывает роль клиента на кошелек
+func (r *MemoryRepository) DeleteWalletGrant(ctx context.Context, walletID uuid.UUID, subject string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.grants[walletID][subject]; !ok {
+		return app_errors.ErrGrantNotFound
+	}
+	delete(r.grants[walletID], subject)
+	return nil
+}
+
+// GetWalletLimits возвращает уровень и собственные ограничения кошелька; Effective не заполняется
+func (r *MemoryRepository) GetWalletLimits(ctx context.Context, walletID uuid.UUID) (domain.WalletLimits, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.WalletLimits{}, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	wallet, err := r.wallet(walletID)
+	if err != nil {
+		return domain.WalletLimits{}, err
+	}
+
+	limits, ok := r.limits[walletID]
+	if !ok {
+		limits = domain.WalletLimits{WalletID: walletID}
+	}
+	limits.Currency = wallet.wallet.Currency
+	return limits, nil
+}
+
+// PutWalletLimits заменяет уровень и собственные ограничения кошелька
+func (r *MemoryRepository) PutWalletLimits(ctx context.Context, limits domain.WalletLimits) (domain.WalletLimits, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.WalletLimits{}, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	wallet, err := r.wallet(limits.WalletID)
+	if err != nil {
+		return domain.WalletLimits{}, err
+	}
+
+	now := memoryNow()
+	limits.Currency = wallet.wallet.Currency
+	limits.UpdatedAt = &now
+	limits.Effective = domain.TransactionLimits{}
+	r.limits[limits.WalletID] = limits
+
+	return limits, nil
+}
+
+// nonceSweepInterval — как часто хранилище в памяти удаляет истекшие nonce
+const nonceSweepInterval = time.Minute
+
+// RememberNonce запоминает nonce до expiresAt и возвращает false, если он уже использован и еще не истек
+func (r *MemoryRepository) RememberNonce(ctx context.Context, nonce string, expiresAt time.Time) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(r.noncesSwept) >= nonceSweepInterval {
+		for stored, storedUntil := range r.nonces {
+			if storedUntil.Before(now) {
+				delete(r.nonces, stored)
+			}
+		}
+		r.noncesSwept = now
+	}
+
+	if storedUntil, ok := r.nonces[nonce]; ok && !storedUntil.Before(now) {
+		return false, nil
+	}
+	r.nonces[nonce] = expiresAt
+	return true, nil
+}
+
+// wallet возвращает кошелек; вызывается под блокировкой r.mu
+func (r *MemoryRepository) wallet(walletID uuid.UUID) (*memoryWallet, error) {
+	wallet, ok := r.wallets[walletID]
+	if !ok {
+		return nil, app_errors.ErrWalletNotFound
+	}
+	return wallet, nil
+}
+
+// postEntry записывает сбалансированную запись журнала и обновляет балансы кошельков.
+// Запись проверяется до любых изменений, поэтому при ошибке состояние хранилища не меняется.
+func (r *MemoryRepository) postEntry(entry *domain.JournalEntry, now time.Time) error {
+	if err := entry.Validate(); err != nil {
+		return err
+	}
+
+	entry.CreatedAt = now
+	r.entries[entry.ID] = entry.Postings
+	for _, posting := range entry.Postings {
+		r.postings = append(r.postings, memoryPosting{entryID: entry.ID, Posting: posting})
+		if domain.IsSystemAccount(posting.AccountID) {
+			continue
+		}
+		wallet := r.wallets[posting.AccountID]
+		wallet.wallet.Balance = wallet.wallet.Balance.Add(posting.Amount)
+		wallet.touch(now)
+	}
+
+	return nil
+}
+
+// addTransaction записывает операцию в историю кошелька
+func (r *MemoryRepository) addTransaction(transaction *domain.Transaction, now time.Time) {
+	transaction.CreatedAt = now
+	r.transactions[transaction.ID] = transaction
+	wallet := r.wallets[transaction.WalletID]
+	wallet.transactions = append(wallet.transactions, transaction)
+}
+
+// findIdempotentResponse ищет сохраненный ответ по ключу идемпотентности, как одноименная функция для Postgres
+func (r *MemoryRepository) findIdempotentResponse(subject, key, fingerprint string, response any) (bool, error) {
+	stored, ok := r.idempotency[idempotencyKey{subject: subject, key: key}]
+	if !ok {
+		return false, nil
+	}
+	if stored.fingerprint != fingerprint {
+		return false, app_errors.ErrIdempotencyKeyReused
+	}
+	return true, json.Unmarshal(stored.response, response)
+}
+
+// saveIdempotentResponse сохраняет ответ на запрос с ключом идемпотентности клиента subject
+func (r *MemoryRepository) saveIdempotentResponse(subject, key, fingerprint string, response any) error {
+	encoded, err := json.Marshal(response)
+	if err != nil {
+		return err
+	}
+	r.idempotency[idempotencyKey{subject: subject, key: key}] = memoryResponse{fingerprint: fingerprint, response: encoded}
+	return nil
+}
+
+// locked возвращает состояние кошелька в том виде, в каком его видят проверки WalletRepository
+func (w *memoryWallet) locked() lockedWallet {
+	return lockedWallet{
+		Balance:    w.wallet.Balance,
+		Held:       w.held,
+		Currency:   w.wallet.Currency,
+		Status:     w.wallet.Status,
+		ShardCount: w.wallet.Shards,
+		Version:    w.version,
+	}
+}
+
+// checkLimits проверяет операцию на сумму amount по ограничениям limits кошелька, как одноименная функция для Postgres;
+// held — резерв активных холдов, который учитывается в списаниях за день и месяц
+func (w *memoryWallet) checkLimits(limits domain.TransactionLimits, held, amount decimal.Decimal) error {
+	if limits.IsZero() {
+		return nil
+	}
+
+	var usage domain.WithdrawalUsage
+	if amount.IsNegative() && limits.LimitsWithdrawals() {
+		usage = w.withdrawalUsage(memoryNow()).Add(held.Neg())
+	}
+
+	return limits.Check(amount, w.wallet.Balance.Add(amount), usage)
+}
+
+// withdrawalUsage возвращает сумму списаний кошелька за календарные день и месяц, в которые попадает now
+func (w *memoryWallet) withdrawalUsage(now time.Time) domain.WithdrawalUsage {
+	dayStart, monthStart := domain.WithdrawalPeriods(now)
+
+	var usage domain.WithdrawalUsage
+	// Операции хранятся в порядке создания, поэтому просмотр идет с конца до начала месяца
+	for i := len(w.transactions) - 1; i >= 0; i-- {
+		transaction := w.transactions[i]
+		if transaction.CreatedAt.Before(monthStart) {
+			break
+		}
+		if !transaction.Amount.IsNegative() || !slices.Contains(domain.WithdrawalTypes, transaction.OperationType) {
+			continue
+		}
+		usage.Monthly = usage.Monthly.Sub(transaction.Amount)
+		if !transaction.CreatedAt.Before(dayStart) {
+			usage.Daily = usage.Daily.Sub(transaction.Amount)
+		}
+	}
+
+	return usage
+}
+
+// touch отмечает изменение кошелька: увеличивает версию и время обновления
+func (w *memoryWallet) touch(now time.Time) {
+	w.version++
+	w.wallet.UpdatedAt = now
+}
+
+// snapshot возвращает копию кошелька, не разделяющую с хранилищем метаданные и метки
+func (w *memoryWallet) snapshot() domain.Wallet {
+	wallet := w.wallet
+	wallet.Metadata = maps.Clone(w.wallet.Metadata)
+	wallet.Labels = slices.Clone(w.wallet.Labels)
+	return wallet
+}
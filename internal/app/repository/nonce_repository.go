@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// RememberNonce запоминает nonce до expiresAt и возвращает false, если он уже использован и еще не истек.
+// Попутно удаляются истекшие nonce, поэтому таблица не растет дольше окна подписи.
+func (r *WalletRepository) RememberNonce(ctx context.Context, nonce string, expiresAt time.Time) (_ bool, err error) {
+	defer translateError(&err)
+
+	// Истекшая запись с тем же nonce не удаляется в CTE, а перезаписывается вставкой:
+	// одна команда не может и удалить, и обновить одну строку
+	var stored string
+	err = r.db.QueryRow(ctx,
+		`WITH purged AS (DELETE FROM request_nonces WHERE expires_at < now() AND nonce <> $1)
+		INSERT INTO request_nonces(nonce, expires_at) VALUES($1, $2)
+		ON CONFLICT (nonce) DO UPDATE SET expires_at = EXCLUDED.expires_at WHERE request_nonces.expires_at < now()
+		RETURNING nonce`,
+		nonce, expiresAt,
+	).Scan(&stored)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
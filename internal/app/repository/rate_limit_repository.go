@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"wallet-app/internal/app/domain"
+)
+
+// pruneBucketsBatch — сколько наполнившихся корзин удаляется за один вызов TakeToken
+const pruneBucketsBatch = 100
+
+// TakeToken берет токен из корзины key. Строка корзины блокируется до конца транзакции, а время
+// берется из базы, поэтому корзина согласована между экземплярами приложения с разными часами.
+// Попутно удаляются корзины, которые успели наполниться до емкости: такая корзина не отличается
+// от новой, поэтому таблица не растет с числом клиентов и кошельков, которые давно не обращались.
+func (r *WalletRepository) TakeToken(ctx context.Context, key string, limit domain.RateLimit) (_ domain.RateDecision, err error) {
+	defer translateError(&err)
+
+	return runTx(ctx, r, pgx.ReadCommitted, func(tx pgx.Tx) (domain.RateDecision, error) {
+		// Вставка с ON CONFLICT DO UPDATE блокирует и новую, и существующую строку одним запросом,
+		// поэтому корзину не удалит очистка другой транзакции между созданием и чтением
+		var bucket domain.TokenBucket
+		var updatedAt *time.Time
+		var now time.Time
+		err := tx.QueryRow(ctx,
+			`INSERT INTO rate_limit_buckets(bucket_key) VALUES($1)
+			 ON CONFLICT (bucket_key) DO UPDATE SET bucket_key = EXCLUDED.bucket_key
+			 RETURNING tokens, updated_at, now()`,
+			key,
+		).Scan(&bucket.Tokens, &updatedAt, &now)
+		if err != nil {
+			return domain.RateDecision{}, err
+		}
+		if updatedAt != nil {
+			bucket.UpdatedAt = *updatedAt
+		}
+
+		decision := limit.Take(&bucket, now)
+		fullAt := bucket.UpdatedAt.Add(limit.RefillTime(bucket))
+
+		_, err = tx.Exec(ctx,
+			`UPDATE rate_limit_buckets SET tokens = $2, updated_at = $3, full_at = $4 WHERE bucket_key = $1`,
+			key, bucket.Tokens, bucket.UpdatedAt, fullAt,
+		)
+		if err != nil {
+			return domain.RateDecision{}, err
+		}
+
+		// Корзины, занятые другими транзакциями, пропускаются: их удалит следующий вызов
+		_, err = tx.Exec(ctx,
+			`DELETE FROM rate_limit_buckets WHERE bucket_key IN (
+				SELECT bucket_key FROM rate_limit_buckets
+				WHERE full_at < now() AND bucket_key <> $1
+				LIMIT $2 FOR UPDATE SKIP LOCKED
+			)`,
+			key, pruneBucketsBatch,
+		)
+		if err != nil {
+			return domain.RateDecision{}, err
+		}
+
+		return decision, nil
+	})
+}
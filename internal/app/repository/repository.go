@@ -1,13 +1,98 @@
 package repository
 
 import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shopspring/decimal"
+
+	"wallet-app/internal/app/domain"
+)
+
+// Wallets — хранилище кошельков, от которого зависят сервисы. Реализации: WalletRepository (Postgres)
+// и MemoryRepository (в памяти процесса) с одинаковой семантикой операций.
+type Wallets interface {
+	CreateWallet(ctx context.Context, req domain.CreateWalletRequest) (domain.Wallet, error)
+	ListWalletsByOwner(ctx context.Context, ownerID string) ([]domain.Wallet, error)
+	GetBalance(ctx context.Context, walletID uuid.UUID) (domain.WalletBalance, error)
+	SetWalletStatus(ctx context.Context, walletID uuid.UUID, status domain.WalletStatus, reason string) (domain.WalletState, error)
+
+	UpdateBalance(ctx context.Context, update domain.BalanceUpdate) (domain.Transaction, error)
+	UpdateBalanceBatch(ctx context.Context, walletID uuid.UUID, updates []domain.BalanceUpdate) ([]domain.BalanceUpdateResult, error)
+	ApplyCorrection(ctx context.Context, update domain.BalanceUpdate) (domain.Transaction, error)
+	Transfer(ctx context.Context, update domain.TransferUpdate) (domain.TransferResult, error)
+	ListTransactions(ctx context.Context, walletID uuid.UUID, filter domain.TransactionFilter) (domain.TransactionPage, error)
+
+	CreateHold(ctx context.Context, walletID uuid.UUID, amount decimal.Decimal, expiresAt time.Time, limits domain.TransactionLimits) (domain.Hold, error)
+	CaptureHold(ctx context.Context, walletID, holdID uuid.UUID, amount *decimal.Decimal, limits domain.TransactionLimits) (domain.HoldCaptureResult, error)
+	ReleaseHold(ctx context.Context, walletID, holdID uuid.UUID) (domain.Hold, error)
+	ExpireHolds(ctx context.Context, limit int) (int, error)
+
+	CreateQuote(ctx context.Context, quote domain.FXQuote) (domain.FXQuote, error)
+	GetQuote(ctx context.Context, quoteID uuid.UUID) (domain.FXQuote, error)
+
+	VerifyLedger(ctx context.Context) (domain.LedgerReport, error)
+}
+
+// APIKeys — хранилище ключей API. Ключи ищутся по хешу, сами ключи не хранятся.
+type APIKeys interface {
+	CreateAPIKey(ctx context.Context, key domain.APIKey, hash []byte) (domain.APIKey, error)
+	GetAPIKeyByHash(ctx context.Context, hash []byte) (domain.APIKey, error)
+}
+
+// WalletGrants — роли клиентов на кошельки, по которым проверяется доступ к ним
+type WalletGrants interface {
+	GetWalletAccess(ctx context.Context, walletID uuid.UUID, subject string) (domain.WalletAccess, error)
+	ListWalletGrants(ctx context.Context, walletID uuid.UUID) ([]domain.WalletGrant, error)
+	PutWalletGrant(ctx context.Context, grant domain.WalletGrant) (domain.WalletGrant, error)
+	DeleteWalletGrant(ctx context.Context, walletID uuid.UUID, subject string) error
+}
+
+// WalletLimits — уровни и собственные ограничения операций кошельков. Ограничения проверяются
+// при изменении баланса (Wallets.UpdateBalance, Wallets.Transfer) по полям Limits, которые заполняет сервис.
+type WalletLimits interface {
+	GetWalletLimits(ctx context.Context, walletID uuid.UUID) (domain.WalletLimits, error)
+	PutWalletLimits(ctx context.Context, limits domain.WalletLimits) (domain.WalletLimits, error)
+}
+
+// Nonces — использованные nonce подписанных запросов. Хранилище общее для всех экземпляров приложения,
+// поэтому повтор запроса отклоняется, на какой бы экземпляр он ни пришел.
+type Nonces interface {
+	RememberNonce(ctx context.Context, nonce string, expiresAt time.Time) (bool, error)
+}
+
+// Storage — все хранилища приложения; обе реализации хранят кошельки, ключи API, роли, ограничения и nonce
+// в одном месте
+type Storage interface {
+	Wallets
+	APIKeys
+	WalletGrants
+	WalletLimits
+	Nonces
+}
+
+// RateLimits — корзины токенов ограничения частоты запросов. Реализации: WalletRepository — корзины
+// в Postgres, общие для всех экземпляров приложения, и ratelimit.MemoryLimiter — в памяти экземпляра.
+type RateLimits interface {
+	TakeToken(ctx context.Context, key string, limit domain.RateLimit) (domain.RateDecision, error)
+}
+
+var (
+	_ Storage    = (*WalletRepository)(nil)
+	_ Storage    = (*MemoryRepository)(nil)
+	_ RateLimits = (*WalletRepository)(nil)
 )
 
 type WalletRepository struct {
-	db *pgxpool.Pool
+	db    *pgxpool.Pool
+	retry RetryPolicy
 }
 
-func NewRepository(db *pgxpool.Pool) *WalletRepository {
-	return &WalletRepository{db: db}
+func NewRepository(db *pgxpool.Pool, retry RetryPolicy) *WalletRepository {
+	if retry.MaxAttempts < 1 {
+		retry.MaxAttempts = 1
+	}
+	return &WalletRepository{db: db, retry: retry}
 }
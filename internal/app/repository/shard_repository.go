@@ -0,0 +1,223 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+
+	"wallet-app/internal/app/app_errors"
+	"wallet-app/internal/app/domain"
+)
+
+// Шардированный кошелек хранит баланс в основной строке wallets и в shard_count строках wallet_balance_shards.
+// Зачисления попадают на случайный шард и берут блокировку строки кошелька только в режиме FOR SHARE,
+// поэтому не ждут друг друга. Списания работают только с основным балансом под FOR UPDATE, как у обычного
+// кошелька; если его не хватает, шарды сводятся в основной баланс (drainShards). Шарды только пополняются
+// и обнуляются при сведении, так что ни основной баланс, ни шарды не становятся отрицательными.
+
+// shardTotalSQL — сумма несведенных зачислений кошелька для запросов FROM wallets
+const shardTotalSQL = "COALESCE((SELECT SUM(s.balance) FROM wallet_balance_shards s WHERE s.wallet_id = wallets.wallet_id), 0)"
+
+// queryRower — пул соединений или транзакция
+type queryRower interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// shardTarget направляет проводку по кошельку WalletID на его шард ShardID вместо строки wallets
+type shardTarget struct {
+	WalletID uuid.UUID
+	ShardID  int
+}
+
+// walletShardCount возвращает число шардов кошелька. Оно задается при создании и не меняется,
+// поэтому его можно читать без блокировки
+func walletShardCount(ctx context.Context, q queryRower, walletID uuid.UUID) (int, error) {
+	var shardCount int
+	err := q.QueryRow(ctx, "SELECT shard_count FROM wallets WHERE wallet_id = $1", walletID).Scan(&shardCount)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, app_errors.ErrWalletNotFound
+	}
+	return shardCount, err
+}
+
+// lockWalletForCredit блокирует кошелек для зачисления. Шардированный кошелек блокируется в режиме FOR SHARE:
+// параллельные зачисления не ждут друг друга, а списания и смена состояния, которые берут FOR UPDATE,
+// дожидаются их завершения. Баланс шардированного кошелька при этом не читается.
+func lockWalletForCredit(ctx context.Context, tx pgx.Tx, walletID uuid.UUID) (lockedWallet, error) {
+	shardCount, err := walletShardCount(ctx, tx, walletID)
+	if err != nil {
+		return lockedWallet{}, err
+	}
+	if shardCount == 0 {
+		return lockWallet(ctx, tx, walletID)
+	}
+
+	var currency, status string
+	err = tx.QueryRow(ctx, "SELECT currency, status FROM wallets WHERE wallet_id = $1 FOR SHARE", walletID).
+		Scan(&currency, &status)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return lockedWallet{}, app_errors.ErrWalletNotFound
+	}
+	if err != nil {
+		return lockedWallet{}, err
+	}
+
+	return lockedWallet{Currency: currency, Status: domain.WalletStatus(status), ShardCount: shardCount}, nil
+}
+
+// randomShard выбирает шард для зачисления на кошелек
+func randomShard(walletID uuid.UUID, shardCount int) *shardTarget {
+	return &shardTarget{WalletID: walletID, ShardID: rand.IntN(shardCount)}
+}
+
+// creditShardTx зачисляет средства на шард кошелька. Выполняется в транзакции READ COMMITTED: в SERIALIZABLE
+// параллельные зачисления конфликтовали бы на общих страницах индексов. Одновременные запросы с одним
+// ключом идемпотентности упорядочиваются advisory-блокировкой: второй дождется первого и вернет его ответ.
+func creditShardTx(ctx context.Context, tx pgx.Tx, update domain.BalanceUpdate) (_ domain.Transaction, err error) {
+	if update.IdempotencyKey != "" {
+		if _, err = tx.Exec(ctx, "SELECT pg_advisory_xact_lock(hashtextextended($1 || '/' || $2, 0))",
+			update.IdempotencySubject, update.IdempotencyKey); err != nil {
+			return domain.Transaction{}, err
+		}
+
+		var stored domain.Transaction
+		found, err := findIdempotentResponse(ctx, tx, update.IdempotencySubject, update.IdempotencyKey, update.Fingerprint, &stored)
+		if err != nil {
+			return domain.Transaction{}, err
+		}
+		if found {
+			return stored, nil
+		}
+	}
+
+	wallet, err := lockWalletForCredit(ctx, tx, update.WalletID)
+	if err != nil {
+		return domain.Transaction{}, err
+	}
+	if err = wallet.CheckVersion(update.ExpectedVersion); err != nil {
+		return domain.Transaction{}, err
+	}
+	if err = wallet.Status.CheckMovement(update.Amount); err != nil {
+		return domain.Transaction{}, err
+	}
+	if err = wallet.CheckAmount(update.Currency, update.Amount); err != nil {
+		return domain.Transaction{}, err
+	}
+	// Сюда попадают только зачисления без потолка баланса: проверять остается сумму операции
+	if err = update.Limits.Check(update.Amount, update.Amount, domain.WithdrawalUsage{}); err != nil {
+		return domain.Transaction{}, err
+	}
+
+	entry := domain.NewExternalEntry(update.OperationType, update.WalletID, wallet.Currency, update.Amount)
+	if err = postJournalEntryWithShard(ctx, tx, &entry, randomShard(update.WalletID, wallet.ShardCount)); err != nil {
+		return domain.Transaction{}, err
+	}
+
+	transaction := domain.Transaction{
+		ID:            uuid.New(),
+		WalletID:      update.WalletID,
+		OperationType: update.OperationType,
+		Amount:        update.Amount,
+		Currency:      wallet.Currency,
+		EntryID:       &entry.ID,
+	}
+	if err = insertTransaction(ctx, tx, &transaction); err != nil {
+		return domain.Transaction{}, err
+	}
+
+	if update.IdempotencyKey != "" {
+		err = saveIdempotentResponse(ctx, tx, update.IdempotencySubject, update.IdempotencyKey, update.Fingerprint, transaction)
+		if err != nil {
+			return domain.Transaction{}, err
+		}
+	}
+
+	return transaction, nil
+}
+
+// drainShards сводит зачисления с шардов в основной баланс кошелька и сообщает, было ли что сводить.
+// Выполняется в отдельной транзакции READ COMMITTED: после блокировки строки кошелька FOR UPDATE
+// незавершенных зачислений нет, а каждый следующий запрос видит все зафиксированные. Снимок
+// SERIALIZABLE делается до ожидания блокировки и таких зачислений бы не увидел.
+func (r *WalletRepository) drainShards(ctx context.Context, walletID uuid.UUID) (bool, error) {
+	return runTx(ctx, r, pgx.ReadCommitted, func(tx pgx.Tx) (bool, error) {
+		var shardCount int
+		err := tx.QueryRow(ctx, "SELECT shard_count FROM wallets WHERE wallet_id = $1 FOR UPDATE", walletID).
+			Scan(&shardCount)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, app_errors.ErrWalletNotFound
+		}
+		if err != nil || shardCount == 0 {
+			return false, err
+		}
+
+		var totalStr string
+		err = tx.QueryRow(ctx, "SELECT COALESCE(SUM(balance), 0) FROM wallet_balance_shards WHERE wallet_id = $1", walletID).
+			Scan(&totalStr)
+		if err != nil {
+			return false, err
+		}
+		total, err := decimal.NewFromString(totalStr)
+		if err != nil || total.IsZero() {
+			return false, err
+		}
+
+		batch := &pgx.Batch{}
+		batch.Queue("UPDATE wallet_balance_shards SET balance = 0 WHERE wallet_id = $1 AND balance <> 0", walletID)
+		batch.Queue("UPDATE wallets SET balance = balance + $1 WHERE wallet_id = $2", total.String(), walletID)
+		if err = tx.SendBatch(ctx, batch).Close(); err != nil {
+			return false, err
+		}
+
+		return true, nil
+	})
+}
+
+// retryAfterDrain выполняет операцию op со списанием с кошелька walletID. Если средств не хватило,
+// а на шардах кошелька есть несведенные зачисления, они сводятся и операция повторяется один раз.
+func retryAfterDrain[T any](ctx context.Context, r *WalletRepository, walletID uuid.UUID, op func() (T, error)) (T, error) {
+	result, err := op()
+	if !errors.Is(err, app_errors.ErrInsufficientFunds) {
+		return result, err
+	}
+
+	drained, drainErr := r.drainShards(ctx, walletID)
+	if drainErr != nil {
+		return result, drainErr
+	}
+	if !drained {
+		return result, err
+	}
+
+	return op()
+}
+
+// lockShardTotal блокирует шарды кошелька и возвращает сумму несведенных зачислений.
+// Если шард изменило зачисление, зафиксированное после снимка транзакции, Postgres вернет ошибку
+// сериализации и транзакция будет повторена, поэтому сумма не бывает устаревшей.
+func lockShardTotal(ctx context.Context, tx pgx.Tx, walletID uuid.UUID) (decimal.Decimal, error) {
+	rows, err := tx.Query(ctx, "SELECT balance FROM wallet_balance_shards WHERE wallet_id = $1 FOR UPDATE", walletID)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	defer rows.Close()
+
+	total := decimal.Zero
+	for rows.Next() {
+		var balanceStr string
+		if err = rows.Scan(&balanceStr); err != nil {
+			return decimal.Zero, err
+		}
+		balance, err := decimal.NewFromString(balanceStr)
+		if err != nil {
+			return decimal.Zero, err
+		}
+		total = total.Add(balance)
+	}
+
+	return total, rows.Err()
+}
@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"wallet-app/internal/app/app_errors"
+	"wallet-app/internal/app/domain"
+)
+
+// ListTransactions возвращает страницу истории операций кошелька.
+// Записи упорядочены по убыванию (created_at, transaction_id), что дает стабильную курсорную пагинацию.
+func (r *WalletRepository) ListTransactions(ctx context.Context, walletID uuid.UUID, filter domain.TransactionFilter) (_ domain.TransactionPage, err error) {
+	defer translateError(&err)
+
+	var exists bool
+	err = r.db.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM wallets WHERE wallet_id=$1)", walletID).Scan(&exists)
+	if err != nil {
+		return domain.TransactionPage{}, err
+	}
+	if !exists {
+		return domain.TransactionPage{}, app_errors.ErrWalletNotFound
+	}
+
+	// Собираем условия выборки по заданным фильтрам
+	conditions := []string{"wallet_id = $1"}
+	args := []any{walletID}
+	addCondition := func(format string, value any) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(format, len(args)))
+	}
+
+	if filter.OperationType != "" {
+		addCondition("operation_type = $%d", string(filter.OperationType))
+	}
+
+	minAmount, maxAmount, err := filter.ParseAmountRange()
+	if err != nil {
+		return domain.TransactionPage{}, app_errors.ErrInvalidAmount
+	}
+	if minAmount != nil {
+		addCondition("ABS(amount) >= $%d", minAmount.String())
+	}
+	if maxAmount != nil {
+		addCondition("ABS(amount) <= $%d", maxAmount.String())
+	}
+
+	if !filter.From.IsZero() {
+		addCondition("created_at >= $%d", filter.From)
+	}
+	if !filter.To.IsZero() {
+		addCondition("created_at < $%d", filter.To)
+	}
+
+	cursor, err := filter.ParseCursor()
+	if err != nil {
+		return domain.TransactionPage{}, err
+	}
+	if cursor != nil {
+		args = append(args, cursor.CreatedAt, cursor.TransactionID)
+		conditions = append(conditions,
+			fmt.Sprintf("(created_at, transaction_id) < ($%d, $%d)", len(args)-1, len(args)))
+	}
+
+	// Запрашиваем на одну запись больше, чтобы понять, есть ли следующая страница
+	limit := filter.GetLimit()
+	args = append(args, limit+1)
+
+	query := fmt.Sprintf(
+		`SELECT transaction_id, wallet_id, operation_type, amount, currency, balance_after, created_at,
+			transfer_id, counterparty_wallet_id, entry_id, original_transaction_id,
+			exchange_rate, counter_amount, counter_currency
+		FROM wallet_transactions
+		WHERE %s
+		ORDER BY created_at DESC, transaction_id DESC
+		LIMIT $%d`,
+		strings.Join(conditions, " AND "), len(args),
+	)
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return domain.TransactionPage{}, err
+	}
+	defer rows.Close()
+
+	transactions := make([]domain.Transaction, 0, limit)
+	for rows.Next() {
+		var transaction domain.Transaction
+		var operationType, amountStr string
+		var balanceAfterStr, exchangeRateStr, counterAmountStr, counterCurrency *string
+
+		err = rows.Scan(&transaction.ID, &transaction.WalletID, &operationType, &amountStr, &transaction.Currency, &balanceAfterStr, &transaction.CreatedAt,
+			&transaction.TransferID, &transaction.CounterpartyWalletID, &transaction.EntryID, &transaction.OriginalTransactionID,
+			&exchangeRateStr, &counterAmountStr, &counterCurrency)
+		if err != nil {
+			return domain.TransactionPage{}, err
+		}
+
+		transaction.OperationType = domain.OperationType(operationType)
+		if transaction.Amount, err = decimal.NewFromString(amountStr); err != nil {
+			return domain.TransactionPage{}, err
+		}
+		if transaction.BalanceAfter, err = parseNullableDecimal(balanceAfterStr); err != nil {
+			return domain.TransactionPage{}, err
+		}
+		if transaction.ExchangeRate, err = parseNullableDecimal(exchangeRateStr); err != nil {
+			return domain.TransactionPage{}, err
+		}
+		if transaction.CounterAmount, err = parseNullableDecimal(counterAmountStr); err != nil {
+			return domain.TransactionPage{}, err
+		}
+		if counterCurrency != nil {
+			transaction.CounterCurrency = *counterCurrency
+		}
+
+		transactions = append(transactions, transaction)
+	}
+	if err = rows.Err(); err != nil {
+		return domain.TransactionPage{}, err
+	}
+
+	page := domain.TransactionPage{Transactions: transactions}
+	if len(transactions) > limit {
+		page.Transactions = transactions[:limit]
+		last := page.Transactions[limit-1]
+		page.NextCursor = domain.TransactionCursor{
+			CreatedAt:     last.CreatedAt,
+			TransactionID: last.ID,
+		}.Encode()
+	}
+
+	return page, nil
+}
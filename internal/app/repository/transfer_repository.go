@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"wallet-app/internal/app/app_errors"
+	"wallet-app/internal/app/domain"
+)
+
+// Transfer списывает средства с одного кошелька и зачисляет на другой в одной транзакции.
+// Перевод между кошельками в разных валютах выполняется только по котировке (update.Conversion).
+// Строки кошельков блокируются в порядке возрастания UUID, чтобы встречные переводы не приводили к взаимоблокировке.
+func (r *WalletRepository) Transfer(ctx context.Context, update domain.TransferUpdate) (_ domain.TransferResult, err error) {
+	defer translateError(&err)
+
+	return retryAfterDrain(ctx, r, update.FromWalletID, func() (domain.TransferResult, error) {
+		return runTx(ctx, r, pgx.Serializable, func(tx pgx.Tx) (domain.TransferResult, error) {
+			return transferTx(ctx, tx, update)
+		})
+	})
+}
+
+// transferTx выполняет Transfer в транзакции tx
+func transferTx(ctx context.Context, tx pgx.Tx, update domain.TransferUpdate) (_ domain.TransferResult, err error) {
+	// Повтор запроса с уже использованным ключом отдает сохраненный ответ
+	if update.IdempotencyKey != "" {
+		var stored domain.TransferResult
+		found, err := findIdempotentResponse(ctx, tx, update.IdempotencySubject, update.IdempotencyKey, update.Fingerprint, &stored)
+		if err != nil {
+			return domain.TransferResult{}, err
+		}
+		if found {
+			return stored, nil
+		}
+	}
+
+	// Блокируем оба кошелька в детерминированном порядке; шардированный получатель блокируется только FOR SHARE,
+	// если его баланс не ограничен: проверка потолка баланса должна видеть все зачисления
+	lockOrder := []uuid.UUID{update.FromWalletID, update.ToWalletID}
+	if bytes.Compare(lockOrder[0][:], lockOrder[1][:]) > 0 {
+		lockOrder[0], lockOrder[1] = lockOrder[1], lockOrder[0]
+	}
+
+	wallets := make(map[uuid.UUID]lockedWallet, len(lockOrder))
+	for _, walletID := range lockOrder {
+		lock := lockWallet
+		if walletID == update.ToWalletID && update.ToLimits.MaxBalance == nil {
+			lock = lockWalletForCredit
+		}
+		wallet, err := lock(ctx, tx, walletID)
+		if err != nil {
+			return domain.TransferResult{}, err
+		}
+		wallets[walletID] = wallet
+	}
+
+	fromWallet, toWallet := wallets[update.FromWalletID], wallets[update.ToWalletID]
+	if err = fromWallet.Status.CheckMovement(update.Amount.Neg()); err != nil {
+		return domain.TransferResult{}, err
+	}
+	if err = fromWallet.CheckAmount(update.Currency, update.Amount); err != nil {
+		return domain.TransferResult{}, err
+	}
+
+	// Без котировки перевод возможен только между кошельками одной валюты;
+	// с котировкой получатель получает сумму, пересчитанную по зафиксированному курсу
+	creditAmount := update.Amount
+	if update.Conversion != nil {
+		creditAmount = update.Conversion.ConvertedAmount
+	} else if fromWallet.Currency != toWallet.Currency {
+		return domain.TransferResult{}, app_errors.ErrCurrencyMismatch
+	}
+	if err = toWallet.Status.CheckMovement(creditAmount); err != nil {
+		return domain.TransferResult{}, err
+	}
+
+	// Переводить можно только незарезервированные средства
+	if fromWallet.Available().LessThan(update.Amount) {
+		return domain.TransferResult{}, app_errors.ErrInsufficientFunds
+	}
+	if err = checkLimits(ctx, tx, update.FromWalletID, fromWallet, update.FromLimits, update.Amount.Neg()); err != nil {
+		return domain.TransferResult{}, err
+	}
+	if err = checkLimits(ctx, tx, update.ToWalletID, toWallet, update.ToLimits, creditAmount); err != nil {
+		return domain.TransferResult{}, err
+	}
+	fromBalance := fromWallet.Balance.Sub(update.Amount)
+	toBalance := toWallet.Balance.Add(creditAmount)
+
+	result := domain.TransferResult{
+		ID:           uuid.New(),
+		FromWalletID: update.FromWalletID,
+		ToWalletID:   update.ToWalletID,
+		Amount:       update.Amount,
+		Currency:     fromWallet.Currency,
+		Conversion:   update.Conversion,
+	}
+
+	// Проводим перевод по журналу; проекции балансов обоих кошельков обновляются в той же транзакции
+	entry := domain.NewTransferEntry(update.FromWalletID, update.ToWalletID, fromWallet.Currency, update.Amount)
+	if update.Conversion != nil {
+		err = claimQuote(ctx, tx, *update.Conversion, result.ID, fromWallet.Currency, toWallet.Currency)
+		if err != nil {
+			return domain.TransferResult{}, err
+		}
+		entry = domain.NewConversionEntry(update.FromWalletID, update.ToWalletID,
+			fromWallet.Currency, update.Amount, toWallet.Currency, creditAmount)
+	}
+	var shard *shardTarget
+	if toWallet.ShardCount > 0 {
+		shard = randomShard(update.ToWalletID, toWallet.ShardCount)
+	}
+	if err = postJournalEntryWithShard(ctx, tx, &entry, shard); err != nil {
+		return domain.TransferResult{}, err
+	}
+
+	// Записываем обе части перевода в историю, связывая их общим идентификатором
+	result.Debit = domain.Transaction{
+		ID:                   uuid.New(),
+		WalletID:             update.FromWalletID,
+		OperationType:        domain.Transfer,
+		Amount:               update.Amount.Neg(),
+		Currency:             fromWallet.Currency,
+		BalanceAfter:         fromWallet.BalanceAfter(fromBalance),
+		TransferID:           &result.ID,
+		CounterpartyWalletID: &result.ToWalletID,
+		EntryID:              &entry.ID,
+	}
+	result.Credit = domain.Transaction{
+		ID:                   uuid.New(),
+		WalletID:             update.ToWalletID,
+		OperationType:        domain.Transfer,
+		Amount:               creditAmount,
+		Currency:             toWallet.Currency,
+		BalanceAfter:         toWallet.BalanceAfter(toBalance),
+		TransferID:           &result.ID,
+		CounterpartyWalletID: &result.FromWalletID,
+		EntryID:              &entry.ID,
+	}
+	if update.Conversion != nil {
+		rate := update.Conversion.Rate
+		result.Debit.ExchangeRate, result.Debit.CounterAmount = &rate, &creditAmount
+		result.Debit.CounterCurrency = toWallet.Currency
+		result.Credit.ExchangeRate, result.Credit.CounterAmount = &rate, &result.Amount
+		result.Credit.CounterCurrency = fromWallet.Currency
+	}
+
+	if err = insertTransaction(ctx, tx, &result.Debit); err != nil {
+		return domain.TransferResult{}, err
+	}
+	if err = insertTransaction(ctx, tx, &result.Credit); err != nil {
+		return domain.TransferResult{}, err
+	}
+
+	if update.IdempotencyKey != "" {
+		err = saveIdempotentResponse(ctx, tx, update.IdempotencySubject, update.IdempotencyKey, update.Fingerprint, result)
+		if err != nil {
+			return domain.TransferResult{}, err
+		}
+	}
+
+	return result, nil
+}
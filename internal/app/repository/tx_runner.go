@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"math/rand/v2"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	logger "github.com/sirupsen/logrus"
+)
+
+// txMetrics — счетчики транзакций записи, доступные через /debug/vars:
+// commits — успешные транзакции, retries — повторы после ошибок сериализации и взаимоблокировок,
+// retries_exhausted — транзакции, для которых не хватило попыток
+var txMetrics = expvar.NewMap("repository_tx")
+
+// RetryPolicy задает, сколько раз и с какими паузами повторять транзакцию
+type RetryPolicy struct {
+	// Общее число попыток, включая первую
+	MaxAttempts int
+	// Пауза перед первым повтором; каждая следующая удваивается
+	BaseDelay time.Duration
+	// Верхняя граница паузы
+	MaxDelay time.Duration
+}
+
+// Backoff возвращает паузу перед повтором номер attempt (начиная с 1): случайное значение
+// из второй половины экспоненциально растущего интервала, чтобы конкурирующие запросы разошлись во времени
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	delay := p.MaxDelay
+	if attempt < 32 {
+		if d := p.BaseDelay << (attempt - 1); d > 0 && d < p.MaxDelay {
+			delay = d
+		}
+	}
+	if delay <= 0 {
+		return 0
+	}
+
+	half := delay / 2
+	return half + rand.N(delay-half+1)
+}
+
+// IsRetryable сообщает, можно ли повторить транзакцию, завершившуюся ошибкой err:
+// это ошибки сериализации (40001) и взаимоблокировки (40P01), после которых Postgres откатывает транзакцию целиком
+func IsRetryable(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	return pgErr.Code == "40001" || pgErr.Code == "40P01"
+}
+
+// runTx выполняет fn в транзакции с уровнем изоляции iso и фиксирует ее.
+// При ошибке сериализации или взаимоблокировке транзакция повторяется целиком, поэтому fn
+// не должна иметь побочных эффектов вне транзакции.
+func runTx[T any](ctx context.Context, r *WalletRepository, iso pgx.TxIsoLevel, fn func(tx pgx.Tx) (T, error)) (T, error) {
+	var zero T
+	for attempt := 1; ; attempt++ {
+		result, err := runTxOnce(ctx, r, iso, fn)
+		if err == nil {
+			txMetrics.Add("commits", 1)
+			return result, nil
+		}
+		if !IsRetryable(err) {
+			return zero, err
+		}
+		if attempt >= r.retry.MaxAttempts {
+			txMetrics.Add("retries_exhausted", 1)
+			return zero, err
+		}
+
+		txMetrics.Add("retries", 1)
+		delay := r.retry.Backoff(attempt)
+		logger.WithField("attempt", attempt).Debugf("retrying transaction in %s: %v", delay, err)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return zero, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func runTxOnce[T any](ctx context.Context, r *WalletRepository, iso pgx.TxIsoLevel, fn func(tx pgx.Tx) (T, error)) (T, error) {
+	var zero T
+
+	tx, err := r.db.BeginTx(ctx, pgx.TxOptions{IsoLevel: iso})
+	if err != nil {
+		return zero, err
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := fn(tx)
+	if err != nil {
+		return zero, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return zero, err
+	}
+
+	return result, nil
+}
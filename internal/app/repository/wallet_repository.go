@@ -2,89 +2,437 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/shopspring/decimal"
 
 	"wallet-app/internal/app/app_errors"
 	"wallet-app/internal/app/domain"
 )
 
-// CreateWallet создает новый кошелек с начальным балансом
-func (r *WalletRepository) CreateWallet(ctx context.Context) (domain.Wallet, error) {
+// walletColumns — колонки кошелька в порядке, ожидаемом scanWallet; баланс включает несведенные зачисления на шарды
+const walletColumns = "wallet_id, balance + " + shardTotalSQL +
+	", currency, status, shard_count, owner_id, display_name, metadata, labels, created_at, updated_at"
+
+// CreateWallet создает новый кошелек с нулевым балансом
+func (r *WalletRepository) CreateWallet(ctx context.Context, req domain.CreateWalletRequest) (_ domain.Wallet, err error) {
+	defer translateError(&err)
+
 	// Генерируем новый UUID для кошелька
 	walletID := uuid.New()
 
-	// Вставляем новый кошелек в базу данных с начальным балансом 0
-	_, err := r.db.Exec(ctx, "INSERT INTO wallets(wallet_id, balance) VALUES($1, $2)", walletID, decimal.Zero.String())
+	metadata := req.Metadata
+	if metadata == nil {
+		metadata = map[string]any{}
+	}
+	labels := req.Labels
+	if labels == nil {
+		labels = []string{}
+	}
+
+	return runTx(ctx, r, pgx.ReadCommitted, func(tx pgx.Tx) (domain.Wallet, error) {
+		// Вставляем новый кошелек в базу данных с начальным балансом 0
+		wallet, err := scanWallet(tx.QueryRow(ctx,
+			`INSERT INTO wallets(wallet_id, balance, currency, shard_count, owner_id, display_name, metadata, labels)
+			VALUES($1, $2, $3, $4, $5, $6, $7, $8) RETURNING `+walletColumns,
+			walletID, decimal.Zero.String(), req.GetCurrency(), req.Shards, nullableString(req.OwnerID),
+			nullableString(req.DisplayName), metadata, labels,
+		))
+		if err != nil {
+			return domain.Wallet{}, err
+		}
+
+		if req.Shards > 0 {
+			_, err = tx.Exec(ctx,
+				"INSERT INTO wallet_balance_shards(wallet_id, shard_id) SELECT $1, generate_series(0, $2 - 1)",
+				walletID, req.Shards)
+			if err != nil {
+				return domain.Wallet{}, err
+			}
+		}
+
+		// Открываем счет кошелька в журнале двойной записи
+		_, err = tx.Exec(ctx,
+			"INSERT INTO ledger_accounts(account_id, account_type, wallet_id) VALUES($1, 'WALLET', $1)", walletID)
+		if err != nil {
+			return domain.Wallet{}, err
+		}
+
+		return wallet, nil
+	})
+}
+
+// ListWalletsByOwner возвращает все кошельки владельца в порядке создания
+func (r *WalletRepository) ListWalletsByOwner(ctx context.Context, ownerID string) (_ []domain.Wallet, err error) {
+	defer translateError(&err)
+
+	rows, err := r.db.Query(ctx,
+		"SELECT "+walletColumns+" FROM wallets WHERE owner_id = $1 ORDER BY created_at, wallet_id",
+		ownerID,
+	)
 	if err != nil {
-		return domain.Wallet{}, err
+		return nil, err
 	}
+	defer rows.Close()
 
-	// Возвращаем созданный кошелек с балансом 0
-	newWallet := domain.Wallet{
-		ID:      walletID,
-		Balance: decimal.Zero,
+	wallets := []domain.Wallet{}
+	for rows.Next() {
+		wallet, err := scanWallet(rows)
+		if err != nil {
+			return nil, err
+		}
+		wallets = append(wallets, wallet)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
 	}
 
-	return newWallet, nil
+	return wallets, nil
 }
 
-func (r *WalletRepository) GetWallet(ctx context.Context, walletID uuid.UUID) (*decimal.Decimal, error) {
+func scanWallet(row pgx.Row) (domain.Wallet, error) {
 	var wallet domain.Wallet
-	var balanceStr string
+	var balanceStr, status string
+	var ownerID, displayName *string
 
-	err := r.db.QueryRow(ctx, "SELECT wallet_id, balance FROM wallets WHERE wallet_id=$1", walletID).
-		Scan(&wallet.ID, &balanceStr)
+	err := row.Scan(&wallet.ID, &balanceStr, &wallet.Currency, &status, &wallet.Shards, &ownerID, &displayName,
+		&wallet.Metadata, &wallet.Labels, &wallet.CreatedAt, &wallet.UpdatedAt)
 	if err != nil {
-		return nil, err
+		return domain.Wallet{}, err
 	}
 
-	wallet.Balance, err = decimal.NewFromString(balanceStr)
-	if err != nil {
-		return nil, err
+	wallet.Status = domain.WalletStatus(status)
+	if ownerID != nil {
+		wallet.OwnerID = *ownerID
+	}
+	if displayName != nil {
+		wallet.DisplayName = *displayName
+	}
+	if wallet.Balance, err = decimal.NewFromString(balanceStr); err != nil {
+		return domain.Wallet{}, err
 	}
 
-	return &wallet.Balance, nil
+	return wallet, nil
 }
 
-func (r *WalletRepository) UpdateBalance(ctx context.Context, walletID uuid.UUID, amount decimal.Decimal) error {
-	// Начало транзакции
-	tx, err := r.db.Begin(ctx)
+// GetBalance возвращает баланс кошелька с учетом активных холдов
+func (r *WalletRepository) GetBalance(ctx context.Context, walletID uuid.UUID) (_ domain.WalletBalance, err error) {
+	defer translateError(&err)
+
+	var balanceStr, heldStr, currency, status string
+	var shardCount int
+	var version int64
+
+	err = r.db.QueryRow(ctx,
+		"SELECT balance + "+shardTotalSQL+", held, currency, status, shard_count, version FROM wallets WHERE wallet_id=$1",
+		walletID,
+	).Scan(&balanceStr, &heldStr, &currency, &status, &shardCount, &version)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.WalletBalance{}, app_errors.ErrWalletNotFound
+	}
 	if err != nil {
-		return err
+		return domain.WalletBalance{}, err
 	}
 
-	// Установка уровня изоляции транзакции
-	_, err = tx.Exec(ctx, "SET TRANSACTION ISOLATION LEVEL SERIALIZABLE")
+	wallet, err := parseLockedWallet(balanceStr, heldStr, currency, status)
 	if err != nil {
-		tx.Rollback(ctx)
-		return err
+		return domain.WalletBalance{}, err
 	}
+	wallet.ShardCount = shardCount
+	wallet.Version = version
 
-	defer tx.Rollback(ctx)
+	return wallet.WalletBalance(), nil
+}
+
+// UpdateBalance изменяет баланс кошелька и в той же транзакции записывает операцию в историю.
+// Если задан ключ идемпотентности, повторный запрос с тем же ключом возвращает сохраненный результат.
+func (r *WalletRepository) UpdateBalance(ctx context.Context, update domain.BalanceUpdate) (_ domain.Transaction, err error) {
+	defer translateError(&err)
+
+	// Зачисление на шардированный кошелек не блокирует строку кошелька на запись. Если баланс кошелька
+	// ограничен, зачисление выполняется под блокировкой, иначе параллельные зачисления превысят потолок.
+	if update.Amount.IsPositive() && update.Limits.MaxBalance == nil {
+		shardCount, err := walletShardCount(ctx, r.db, update.WalletID)
+		if err != nil {
+			return domain.Transaction{}, err
+		}
+		if shardCount > 0 {
+			return runTx(ctx, r, pgx.ReadCommitted, func(tx pgx.Tx) (domain.Transaction, error) {
+				return creditShardTx(ctx, tx, update)
+			})
+		}
+	}
+
+	return retryAfterDrain(ctx, r, update.WalletID, func() (domain.Transaction, error) {
+		return runTx(ctx, r, pgx.Serializable, func(tx pgx.Tx) (domain.Transaction, error) {
+			return updateBalanceTx(ctx, tx, update)
+		})
+	})
+}
+
+// updateBalanceTx выполняет UpdateBalance в транзакции tx
+func updateBalanceTx(ctx context.Context, tx pgx.Tx, update domain.BalanceUpdate) (_ domain.Transaction, err error) {
+	// Повтор запроса с уже использованным ключом отдает сохраненный ответ без изменения баланса
+	if update.IdempotencyKey != "" {
+		var stored domain.Transaction
+		found, err := findIdempotentResponse(ctx, tx, update.IdempotencySubject, update.IdempotencyKey, update.Fingerprint, &stored)
+		if err != nil {
+			return domain.Transaction{}, err
+		}
+		if found {
+			return stored, nil
+		}
+	}
 
 	// Получение текущего баланса кошелька с блокировкой строки для обновления
-	var balanceStr string
-	err = tx.QueryRow(ctx, "SELECT balance FROM wallets WHERE wallet_id=$1 FOR UPDATE", walletID).Scan(&balanceStr)
+	wallet, err := lockWallet(ctx, tx, update.WalletID)
 	if err != nil {
-		return err
+		return domain.Transaction{}, err
+	}
+
+	if err = wallet.CheckVersion(update.ExpectedVersion); err != nil {
+		return domain.Transaction{}, err
 	}
 
+	// Состояние кошелька проверяется под блокировкой, чтобы заморозка не разминулась с операцией
+	if err = wallet.Status.CheckMovement(update.Amount); err != nil {
+		return domain.Transaction{}, err
+	}
+
+	if err = wallet.CheckAmount(update.Currency, update.Amount); err != nil {
+		return domain.Transaction{}, err
+	}
+
+	// Списание не может затрагивать зарезервированные холдами средства
+	if update.Amount.IsNegative() && wallet.Available().Add(update.Amount).IsNegative() {
+		return domain.Transaction{}, app_errors.ErrInsufficientFunds
+	}
+
+	// Суммы списаний за день и месяц читаются под блокировкой кошелька, поэтому параллельные
+	// списания не превысят ограничение вместе
+	if err = checkLimits(ctx, tx, update.WalletID, wallet, update.Limits, update.Amount); err != nil {
+		return domain.Transaction{}, err
+	}
+	newBalance := wallet.Balance.Add(update.Amount)
+
+	// Проводим операцию по журналу: кошелек против внешнего системного счета
+	entry := domain.NewExternalEntry(update.OperationType, update.WalletID, wallet.Currency, update.Amount)
+	if err = postJournalEntry(ctx, tx, &entry); err != nil {
+		return domain.Transaction{}, err
+	}
+
+	// Записываем операцию в историю
+	transaction := domain.Transaction{
+		ID:            uuid.New(),
+		WalletID:      update.WalletID,
+		OperationType: update.OperationType,
+		Amount:        update.Amount,
+		Currency:      wallet.Currency,
+		BalanceAfter:  wallet.BalanceAfter(newBalance),
+		EntryID:       &entry.ID,
+	}
+	if err = insertTransaction(ctx, tx, &transaction); err != nil {
+		return domain.Transaction{}, err
+	}
+
+	if update.IdempotencyKey != "" {
+		err = saveIdempotentResponse(ctx, tx, update.IdempotencySubject, update.IdempotencyKey, update.Fingerprint, transaction)
+		if err != nil {
+			return domain.Transaction{}, err
+		}
+	}
+
+	return transaction, nil
+}
+
+// lockedWallet — состояние кошелька, прочитанное под блокировкой строки.
+// Для шардированного кошелька Balance — основной баланс без несведенных зачислений на шарды.
+type lockedWallet struct {
+	Balance    decimal.Decimal
+	Held       decimal.Decimal
+	Currency   string
+	Status     domain.WalletStatus
+	ShardCount int
+	// Версия строки кошелька для оптимистичной блокировки; не заполняется lockWalletForCredit
+	// для шардированного кошелька
+	Version int64
+}
+
+// Available возвращает сумму, доступную для списания
+func (w lockedWallet) Available() decimal.Decimal {
+	return w.Balance.Sub(w.Held)
+}
+
+// CheckAmount проверяет, что сумма выражена в валюте кошелька с допустимой для нее точностью.
+// Пустая currency означает, что клиент не указал валюту, и проверяется только точность.
+func (w lockedWallet) CheckAmount(currency string, amount decimal.Decimal) error {
+	if currency != "" && currency != w.Currency {
+		return app_errors.ErrCurrencyMismatch
+	}
+	return domain.ValidateAmountScale(w.Currency, amount)
+}
+
+// CheckVersion проверяет условие If-Match: expected — версия, которую клиент видел при чтении кошелька.
+// Зачисления на шарды версию не меняют, поэтому для шардированного кошелька условие не выполняется никогда.
+func (w lockedWallet) CheckVersion(expected *int64) error {
+	if expected == nil {
+		return nil
+	}
+	if w.ShardCount > 0 || *expected != w.Version {
+		return app_errors.ErrPreconditionFailed
+	}
+	return nil
+}
+
+// BalanceAfter возвращает баланс после операции для истории; у шардированного кошелька он не определен
+func (w lockedWallet) BalanceAfter(balance decimal.Decimal) *decimal.Decimal {
+	if w.ShardCount > 0 {
+		return nil
+	}
+	return &balance
+}
+
+func (w lockedWallet) WalletBalance() domain.WalletBalance {
+	balance := domain.WalletBalance{
+		Available: w.Available(),
+		Held:      w.Held,
+		Total:     w.Balance,
+		Currency:  w.Currency,
+		Status:    w.Status,
+	}
+	// Версия шардированного кошелька не отражает зачисления на шарды
+	if w.ShardCount == 0 {
+		balance.Version = w.Version
+	}
+	return balance
+}
+
+func parseLockedWallet(balanceStr, heldStr, currency, status string) (lockedWallet, error) {
 	balance, err := decimal.NewFromString(balanceStr)
 	if err != nil {
-		return err
+		return lockedWallet{}, err
 	}
 
-	newBalance := balance.Add(amount)
-	if newBalance.LessThan(decimal.Zero) {
-		return app_errors.ErrInsufficientFunds
+	held, err := decimal.NewFromString(heldStr)
+	if err != nil {
+		return lockedWallet{}, err
 	}
 
-	_, err = tx.Exec(ctx, "UPDATE wallets SET balance = $1 WHERE wallet_id = $2", newBalance.String(), walletID)
+	return lockedWallet{Balance: balance, Held: held, Currency: currency, Status: domain.WalletStatus(status)}, nil
+}
+
+// lockWallet блокирует строку кошелька до конца транзакции и возвращает его текущее состояние
+func lockWallet(ctx context.Context, tx pgx.Tx, walletID uuid.UUID) (lockedWallet, error) {
+	var balanceStr, heldStr, currency, status string
+	var shardCount int
+	var version int64
+	err := tx.QueryRow(ctx,
+		"SELECT balance, held, currency, status, shard_count, version FROM wallets WHERE wallet_id=$1 FOR UPDATE", walletID,
+	).Scan(&balanceStr, &heldStr, &currency, &status, &shardCount, &version)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return lockedWallet{}, app_errors.ErrWalletNotFound
+	}
+	if err != nil {
+		return lockedWallet{}, err
+	}
+
+	wallet, err := parseLockedWallet(balanceStr, heldStr, currency, status)
+	wallet.ShardCount = shardCount
+	wallet.Version = version
+	return wallet, err
+}
+
+// transactionInsertColumns — колонки wallet_transactions в порядке transactionArgs
+const transactionInsertColumns = `transaction_id, wallet_id, operation_type, amount, currency, balance_after,
+	transfer_id, counterparty_wallet_id, entry_id, original_transaction_id,
+	exchange_rate, counter_amount, counter_currency`
+
+func transactionArgs(transaction *domain.Transaction) []any {
+	return []any{
+		transaction.ID, transaction.WalletID, string(transaction.OperationType), transaction.Amount.String(),
+		transaction.Currency, nullableDecimal(transaction.BalanceAfter), transaction.TransferID, transaction.CounterpartyWalletID,
+		transaction.EntryID, transaction.OriginalTransactionID, nullableDecimal(transaction.ExchangeRate),
+		nullableDecimal(transaction.CounterAmount), nullableString(transaction.CounterCurrency),
+	}
+}
+
+// insertTransaction записывает операцию в историю и заполняет время ее создания
+func insertTransaction(ctx context.Context, tx pgx.Tx, transaction *domain.Transaction) error {
+	return insertTransactions(ctx, tx, []*domain.Transaction{transaction})
+}
+
+// insertTransactions записывает операции в историю одним многострочным INSERT и заполняет время их создания
+func insertTransactions(ctx context.Context, tx pgx.Tx, transactions []*domain.Transaction) error {
+	if len(transactions) == 0 {
+		return nil
+	}
+
+	byID := make(map[uuid.UUID]*domain.Transaction, len(transactions))
+	values := make([]string, 0, len(transactions))
+	var args []any
+	for _, transaction := range transactions {
+		row := transactionArgs(transaction)
+		placeholders := make([]string, len(row))
+		for k := range row {
+			placeholders[k] = fmt.Sprintf("$%d", len(args)+k+1)
+		}
+		values = append(values, "("+strings.Join(placeholders, ", ")+")")
+		args = append(args, row...)
+		byID[transaction.ID] = transaction
+	}
+
+	rows, err := tx.Query(ctx,
+		"INSERT INTO wallet_transactions("+transactionInsertColumns+") VALUES "+strings.Join(values, ", ")+
+			" RETURNING transaction_id, created_at",
+		args...,
+	)
 	if err != nil {
 		return err
 	}
+	defer rows.Close()
+
+	// Порядок строк RETURNING не гарантирован, поэтому время сопоставляется по идентификатору
+	for rows.Next() {
+		var transactionID uuid.UUID
+		var createdAt time.Time
+		if err = rows.Scan(&transactionID, &createdAt); err != nil {
+			return err
+		}
+		if transaction, ok := byID[transactionID]; ok {
+			transaction.CreatedAt = createdAt
+		}
+	}
+
+	return rows.Err()
+}
 
-	return tx.Commit(ctx)
+func nullableDecimal(value *decimal.Decimal) *string {
+	if value == nil {
+		return nil
+	}
+	str := value.String()
+	return &str
+}
+
+func nullableString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+func parseNullableDecimal(value *string) (*decimal.Decimal, error) {
+	if value == nil {
+		return nil, nil
+	}
+	parsed, err := decimal.NewFromString(*value)
+	if err != nil {
+		return nil, err
+	}
+	return &parsed, nil
 }
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"wallet-app/internal/app/domain"
+)
+
+// SetWalletStatus переводит кошелек в состояние status. Строка кошелька блокируется,
+// поэтому смена состояния упорядочена с операциями по кошельку.
+func (r *WalletRepository) SetWalletStatus(ctx context.Context, walletID uuid.UUID, status domain.WalletStatus, reason string) (_ domain.WalletState, err error) {
+	defer translateError(&err)
+
+	return runTx(ctx, r, pgx.Serializable, func(tx pgx.Tx) (domain.WalletState, error) {
+		return setWalletStatusTx(ctx, tx, walletID, status, reason)
+	})
+}
+
+// setWalletStatusTx выполняет SetWalletStatus в транзакции tx
+func setWalletStatusTx(ctx context.Context, tx pgx.Tx, walletID uuid.UUID, status domain.WalletStatus, reason string) (_ domain.WalletState, err error) {
+	wallet, err := lockWallet(ctx, tx, walletID)
+	if err != nil {
+		return domain.WalletState{}, err
+	}
+
+	// Закрыть кошелек можно, только если на его шардах тоже не осталось средств
+	balance := wallet.Balance
+	if status == domain.WalletClosed && wallet.ShardCount > 0 {
+		shardTotal, err := lockShardTotal(ctx, tx, walletID)
+		if err != nil {
+			return domain.WalletState{}, err
+		}
+		balance = balance.Add(shardTotal)
+	}
+
+	if err = wallet.Status.CheckTransition(status, balance, wallet.Held); err != nil {
+		return domain.WalletState{}, err
+	}
+
+	state := domain.WalletState{WalletID: walletID}
+	var statusStr string
+	var reasonStr *string
+
+	err = tx.QueryRow(ctx,
+		`UPDATE wallets SET status = $1, status_reason = $2, status_changed_at = now()
+		WHERE wallet_id = $3 RETURNING status, status_reason, status_changed_at`,
+		string(status), nullableString(reason), walletID,
+	).Scan(&statusStr, &reasonStr, &state.StatusChangedAt)
+	if err != nil {
+		return domain.WalletState{}, err
+	}
+
+	state.Status = domain.WalletStatus(statusStr)
+	if reasonStr != nil {
+		state.Reason = *reasonStr
+	}
+
+	return state, nil
+}
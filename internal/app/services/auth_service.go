@@ -0,0 +1,138 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"wallet-app/internal/app/app_errors"
+	"wallet-app/internal/app/domain"
+	"wallet-app/internal/app/repository"
+)
+
+const (
+	// apiKeyPrefix отличает ключи приложения от других секретов, например при поиске утечек в коде
+	apiKeyPrefix = "wk_"
+	// apiKeyBytes — число случайных байт ключа; 256 бит достаточно, чтобы хранить быстрый хеш без соли
+	apiKeyBytes = 32
+	// apiKeyVisiblePrefix — сколько первых символов ключа сохраняется для его опознания
+	apiKeyVisiblePrefix = 11
+)
+
+type AuthService struct {
+	keys       repository.APIKeys
+	nonces     repository.Nonces
+	tokens     TokenVerifier
+	signatures RequestVerifier
+}
+
+// NewAuthService создает AuthService; если tokens равен nil, токены JWT не принимаются,
+// если signatures равен nil — не принимаются подписанные запросы
+func NewAuthService(keys repository.APIKeys, nonces repository.Nonces, tokens TokenVerifier, signatures RequestVerifier) *AuthService {
+	return &AuthService{keys: keys, nonces: nonces, tokens: tokens, signatures: signatures}
+}
+
+// Authenticate определяет клиента по ключу API или токену JWT
+func (s *AuthService) Authenticate(ctx context.Context, credentials domain.Credentials) (domain.Principal, error) {
+	switch {
+	case credentials.APIKey != "" && credentials.BearerToken != "":
+		return domain.Principal{}, app_errors.ErrAmbiguousCredentials
+	case credentials.APIKey != "":
+		return s.authenticateAPIKey(ctx, credentials.APIKey)
+	case credentials.BearerToken != "":
+		if s.tokens == nil {
+			return domain.Principal{}, app_errors.ErrInvalidCredentials.Wrap(errors.New("bearer tokens are not accepted"))
+		}
+		return s.tokens.Verify(credentials.BearerToken)
+	}
+	return domain.Principal{}, app_errors.ErrUnauthenticated
+}
+
+func (s *AuthService) authenticateAPIKey(ctx context.Context, rawKey string) (domain.Principal, error) {
+	key, err := s.keys.GetAPIKeyByHash(ctx, hashAPIKey(rawKey))
+	if errors.Is(err, app_errors.ErrNotFound) {
+		return domain.Principal{}, app_errors.ErrInvalidCredentials
+	}
+	if err != nil {
+		return domain.Principal{}, err
+	}
+
+	if !key.Active(time.Now()) {
+		return domain.Principal{}, app_errors.ErrInvalidCredentials.Wrap(errors.New("api key is expired or revoked"))
+	}
+
+	return domain.Principal{Subject: key.Subject, Method: domain.AuthAPIKey, Roles: key.Roles, KeyID: &key.ID}, nil
+}
+
+// IssueAPIKey выпускает новый ключ API. Ключ возвращается только здесь: в хранилище попадает его хеш.
+func (s *AuthService) IssueAPIKey(ctx context.Context, req domain.APIKeyRequest) (domain.IssuedAPIKey, error) {
+	random := make([]byte, apiKeyBytes)
+	if _, err := rand.Read(random); err != nil {
+		return domain.IssuedAPIKey{}, err
+	}
+	rawKey := apiKeyPrefix + base64.RawURLEncoding.EncodeToString(random)
+
+	key := domain.APIKey{
+		ID:      uuid.New(),
+		Name:    req.Name,
+		Subject: req.Subject,
+		Roles:   req.Roles,
+		Prefix:  rawKey[:apiKeyVisiblePrefix],
+	}
+	if key.Roles == nil {
+		key.Roles = []string{}
+	}
+	if req.TTL > 0 {
+		expiresAt := time.Now().Add(req.TTL)
+		key.ExpiresAt = &expiresAt
+	}
+
+	key, err := s.keys.CreateAPIKey(ctx, key, hashAPIKey(rawKey))
+	if err != nil {
+		return domain.IssuedAPIKey{}, err
+	}
+
+	return domain.IssuedAPIKey{APIKey: key, Key: rawKey}, nil
+}
+
+// VerifySignature проверяет подпись запроса клиента из контекста. Клиент, для которого настроены
+// ключи подписи, обязан подписывать каждый запрос; подпись остальных клиентов проверяется, если она передана.
+// Nonce запоминается только после проверки подписи, чтобы чужие запросы не занимали хранилище.
+func (s *AuthService) VerifySignature(ctx context.Context, req domain.SignedRequest) error {
+	principal, _ := domain.PrincipalFromContext(ctx)
+
+	if !req.Signed() {
+		if s.signatures != nil && s.signatures.Requires(principal.Subject) {
+			return app_errors.ErrSignatureRequired
+		}
+		return nil
+	}
+	if s.signatures == nil {
+		return app_errors.ErrInvalidSignature.Wrap(errors.New("signed requests are not accepted"))
+	}
+
+	nonceExpiresAt, err := s.signatures.Verify(principal.Subject, req)
+	if err != nil {
+		return err
+	}
+
+	// Nonce уникален в пределах ключа: разные клиенты могут случайно выбрать одинаковые значения
+	fresh, err := s.nonces.RememberNonce(ctx, req.KeyID+":"+req.Nonce, nonceExpiresAt)
+	if err != nil {
+		return err
+	}
+	if !fresh {
+		return app_errors.ErrRequestReplayed
+	}
+	return nil
+}
+
+func hashAPIKey(rawKey string) []byte {
+	hash := sha256.Sum256([]byte(rawKey))
+	return hash[:]
+}
@@ -0,0 +1,64 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"wallet-app/internal/app/app_errors"
+	"wallet-app/internal/app/domain"
+	"wallet-app/internal/app/repository"
+)
+
+// Authorizer проверяет, разрешено ли клиенту из контекста запроса действие над кошельком.
+// Контекст без клиента означает вызов изнутри приложения (фоновые задачи, CLI, тесты сервисов):
+// такие вызовы не проверяются. Все запросы API проходят через Authenticate и всегда несут клиента.
+type Authorizer struct {
+	grants repository.WalletGrants
+}
+
+func NewAuthorizer(grants repository.WalletGrants) *Authorizer {
+	return &Authorizer{grants: grants}
+}
+
+// CheckWallet разрешает действие, если его допускают роли клиента на все кошельки,
+// владение кошельком или роль, выданная клиенту на этот кошелек
+func (a *Authorizer) CheckWallet(ctx context.Context, walletID uuid.UUID, permission domain.Permission) error {
+	principal, ok := domain.PrincipalFromContext(ctx)
+	if !ok || principal.Allows(permission) {
+		return nil
+	}
+
+	access, err := a.grants.GetWalletAccess(ctx, walletID, principal.Subject)
+	if err != nil {
+		return err
+	}
+	if !access.Allows(principal.Subject, permission) {
+		return forbidden(principal, permission)
+	}
+	return nil
+}
+
+// CheckOwner разрешает действие над всеми кошельками владельца ownerID самому владельцу
+// и клиентам с ролью, допускающей действие над любым кошельком
+func (a *Authorizer) CheckOwner(ctx context.Context, ownerID string, permission domain.Permission) error {
+	principal, ok := domain.PrincipalFromContext(ctx)
+	if !ok || principal.Allows(permission) || (ownerID != "" && principal.Subject == ownerID) {
+		return nil
+	}
+	return forbidden(principal, permission)
+}
+
+// Check разрешает действие, не относящееся к конкретному кошельку, только по ролям клиента
+func (a *Authorizer) Check(ctx context.Context, permission domain.Permission) error {
+	principal, ok := domain.PrincipalFromContext(ctx)
+	if !ok || principal.Allows(permission) {
+		return nil
+	}
+	return forbidden(principal, permission)
+}
+
+func forbidden(principal domain.Principal, permission domain.Permission) error {
+	return app_errors.ErrForbidden.Wrap(fmt.Errorf("subject %q has no %s permission", principal.Subject, permission))
+}
@@ -0,0 +1,22 @@
+package services
+
+import (
+	"context"
+
+	"wallet-app/internal/app/domain"
+)
+
+// DiagnosticsService проверяет доступ к служебным данным процесса: метрикам expvar
+// с командной строкой, статистикой памяти и счетчиками операций
+type DiagnosticsService struct {
+	authz *Authorizer
+}
+
+func NewDiagnosticsService(authz *Authorizer) *DiagnosticsService {
+	return &DiagnosticsService{authz: authz}
+}
+
+// CheckDiagnostics разрешает чтение служебных данных только администраторам
+func (s *DiagnosticsService) CheckDiagnostics(ctx context.Context) error {
+	return s.authz.Check(ctx, domain.PermissionAdmin)
+}
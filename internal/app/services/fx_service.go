@@ -0,0 +1,38 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"wallet-app/internal/app/domain"
+	"wallet-app/internal/app/repository"
+	"wallet-app/internal/configs"
+)
+
+type FXService struct {
+	repo  repository.Wallets
+	rates ExchangeRateProvider
+	cfg   configs.FXConfig
+}
+
+func NewFXService(repo repository.Wallets, rates ExchangeRateProvider, cfg configs.FXConfig) *FXService {
+	return &FXService{repo: repo, rates: rates, cfg: cfg}
+}
+
+// CreateQuote фиксирует текущий курс пары валют на время QuoteTTL
+func (s *FXService) CreateQuote(ctx context.Context, req domain.FXQuoteRequest) (domain.FXQuote, error) {
+	rate, err := s.rates.GetRate(ctx, req.FromCurrency, req.ToCurrency)
+	if err != nil {
+		return domain.FXQuote{}, err
+	}
+
+	return s.repo.CreateQuote(ctx, domain.FXQuote{
+		ID:           uuid.New(),
+		FromCurrency: req.FromCurrency,
+		ToCurrency:   req.ToCurrency,
+		Rate:         rate,
+		ExpiresAt:    time.Now().Add(s.cfg.QuoteTTL),
+	})
+}
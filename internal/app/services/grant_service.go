@@ -0,0 +1,56 @@
+package services
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"wallet-app/internal/app/domain"
+	"wallet-app/internal/app/repository"
+)
+
+type GrantService struct {
+	repo  repository.WalletGrants
+	authz *Authorizer
+}
+
+func NewGrantService(repo repository.WalletGrants, authz *Authorizer) *GrantService {
+	return &GrantService{repo: repo, authz: authz}
+}
+
+// ListGrants возвращает роли, выданные на кошелек
+func (s *GrantService) ListGrants(ctx context.Context, walletID uuid.UUID) (domain.WalletGrants, error) {
+	if err := s.authz.CheckWallet(ctx, walletID, domain.PermissionManage); err != nil {
+		return domain.WalletGrants{}, err
+	}
+
+	grants, err := s.repo.ListWalletGrants(ctx, walletID)
+	if err != nil {
+		return domain.WalletGrants{}, err
+	}
+
+	return domain.WalletGrants{WalletID: walletID, Grants: grants}, nil
+}
+
+// GrantAccess выдает клиенту subject роль на кошелек, заменяя выданную ранее
+func (s *GrantService) GrantAccess(ctx context.Context, walletID uuid.UUID, subject string, req domain.WalletGrantRequest) (domain.WalletGrant, error) {
+	if err := s.authz.CheckWallet(ctx, walletID, domain.PermissionManage); err != nil {
+		return domain.WalletGrant{}, err
+	}
+
+	grant := domain.WalletGrant{WalletID: walletID, Subject: subject, Role: req.Role}
+	if principal, ok := domain.PrincipalFromContext(ctx); ok {
+		grant.GrantedBy = principal.Subject
+	}
+
+	return s.repo.PutWalletGrant(ctx, grant)
+}
+
+// RevokeAccess отзывает роль клиента subject на кошелек. Владение кошельком (ownerId) так не отзывается.
+func (s *GrantService) RevokeAccess(ctx context.Context, walletID uuid.UUID, subject string) error {
+	if err := s.authz.CheckWallet(ctx, walletID, domain.PermissionManage); err != nil {
+		return err
+	}
+
+	return s.repo.DeleteWalletGrant(ctx, walletID, subject)
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"wallet-app/internal/app/app_errors"
+	"wallet-app/internal/app/domain"
+	"wallet-app/internal/app/repository"
+	"wallet-app/internal/configs"
+)
+
+type HoldService struct {
+	repo   repository.Wallets
+	authz  *Authorizer
+	limits *LimitService
+	cfg    configs.HoldsConfig
+}
+
+func NewHoldService(repo repository.Wallets, authz *Authorizer, limits *LimitService, cfg configs.HoldsConfig) *HoldService {
+	return &HoldService{repo: repo, authz: authz, limits: limits, cfg: cfg}
+}
+
+// CreateHold резервирует средства кошелька на время TTL. Резерв — будущее списание,
+// поэтому проверяется по ограничениям операций кошелька и учитывается в списаниях за день и месяц.
+func (s *HoldService) CreateHold(ctx context.Context, walletID uuid.UUID, req domain.HoldRequest) (domain.Hold, error) {
+	if err := s.authz.CheckWallet(ctx, walletID, domain.PermissionOperate); err != nil {
+		return domain.Hold{}, err
+	}
+
+	amount, err := req.ParseAmount()
+	if err != nil {
+		return domain.Hold{}, fmt.Errorf("failed to parse amount: %w", err)
+	}
+
+	ttl := s.cfg.DefaultTTL
+	if req.TTLSeconds > 0 {
+		ttl = time.Duration(req.TTLSeconds) * time.Second
+	}
+	if ttl > s.cfg.MaxTTL {
+		return domain.Hold{}, app_errors.ErrHoldTTLTooLong
+	}
+
+	limits, err := s.limits.Effective(ctx, walletID)
+	if err != nil {
+		return domain.Hold{}, err
+	}
+
+	return s.repo.CreateHold(ctx, walletID, amount, time.Now().Add(ttl), limits)
+}
+
+// CaptureHold списывает зарезервированные средства полностью или частично.
+// Списание проверяется по действующим ограничениям: они могли измениться после создания холда.
+func (s *HoldService) CaptureHold(ctx context.Context, walletID, holdID uuid.UUID, req domain.CaptureRequest) (domain.HoldCaptureResult, error) {
+	if err := s.authz.CheckWallet(ctx, walletID, domain.PermissionOperate); err != nil {
+		return domain.HoldCaptureResult{}, err
+	}
+
+	amount, err := req.ParseAmount()
+	if err != nil {
+		return domain.HoldCaptureResult{}, fmt.Errorf("failed to parse amount: %w", err)
+	}
+
+	limits, err := s.limits.Effective(ctx, walletID)
+	if err != nil {
+		return domain.HoldCaptureResult{}, err
+	}
+
+	return s.repo.CaptureHold(ctx, walletID, holdID, amount, limits)
+}
+
+// ReleaseHold освобождает зарезервированные средства
+func (s *HoldService) ReleaseHold(ctx context.Context, walletID, holdID uuid.UUID) (domain.Hold, error) {
+	if err := s.authz.CheckWallet(ctx, walletID, domain.PermissionOperate); err != nil {
+		return domain.Hold{}, err
+	}
+
+	return s.repo.ReleaseHold(ctx, walletID, holdID)
+}
+
+// ExpireHolds освобождает просроченные холды, обрабатывая за раз не больше SweepBatchSize
+func (s *HoldService) ExpireHolds(ctx context.Context) (int, error) {
+	return s.repo.ExpireHolds(ctx, s.cfg.SweepBatchSize)
+}
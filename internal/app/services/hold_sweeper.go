@@ -0,0 +1,30 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	logger "github.com/sirupsen/logrus"
+)
+
+// RunHoldSweeper периодически освобождает просроченные холды до отмены контекста
+func RunHoldSweeper(ctx context.Context, holds Holds, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			expired, err := holds.ExpireHolds(ctx)
+			if err != nil {
+				logger.Errorf("Failed to expire holds: %v", err)
+				continue
+			}
+			if expired > 0 {
+				logger.Debugf("Expired %d holds", expired)
+			}
+		}
+	}
+}
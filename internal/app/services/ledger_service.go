@@ -0,0 +1,26 @@
+package services
+
+import (
+	"context"
+
+	"wallet-app/internal/app/domain"
+	"wallet-app/internal/app/repository"
+)
+
+type LedgerService struct {
+	repo  repository.Wallets
+	authz *Authorizer
+}
+
+func NewLedgerService(repo repository.Wallets, authz *Authorizer) *LedgerService {
+	return &LedgerService{repo: repo, authz: authz}
+}
+
+// VerifyLedger сверяет журнал двойной записи с проекциями балансов кошельков; доступно только администраторам
+func (s *LedgerService) VerifyLedger(ctx context.Context) (domain.LedgerReport, error) {
+	if err := s.authz.Check(ctx, domain.PermissionAdmin); err != nil {
+		return domain.LedgerReport{}, err
+	}
+
+	return s.repo.VerifyLedger(ctx)
+}
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	logger "github.com/sirupsen/logrus"
+
+	"wallet-app/internal/app/app_errors"
+	"wallet-app/internal/app/domain"
+	"wallet-app/internal/app/repository"
+	"wallet-app/internal/configs"
+)
+
+// LimitService управляет ограничениями операций кошельков и вычисляет действующие ограничения:
+// ограничения уровня кошелька в его валюте, замененные собственными ограничениями кошелька
+type LimitService struct {
+	repo        repository.WalletLimits
+	authz       *Authorizer
+	defaultTier string
+	tiers       map[limitTier]domain.TransactionLimits
+}
+
+// limitTier — уровень ограничений в одной валюте
+type limitTier struct {
+	name     string
+	currency string
+}
+
+func NewLimitService(repo repository.WalletLimits, authz *Authorizer, cfg configs.LimitsConfig) *LimitService {
+	tiers := make(map[limitTier]domain.TransactionLimits, len(cfg.Tiers))
+	for _, tier := range cfg.Tiers {
+		currency := strings.ToUpper(tier.Currency)
+		if err := domain.ValidateCurrency(currency); err != nil {
+			logger.Warnf("Unsupported currency '%s' in limits tier '%s', the tier is ignored for it", tier.Currency, tier.Name)
+			continue
+		}
+
+		var limits domain.TransactionLimits
+		for _, field := range []struct {
+			value  string
+			target **decimal.Decimal
+		}{
+			{tier.MaxTransaction, &limits.MaxTransaction},
+			{tier.DailyWithdrawal, &limits.DailyWithdrawal},
+			{tier.MonthlyWithdrawal, &limits.MonthlyWithdrawal},
+			{tier.MaxBalance, &limits.MaxBalance},
+		} {
+			if field.value == "" {
+				continue
+			}
+			amount, err := decimal.NewFromString(field.value)
+			if err != nil {
+				logger.Warnf("Invalid amount '%s' in limits tier '%s', the limit is ignored", field.value, tier.Name)
+				continue
+			}
+			*field.target = &amount
+		}
+		tiers[limitTier{name: tier.Name, currency: currency}] = limits
+	}
+
+	return &LimitService{repo: repo, authz: authz, defaultTier: cfg.DefaultTier, tiers: tiers}
+}
+
+// GetLimits возвращает уровень, собственные и действующие ограничения кошелька
+func (s *LimitService) GetLimits(ctx context.Context, walletID uuid.UUID) (domain.WalletLimits, error) {
+	if err := s.authz.CheckWallet(ctx, walletID, domain.PermissionRead); err != nil {
+		return domain.WalletLimits{}, err
+	}
+
+	limits, err := s.repo.GetWalletLimits(ctx, walletID)
+	if err != nil {
+		return domain.WalletLimits{}, err
+	}
+
+	limits.Effective = s.effective(limits)
+	return limits, nil
+}
+
+// SetLimits назначает кошельку уровень и собственные ограничения, заменяя прежние.
+// Ограничения устанавливает служба комплаенса, поэтому нужна роль admin.
+func (s *LimitService) SetLimits(ctx context.Context, walletID uuid.UUID, req domain.WalletLimitsRequest) (domain.WalletLimits, error) {
+	if err := s.authz.CheckWallet(ctx, walletID, domain.PermissionAdmin); err != nil {
+		return domain.WalletLimits{}, err
+	}
+
+	overrides, err := req.Overrides()
+	if err != nil {
+		return domain.WalletLimits{}, err
+	}
+
+	// Суммы уровня заданы в конкретной валюте, поэтому уровень должен быть описан для валюты кошелька
+	if req.Tier != "" {
+		current, err := s.repo.GetWalletLimits(ctx, walletID)
+		if err != nil {
+			return domain.WalletLimits{}, err
+		}
+		if _, ok := s.tiers[limitTier{name: req.Tier, currency: current.Currency}]; !ok {
+			return domain.WalletLimits{}, app_errors.ErrUnknownLimitTier.Wrap(
+				fmt.Errorf("tier %q is not configured for %s", req.Tier, current.Currency))
+		}
+	}
+
+	limits, err := s.repo.PutWalletLimits(ctx, domain.WalletLimits{WalletID: walletID, Tier: req.Tier, Overrides: overrides})
+	if err != nil {
+		return domain.WalletLimits{}, err
+	}
+
+	limits.Effective = s.effective(limits)
+	return limits, nil
+}
+
+// Effective возвращает действующие ограничения кошелька без проверки доступа: их запрашивают
+// сервисы перед изменением баланса, уже проверив доступ к операции
+func (s *LimitService) Effective(ctx context.Context, walletID uuid.UUID) (domain.TransactionLimits, error) {
+	limits, err := s.repo.GetWalletLimits(ctx, walletID)
+	if err != nil {
+		return domain.TransactionLimits{}, err
+	}
+	return s.effective(limits), nil
+}
+
+func (s *LimitService) effective(limits domain.WalletLimits) domain.TransactionLimits {
+	tier := limits.Tier
+	if tier == "" {
+		tier = s.defaultTier
+	}
+	// Уровень, удаленный из конфигурации или не описанный для валюты кошелька, ничего не ограничивает;
+	// собственные ограничения кошелька действуют
+	return limits.Overrides.Merge(s.tiers[limitTier{name: tier, currency: limits.Currency}])
+}
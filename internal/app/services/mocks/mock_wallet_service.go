@@ -7,6 +7,7 @@ package mocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 	domain "wallet-app/internal/app/domain"
 
 	gomock "github.com/golang/mock/gomock"
@@ -38,25 +39,25 @@ func (m *MockWallet) EXPECT() *MockWalletMockRecorder {
 }
 
 // CreateWallet mocks base method.
-func (m *MockWallet) CreateWallet(ctx context.Context) (domain.Wallet, error) {
+func (m *MockWallet) CreateWallet(ctx context.Context, req domain.CreateWalletRequest) (domain.Wallet, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateWallet", ctx)
+	ret := m.ctrl.Call(m, "CreateWallet", ctx, req)
 	ret0, _ := ret[0].(domain.Wallet)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // CreateWallet indicates an expected call of CreateWallet.
-func (mr *MockWalletMockRecorder) CreateWallet(ctx interface{}) *gomock.Call {
+func (mr *MockWalletMockRecorder) CreateWallet(ctx, req interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWallet", reflect.TypeOf((*MockWallet)(nil).CreateWallet), ctx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWallet", reflect.TypeOf((*MockWallet)(nil).CreateWallet), ctx, req)
 }
 
 // GetBalance mocks base method.
-func (m *MockWallet) GetBalance(ctx context.Context, walletID uuid.UUID) (decimal.Decimal, error) {
+func (m *MockWallet) GetBalance(ctx context.Context, walletID uuid.UUID) (domain.WalletBalance, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetBalance", ctx, walletID)
-	ret0, _ := ret[0].(decimal.Decimal)
+	ret0, _ := ret[0].(domain.WalletBalance)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -67,12 +68,43 @@ func (mr *MockWalletMockRecorder) GetBalance(ctx, walletID interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBalance", reflect.TypeOf((*MockWallet)(nil).GetBalance), ctx, walletID)
 }
 
+// ListTransactions mocks base method.
+func (m *MockWallet) ListTransactions(ctx context.Context, walletID uuid.UUID, filter domain.TransactionFilter) (domain.TransactionPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTransactions", ctx, walletID, filter)
+	ret0, _ := ret[0].(domain.TransactionPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTransactions indicates an expected call of ListTransactions.
+func (mr *MockWalletMockRecorder) ListTransactions(ctx, walletID, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTransactions", reflect.TypeOf((*MockWallet)(nil).ListTransactions), ctx, walletID, filter)
+}
+
+// ListWalletsByOwner mocks base method.
+func (m *MockWallet) ListWalletsByOwner(ctx context.Context, ownerID string) (domain.WalletsByOwner, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWalletsByOwner", ctx, ownerID)
+	ret0, _ := ret[0].(domain.WalletsByOwner)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWalletsByOwner indicates an expected call of ListWalletsByOwner.
+func (mr *MockWalletMockRecorder) ListWalletsByOwner(ctx, ownerID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWalletsByOwner", reflect.TypeOf((*MockWallet)(nil).ListWalletsByOwner), ctx, ownerID)
+}
+
 // ProcessOperation mocks base method.
-func (m *MockWallet) ProcessOperation(ctx context.Context, op domain.WalletOperation) error {
+func (m *MockWallet) ProcessOperation(ctx context.Context, op domain.WalletOperation) (domain.Transaction, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "ProcessOperation", ctx, op)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret0, _ := ret[0].(domain.Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // ProcessOperation indicates an expected call of ProcessOperation.
@@ -80,3 +112,671 @@ func (mr *MockWalletMockRecorder) ProcessOperation(ctx, op interface{}) *gomock.
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessOperation", reflect.TypeOf((*MockWallet)(nil).ProcessOperation), ctx, op)
 }
+
+// Transfer mocks base method.
+func (m *MockWallet) Transfer(ctx context.Context, op domain.TransferOperation) (domain.TransferResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Transfer", ctx, op)
+	ret0, _ := ret[0].(domain.TransferResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Transfer indicates an expected call of Transfer.
+func (mr *MockWalletMockRecorder) Transfer(ctx, op interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Transfer", reflect.TypeOf((*MockWallet)(nil).Transfer), ctx, op)
+}
+
+// MockWalletAdmin is a mock of WalletAdmin interface.
+type MockWalletAdmin struct {
+	ctrl     *gomock.Controller
+	recorder *MockWalletAdminMockRecorder
+}
+
+// MockWalletAdminMockRecorder is the mock recorder for MockWalletAdmin.
+type MockWalletAdminMockRecorder struct {
+	mock *MockWalletAdmin
+}
+
+// NewMockWalletAdmin creates a new mock instance.
+func NewMockWalletAdmin(ctrl *gomock.Controller) *MockWalletAdmin {
+	mock := &MockWalletAdmin{ctrl: ctrl}
+	mock.recorder = &MockWalletAdminMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWalletAdmin) EXPECT() *MockWalletAdminMockRecorder {
+	return m.recorder
+}
+
+// SetWalletStatus mocks base method.
+func (m *MockWalletAdmin) SetWalletStatus(ctx context.Context, walletID uuid.UUID, status domain.WalletStatus, req domain.WalletStatusRequest) (domain.WalletState, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetWalletStatus", ctx, walletID, status, req)
+	ret0, _ := ret[0].(domain.WalletState)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetWalletStatus indicates an expected call of SetWalletStatus.
+func (mr *MockWalletAdminMockRecorder) SetWalletStatus(ctx, walletID, status, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetWalletStatus", reflect.TypeOf((*MockWalletAdmin)(nil).SetWalletStatus), ctx, walletID, status, req)
+}
+
+// MockLedger is a mock of Ledger interface.
+type MockLedger struct {
+	ctrl     *gomock.Controller
+	recorder *MockLedgerMockRecorder
+}
+
+// MockLedgerMockRecorder is the mock recorder for MockLedger.
+type MockLedgerMockRecorder struct {
+	mock *MockLedger
+}
+
+// NewMockLedger creates a new mock instance.
+func NewMockLedger(ctrl *gomock.Controller) *MockLedger {
+	mock := &MockLedger{ctrl: ctrl}
+	mock.recorder = &MockLedgerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLedger) EXPECT() *MockLedgerMockRecorder {
+	return m.recorder
+}
+
+// VerifyLedger mocks base method.
+func (m *MockLedger) VerifyLedger(ctx context.Context) (domain.LedgerReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyLedger", ctx)
+	ret0, _ := ret[0].(domain.LedgerReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyLedger indicates an expected call of VerifyLedger.
+func (mr *MockLedgerMockRecorder) VerifyLedger(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyLedger", reflect.TypeOf((*MockLedger)(nil).VerifyLedger), ctx)
+}
+
+// MockDiagnostics is a mock of Diagnostics interface.
+type MockDiagnostics struct {
+	ctrl     *gomock.Controller
+	recorder *MockDiagnosticsMockRecorder
+}
+
+// MockDiagnosticsMockRecorder is the mock recorder for MockDiagnostics.
+type MockDiagnosticsMockRecorder struct {
+	mock *MockDiagnostics
+}
+
+// NewMockDiagnostics creates a new mock instance.
+func NewMockDiagnostics(ctrl *gomock.Controller) *MockDiagnostics {
+	mock := &MockDiagnostics{ctrl: ctrl}
+	mock.recorder = &MockDiagnosticsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDiagnostics) EXPECT() *MockDiagnosticsMockRecorder {
+	return m.recorder
+}
+
+// CheckDiagnostics mocks base method.
+func (m *MockDiagnostics) CheckDiagnostics(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckDiagnostics", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CheckDiagnostics indicates an expected call of CheckDiagnostics.
+func (mr *MockDiagnosticsMockRecorder) CheckDiagnostics(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckDiagnostics", reflect.TypeOf((*MockDiagnostics)(nil).CheckDiagnostics), ctx)
+}
+
+// MockHolds is a mock of Holds interface.
+type MockHolds struct {
+	ctrl     *gomock.Controller
+	recorder *MockHoldsMockRecorder
+}
+
+// MockHoldsMockRecorder is the mock recorder for MockHolds.
+type MockHoldsMockRecorder struct {
+	mock *MockHolds
+}
+
+// NewMockHolds creates a new mock instance.
+func NewMockHolds(ctrl *gomock.Controller) *MockHolds {
+	mock := &MockHolds{ctrl: ctrl}
+	mock.recorder = &MockHoldsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockHolds) EXPECT() *MockHoldsMockRecorder {
+	return m.recorder
+}
+
+// CaptureHold mocks base method.
+func (m *MockHolds) CaptureHold(ctx context.Context, walletID, holdID uuid.UUID, req domain.CaptureRequest) (domain.HoldCaptureResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CaptureHold", ctx, walletID, holdID, req)
+	ret0, _ := ret[0].(domain.HoldCaptureResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CaptureHold indicates an expected call of CaptureHold.
+func (mr *MockHoldsMockRecorder) CaptureHold(ctx, walletID, holdID, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CaptureHold", reflect.TypeOf((*MockHolds)(nil).CaptureHold), ctx, walletID, holdID, req)
+}
+
+// CreateHold mocks base method.
+func (m *MockHolds) CreateHold(ctx context.Context, walletID uuid.UUID, req domain.HoldRequest) (domain.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateHold", ctx, walletID, req)
+	ret0, _ := ret[0].(domain.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateHold indicates an expected call of CreateHold.
+func (mr *MockHoldsMockRecorder) CreateHold(ctx, walletID, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateHold", reflect.TypeOf((*MockHolds)(nil).CreateHold), ctx, walletID, req)
+}
+
+// ExpireHolds mocks base method.
+func (m *MockHolds) ExpireHolds(ctx context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExpireHolds", ctx)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExpireHolds indicates an expected call of ExpireHolds.
+func (mr *MockHoldsMockRecorder) ExpireHolds(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExpireHolds", reflect.TypeOf((*MockHolds)(nil).ExpireHolds), ctx)
+}
+
+// ReleaseHold mocks base method.
+func (m *MockHolds) ReleaseHold(ctx context.Context, walletID, holdID uuid.UUID) (domain.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseHold", ctx, walletID, holdID)
+	ret0, _ := ret[0].(domain.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReleaseHold indicates an expected call of ReleaseHold.
+func (mr *MockHoldsMockRecorder) ReleaseHold(ctx, walletID, holdID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseHold", reflect.TypeOf((*MockHolds)(nil).ReleaseHold), ctx, walletID, holdID)
+}
+
+// MockGrants is a mock of Grants interface.
+type MockGrants struct {
+	ctrl     *gomock.Controller
+	recorder *MockGrantsMockRecorder
+}
+
+// MockGrantsMockRecorder is the mock recorder for MockGrants.
+type MockGrantsMockRecorder struct {
+	mock *MockGrants
+}
+
+// NewMockGrants creates a new mock instance.
+func NewMockGrants(ctrl *gomock.Controller) *MockGrants {
+	mock := &MockGrants{ctrl: ctrl}
+	mock.recorder = &MockGrantsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockGrants) EXPECT() *MockGrantsMockRecorder {
+	return m.recorder
+}
+
+// GrantAccess mocks base method.
+func (m *MockGrants) GrantAccess(ctx context.Context, walletID uuid.UUID, subject string, req domain.WalletGrantRequest) (domain.WalletGrant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GrantAccess", ctx, walletID, subject, req)
+	ret0, _ := ret[0].(domain.WalletGrant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GrantAccess indicates an expected call of GrantAccess.
+func (mr *MockGrantsMockRecorder) GrantAccess(ctx, walletID, subject, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GrantAccess", reflect.TypeOf((*MockGrants)(nil).GrantAccess), ctx, walletID, subject, req)
+}
+
+// ListGrants mocks base method.
+func (m *MockGrants) ListGrants(ctx context.Context, walletID uuid.UUID) (domain.WalletGrants, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListGrants", ctx, walletID)
+	ret0, _ := ret[0].(domain.WalletGrants)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListGrants indicates an expected call of ListGrants.
+func (mr *MockGrantsMockRecorder) ListGrants(ctx, walletID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListGrants", reflect.TypeOf((*MockGrants)(nil).ListGrants), ctx, walletID)
+}
+
+// RevokeAccess mocks base method.
+func (m *MockGrants) RevokeAccess(ctx context.Context, walletID uuid.UUID, subject string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAccess", ctx, walletID, subject)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeAccess indicates an expected call of RevokeAccess.
+func (mr *MockGrantsMockRecorder) RevokeAccess(ctx, walletID, subject interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAccess", reflect.TypeOf((*MockGrants)(nil).RevokeAccess), ctx, walletID, subject)
+}
+
+// MockLimits is a mock of Limits interface.
+type MockLimits struct {
+	ctrl     *gomock.Controller
+	recorder *MockLimitsMockRecorder
+}
+
+// MockLimitsMockRecorder is the mock recorder for MockLimits.
+type MockLimitsMockRecorder struct {
+	mock *MockLimits
+}
+
+// NewMockLimits creates a new mock instance.
+func NewMockLimits(ctrl *gomock.Controller) *MockLimits {
+	mock := &MockLimits{ctrl: ctrl}
+	mock.recorder = &MockLimitsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLimits) EXPECT() *MockLimitsMockRecorder {
+	return m.recorder
+}
+
+// GetLimits mocks base method.
+func (m *MockLimits) GetLimits(ctx context.Context, walletID uuid.UUID) (domain.WalletLimits, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLimits", ctx, walletID)
+	ret0, _ := ret[0].(domain.WalletLimits)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLimits indicates an expected call of GetLimits.
+func (mr *MockLimitsMockRecorder) GetLimits(ctx, walletID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLimits", reflect.TypeOf((*MockLimits)(nil).GetLimits), ctx, walletID)
+}
+
+// SetLimits mocks base method.
+func (m *MockLimits) SetLimits(ctx context.Context, walletID uuid.UUID, req domain.WalletLimitsRequest) (domain.WalletLimits, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetLimits", ctx, walletID, req)
+	ret0, _ := ret[0].(domain.WalletLimits)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetLimits indicates an expected call of SetLimits.
+func (mr *MockLimitsMockRecorder) SetLimits(ctx, walletID, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetLimits", reflect.TypeOf((*MockLimits)(nil).SetLimits), ctx, walletID, req)
+}
+
+// MockAuth is a mock of Auth interface.
+type MockAuth struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuthMockRecorder
+}
+
+// MockAuthMockRecorder is the mock recorder for MockAuth.
+type MockAuthMockRecorder struct {
+	mock *MockAuth
+}
+
+// NewMockAuth creates a new mock instance.
+func NewMockAuth(ctrl *gomock.Controller) *MockAuth {
+	mock := &MockAuth{ctrl: ctrl}
+	mock.recorder = &MockAuthMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuth) EXPECT() *MockAuthMockRecorder {
+	return m.recorder
+}
+
+// Authenticate mocks base method.
+func (m *MockAuth) Authenticate(ctx context.Context, credentials domain.Credentials) (domain.Principal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Authenticate", ctx, credentials)
+	ret0, _ := ret[0].(domain.Principal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Authenticate indicates an expected call of Authenticate.
+func (mr *MockAuthMockRecorder) Authenticate(ctx, credentials interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authenticate", reflect.TypeOf((*MockAuth)(nil).Authenticate), ctx, credentials)
+}
+
+// IssueAPIKey mocks base method.
+func (m *MockAuth) IssueAPIKey(ctx context.Context, req domain.APIKeyRequest) (domain.IssuedAPIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IssueAPIKey", ctx, req)
+	ret0, _ := ret[0].(domain.IssuedAPIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IssueAPIKey indicates an expected call of IssueAPIKey.
+func (mr *MockAuthMockRecorder) IssueAPIKey(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IssueAPIKey", reflect.TypeOf((*MockAuth)(nil).IssueAPIKey), ctx, req)
+}
+
+// VerifySignature mocks base method.
+func (m *MockAuth) VerifySignature(ctx context.Context, req domain.SignedRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifySignature", ctx, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// VerifySignature indicates an expected call of VerifySignature.
+func (mr *MockAuthMockRecorder) VerifySignature(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifySignature", reflect.TypeOf((*MockAuth)(nil).VerifySignature), ctx, req)
+}
+
+// MockRateLimits is a mock of RateLimits interface.
+type MockRateLimits struct {
+	ctrl     *gomock.Controller
+	recorder *MockRateLimitsMockRecorder
+}
+
+// MockRateLimitsMockRecorder is the mock recorder for MockRateLimits.
+type MockRateLimitsMockRecorder struct {
+	mock *MockRateLimits
+}
+
+// NewMockRateLimits creates a new mock instance.
+func NewMockRateLimits(ctrl *gomock.Controller) *MockRateLimits {
+	mock := &MockRateLimits{ctrl: ctrl}
+	mock.recorder = &MockRateLimitsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRateLimits) EXPECT() *MockRateLimitsMockRecorder {
+	return m.recorder
+}
+
+// AllowRequest mocks base method.
+func (m *MockRateLimits) AllowRequest(ctx context.Context) domain.RateDecision {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AllowRequest", ctx)
+	ret0, _ := ret[0].(domain.RateDecision)
+	return ret0
+}
+
+// AllowRequest indicates an expected call of AllowRequest.
+func (mr *MockRateLimitsMockRecorder) AllowRequest(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AllowRequest", reflect.TypeOf((*MockRateLimits)(nil).AllowRequest), ctx)
+}
+
+// AllowWalletDebit mocks base method.
+func (m *MockRateLimits) AllowWalletDebit(ctx context.Context, walletID uuid.UUID) domain.RateDecision {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AllowWalletDebit", ctx, walletID)
+	ret0, _ := ret[0].(domain.RateDecision)
+	return ret0
+}
+
+// AllowWalletDebit indicates an expected call of AllowWalletDebit.
+func (mr *MockRateLimitsMockRecorder) AllowWalletDebit(ctx, walletID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AllowWalletDebit", reflect.TypeOf((*MockRateLimits)(nil).AllowWalletDebit), ctx, walletID)
+}
+
+// MockFX is a mock of FX interface.
+type MockFX struct {
+	ctrl     *gomock.Controller
+	recorder *MockFXMockRecorder
+}
+
+// MockFXMockRecorder is the mock recorder for MockFX.
+type MockFXMockRecorder struct {
+	mock *MockFX
+}
+
+// NewMockFX creates a new mock instance.
+func NewMockFX(ctrl *gomock.Controller) *MockFX {
+	mock := &MockFX{ctrl: ctrl}
+	mock.recorder = &MockFXMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFX) EXPECT() *MockFXMockRecorder {
+	return m.recorder
+}
+
+// CreateQuote mocks base method.
+func (m *MockFX) CreateQuote(ctx context.Context, req domain.FXQuoteRequest) (domain.FXQuote, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateQuote", ctx, req)
+	ret0, _ := ret[0].(domain.FXQuote)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateQuote indicates an expected call of CreateQuote.
+func (mr *MockFXMockRecorder) CreateQuote(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateQuote", reflect.TypeOf((*MockFX)(nil).CreateQuote), ctx, req)
+}
+
+// MockExchangeRateProvider is a mock of ExchangeRateProvider interface.
+type MockExchangeRateProvider struct {
+	ctrl     *gomock.Controller
+	recorder *MockExchangeRateProviderMockRecorder
+}
+
+// MockExchangeRateProviderMockRecorder is the mock recorder for MockExchangeRateProvider.
+type MockExchangeRateProviderMockRecorder struct {
+	mock *MockExchangeRateProvider
+}
+
+// NewMockExchangeRateProvider creates a new mock instance.
+func NewMockExchangeRateProvider(ctrl *gomock.Controller) *MockExchangeRateProvider {
+	mock := &MockExchangeRateProvider{ctrl: ctrl}
+	mock.recorder = &MockExchangeRateProviderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockExchangeRateProvider) EXPECT() *MockExchangeRateProviderMockRecorder {
+	return m.recorder
+}
+
+// GetRate mocks base method.
+func (m *MockExchangeRateProvider) GetRate(ctx context.Context, from, to string) (decimal.Decimal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRate", ctx, from, to)
+	ret0, _ := ret[0].(decimal.Decimal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRate indicates an expected call of GetRate.
+func (mr *MockExchangeRateProviderMockRecorder) GetRate(ctx, from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRate", reflect.TypeOf((*MockExchangeRateProvider)(nil).GetRate), ctx, from, to)
+}
+
+// MockTokenVerifier is a mock of TokenVerifier interface.
+type MockTokenVerifier struct {
+	ctrl     *gomock.Controller
+	recorder *MockTokenVerifierMockRecorder
+}
+
+// MockTokenVerifierMockRecorder is the mock recorder for MockTokenVerifier.
+type MockTokenVerifierMockRecorder struct {
+	mock *MockTokenVerifier
+}
+
+// NewMockTokenVerifier creates a new mock instance.
+func NewMockTokenVerifier(ctrl *gomock.Controller) *MockTokenVerifier {
+	mock := &MockTokenVerifier{ctrl: ctrl}
+	mock.recorder = &MockTokenVerifierMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTokenVerifier) EXPECT() *MockTokenVerifierMockRecorder {
+	return m.recorder
+}
+
+// Verify mocks base method.
+func (m *MockTokenVerifier) Verify(token string) (domain.Principal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Verify", token)
+	ret0, _ := ret[0].(domain.Principal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Verify indicates an expected call of Verify.
+func (mr *MockTokenVerifierMockRecorder) Verify(token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Verify", reflect.TypeOf((*MockTokenVerifier)(nil).Verify), token)
+}
+
+// MockRequestVerifier is a mock of RequestVerifier interface.
+type MockRequestVerifier struct {
+	ctrl     *gomock.Controller
+	recorder *MockRequestVerifierMockRecorder
+}
+
+// MockRequestVerifierMockRecorder is the mock recorder for MockRequestVerifier.
+type MockRequestVerifierMockRecorder struct {
+	mock *MockRequestVerifier
+}
+
+// NewMockRequestVerifier creates a new mock instance.
+func NewMockRequestVerifier(ctrl *gomock.Controller) *MockRequestVerifier {
+	mock := &MockRequestVerifier{ctrl: ctrl}
+	mock.recorder = &MockRequestVerifierMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRequestVerifier) EXPECT() *MockRequestVerifierMockRecorder {
+	return m.recorder
+}
+
+// Requires mocks base method.
+func (m *MockRequestVerifier) Requires(subject string) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Requires", subject)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// Requires indicates an expected call of Requires.
+func (mr *MockRequestVerifierMockRecorder) Requires(subject interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Requires", reflect.TypeOf((*MockRequestVerifier)(nil).Requires), subject)
+}
+
+// Verify mocks base method.
+func (m *MockRequestVerifier) Verify(subject string, req domain.SignedRequest) (time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Verify", subject, req)
+	ret0, _ := ret[0].(time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Verify indicates an expected call of Verify.
+func (mr *MockRequestVerifierMockRecorder) Verify(subject, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Verify", reflect.TypeOf((*MockRequestVerifier)(nil).Verify), subject, req)
+}
+
+// MockBalanceStore is a mock of BalanceStore interface.
+type MockBalanceStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockBalanceStoreMockRecorder
+}
+
+// MockBalanceStoreMockRecorder is the mock recorder for MockBalanceStore.
+type MockBalanceStoreMockRecorder struct {
+	mock *MockBalanceStore
+}
+
+// NewMockBalanceStore creates a new mock instance.
+func NewMockBalanceStore(ctrl *gomock.Controller) *MockBalanceStore {
+	mock := &MockBalanceStore{ctrl: ctrl}
+	mock.recorder = &MockBalanceStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBalanceStore) EXPECT() *MockBalanceStoreMockRecorder {
+	return m.recorder
+}
+
+// UpdateBalance mocks base method.
+func (m *MockBalanceStore) UpdateBalance(ctx context.Context, update domain.BalanceUpdate) (domain.Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateBalance", ctx, update)
+	ret0, _ := ret[0].(domain.Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateBalance indicates an expected call of UpdateBalance.
+func (mr *MockBalanceStoreMockRecorder) UpdateBalance(ctx, update interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateBalance", reflect.TypeOf((*MockBalanceStore)(nil).UpdateBalance), ctx, update)
+}
+
+// UpdateBalanceBatch mocks base method.
+func (m *MockBalanceStore) UpdateBalanceBatch(ctx context.Context, walletID uuid.UUID, updates []domain.BalanceUpdate) ([]domain.BalanceUpdateResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateBalanceBatch", ctx, walletID, updates)
+	ret0, _ := ret[0].([]domain.BalanceUpdateResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateBalanceBatch indicates an expected call of UpdateBalanceBatch.
+func (mr *MockBalanceStoreMockRecorder) UpdateBalanceBatch(ctx, walletID, updates interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateBalanceBatch", reflect.TypeOf((*MockBalanceStore)(nil).UpdateBalanceBatch), ctx, walletID, updates)
+}
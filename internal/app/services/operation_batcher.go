@@ -0,0 +1,159 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"wallet-app/internal/app/app_errors"
+	"wallet-app/internal/app/domain"
+)
+
+// batcherMetrics — счетчики OperationBatcher, доступные через /debug/vars:
+// batches — выполненные пакеты, operations — операции в них, fallbacks — пакеты, разобранные
+// на отдельные операции после ошибки
+var batcherMetrics = expvar.NewMap("operation_batcher")
+
+// OperationBatcher объединяет одновременные операции над одним кошельком в пакеты, каждый из которых
+// применяется одной транзакцией. Пока выполняется пакет, новые операции кошелька копятся в очереди и
+// уходят следующим пакетом, поэтому на горячий кошелек приходится не больше одного соединения с базой.
+type OperationBatcher struct {
+	store    BalanceStore
+	maxBatch int
+	linger   time.Duration
+	timeout  time.Duration
+
+	mu     sync.Mutex
+	queues map[uuid.UUID]*operationQueue
+}
+
+type operationQueue struct {
+	pending []*batchedOperation
+}
+
+type batchedOperation struct {
+	ctx    context.Context
+	update domain.BalanceUpdate
+	result chan domain.BalanceUpdateResult
+}
+
+// NewOperationBatcher создает OperationBatcher: в пакете не больше maxBatch операций,
+// перед сбором пакета очередь ждет linger, пакет выполняется не дольше timeout
+func NewOperationBatcher(store BalanceStore, maxBatch int, linger, timeout time.Duration) *OperationBatcher {
+	if maxBatch < 1 {
+		maxBatch = 1
+	}
+	return &OperationBatcher{
+		store:    store,
+		maxBatch: maxBatch,
+		linger:   linger,
+		timeout:  timeout,
+		queues:   make(map[uuid.UUID]*operationQueue),
+	}
+}
+
+// Submit ставит операцию в очередь ее кошелька и ждет результата.
+// Операция, контекст которой отменен до начала пакета, не выполняется. Начатый пакет
+// доводится до конца независимо от контекста запроса, чтобы результат всегда отражал состояние базы.
+func (b *OperationBatcher) Submit(ctx context.Context, update domain.BalanceUpdate) (domain.Transaction, error) {
+	op := &batchedOperation{ctx: ctx, update: update, result: make(chan domain.BalanceUpdateResult, 1)}
+
+	b.mu.Lock()
+	queue, ok := b.queues[update.WalletID]
+	if !ok {
+		queue = &operationQueue{}
+		b.queues[update.WalletID] = queue
+		go b.run(update.WalletID, queue)
+	}
+	queue.pending = append(queue.pending, op)
+	b.mu.Unlock()
+
+	result := <-op.result
+	return result.Transaction, result.Err
+}
+
+// run выполняет пакеты кошелька, пока в его очереди есть операции, и затем удаляет очередь
+func (b *OperationBatcher) run(walletID uuid.UUID, queue *operationQueue) {
+	for {
+		if b.linger > 0 {
+			time.Sleep(b.linger)
+		}
+
+		b.mu.Lock()
+		if len(queue.pending) == 0 {
+			delete(b.queues, walletID)
+			b.mu.Unlock()
+			return
+		}
+		n := min(len(queue.pending), b.maxBatch)
+		ops := queue.pending[:n:n]
+		queue.pending = queue.pending[n:]
+		b.mu.Unlock()
+
+		b.process(walletID, ops)
+	}
+}
+
+// process выполняет один пакет и раздает результаты операциям
+func (b *OperationBatcher) process(walletID uuid.UUID, ops []*batchedOperation) {
+	live := make([]*batchedOperation, 0, len(ops))
+	for _, op := range ops {
+		if err := op.ctx.Err(); err != nil {
+			op.result <- domain.BalanceUpdateResult{Err: err}
+			continue
+		}
+		live = append(live, op)
+	}
+	if len(live) == 0 {
+		return
+	}
+
+	updates := make([]domain.BalanceUpdate, len(live))
+	for i, op := range live {
+		updates[i] = op.update
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), b.timeout)
+	defer cancel()
+
+	batcherMetrics.Add("batches", 1)
+	batcherMetrics.Add("operations", int64(len(live)))
+
+	results, err := b.store.UpdateBalanceBatch(ctx, walletID, updates)
+	if err != nil && len(live) > 1 && !failsWholeBatch(err) {
+		// Ошибка могла быть вызвана одной из операций: выполняем их по отдельности,
+		// чтобы она не затронула остальные
+		batcherMetrics.Add("fallbacks", 1)
+		for _, op := range live {
+			var result domain.BalanceUpdateResult
+			result.Transaction, result.Err = b.store.UpdateBalance(ctx, op.update)
+			op.result <- result
+		}
+		return
+	}
+
+	for i, op := range live {
+		if err != nil {
+			op.result <- domain.BalanceUpdateResult{Err: err}
+			continue
+		}
+		op.result <- results[i]
+	}
+}
+
+// failsWholeBatch сообщает, что ошибка относится к кошельку или базе, а не к отдельной операции,
+// и повтор операций по одной ничего не изменит
+func failsWholeBatch(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return true
+	}
+	switch app_errors.As(err).Kind {
+	case app_errors.KindNotFound, app_errors.KindUnavailable:
+		return true
+	}
+	return false
+}
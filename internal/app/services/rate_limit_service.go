@@ -0,0 +1,78 @@
+package services
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	logger "github.com/sirupsen/logrus"
+
+	"wallet-app/internal/app/domain"
+	"wallet-app/internal/app/repository"
+	"wallet-app/internal/configs"
+)
+
+type RateLimitService struct {
+	limiter repository.RateLimits
+
+	client       domain.RateLimit
+	clients      map[string]domain.RateLimit
+	walletDebits domain.RateLimit
+	wallets      map[uuid.UUID]domain.RateLimit
+}
+
+// NewRateLimitService создает RateLimitService с ограничениями из cfg; cfg проверяется при загрузке конфигурации
+func NewRateLimitService(limiter repository.RateLimits, cfg configs.RateLimitConfig) *RateLimitService {
+	s := &RateLimitService{
+		limiter:      limiter,
+		client:       rateLimit(cfg.Client),
+		clients:      make(map[string]domain.RateLimit, len(cfg.Clients)),
+		walletDebits: rateLimit(cfg.WalletDebits),
+		wallets:      make(map[uuid.UUID]domain.RateLimit, len(cfg.Wallets)),
+	}
+	for _, client := range cfg.Clients {
+		s.clients[client.Subject] = rateLimit(client.RateLimitRule)
+	}
+	for _, wallet := range cfg.Wallets {
+		s.wallets[uuid.MustParse(wallet.WalletID)] = rateLimit(wallet.RateLimitRule)
+	}
+	return s
+}
+
+func rateLimit(rule configs.RateLimitRule) domain.RateLimit {
+	return domain.RateLimit{Requests: rule.Requests, Per: rule.Per, Burst: rule.Burst}
+}
+
+// AllowRequest учитывает запрос клиента из контекста в его ограничении
+func (s *RateLimitService) AllowRequest(ctx context.Context) domain.RateDecision {
+	principal, _ := domain.PrincipalFromContext(ctx)
+
+	limit, ok := s.clients[principal.Subject]
+	if !ok {
+		limit = s.client
+	}
+	return s.take(ctx, "client:"+principal.Subject, limit)
+}
+
+// AllowWalletDebit учитывает списание с кошелька в его ограничении
+func (s *RateLimitService) AllowWalletDebit(ctx context.Context, walletID uuid.UUID) domain.RateDecision {
+	limit, ok := s.wallets[walletID]
+	if !ok {
+		limit = s.walletDebits
+	}
+	return s.take(ctx, "wallet_debits:"+walletID.String(), limit)
+}
+
+// take берет токен из корзины. Если хранилище корзин недоступно, запрос пропускается:
+// ограничение частоты защищает сервис, но не должно само останавливать его работу.
+func (s *RateLimitService) take(ctx context.Context, key string, limit domain.RateLimit) domain.RateDecision {
+	if limit.Unlimited() {
+		return domain.RateDecision{Allowed: true}
+	}
+
+	decision, err := s.limiter.TakeToken(ctx, key, limit)
+	if err != nil {
+		logger.WithField("bucket", key).Warnf("Rate limiter is unavailable, request allowed: %v", err)
+		return domain.RateDecision{Allowed: true}
+	}
+	return decision
+}
@@ -2,26 +2,148 @@ package services
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
+	logger "github.com/sirupsen/logrus"
 
 	"wallet-app/internal/app/domain"
 	"wallet-app/internal/app/repository"
+	"wallet-app/internal/configs"
 )
 
 type Wallet interface {
-	CreateWallet(ctx context.Context) (domain.Wallet, error)
-	ProcessOperation(ctx context.Context, op domain.WalletOperation) error
-	GetBalance(ctx context.Context, walletID uuid.UUID) (decimal.Decimal, error)
+	CreateWallet(ctx context.Context, req domain.CreateWalletRequest) (domain.Wallet, error)
+	ProcessOperation(ctx context.Context, op domain.WalletOperation) (domain.Transaction, error)
+	Transfer(ctx context.Context, op domain.TransferOperation) (domain.TransferResult, error)
+	GetBalance(ctx context.Context, walletID uuid.UUID) (domain.WalletBalance, error)
+	ListWalletsByOwner(ctx context.Context, ownerID string) (domain.WalletsByOwner, error)
+	ListTransactions(ctx context.Context, walletID uuid.UUID, filter domain.TransactionFilter) (domain.TransactionPage, error)
+}
+
+// WalletAdmin — административное управление состоянием кошельков
+type WalletAdmin interface {
+	SetWalletStatus(ctx context.Context, walletID uuid.UUID, status domain.WalletStatus, req domain.WalletStatusRequest) (domain.WalletState, error)
+}
+
+type Ledger interface {
+	VerifyLedger(ctx context.Context) (domain.LedgerReport, error)
+}
+
+// Diagnostics — доступ к служебным данным процесса
+type Diagnostics interface {
+	CheckDiagnostics(ctx context.Context) error
+}
+
+type Holds interface {
+	CreateHold(ctx context.Context, walletID uuid.UUID, req domain.HoldRequest) (domain.Hold, error)
+	CaptureHold(ctx context.Context, walletID, holdID uuid.UUID, req domain.CaptureRequest) (domain.HoldCaptureResult, error)
+	ReleaseHold(ctx context.Context, walletID, holdID uuid.UUID) (domain.Hold, error)
+	ExpireHolds(ctx context.Context) (int, error)
+}
+
+// Grants — управление доступом клиентов к кошельку
+type Grants interface {
+	ListGrants(ctx context.Context, walletID uuid.UUID) (domain.WalletGrants, error)
+	GrantAccess(ctx context.Context, walletID uuid.UUID, subject string, req domain.WalletGrantRequest) (domain.WalletGrant, error)
+	RevokeAccess(ctx context.Context, walletID uuid.UUID, subject string) error
+}
+
+// Limits — ограничения операций кошельков
+type Limits interface {
+	GetLimits(ctx context.Context, walletID uuid.UUID) (domain.WalletLimits, error)
+	SetLimits(ctx context.Context, walletID uuid.UUID, req domain.WalletLimitsRequest) (domain.WalletLimits, error)
+}
+
+// Auth — аутентификация клиентов и выпуск ключей API
+type Auth interface {
+	Authenticate(ctx context.Context, credentials domain.Credentials) (domain.Principal, error)
+	IssueAPIKey(ctx context.Context, req domain.APIKeyRequest) (domain.IssuedAPIKey, error)
+	VerifySignature(ctx context.Context, req domain.SignedRequest) error
+}
+
+// RateLimits — ограничение частоты запросов клиентов и списаний с кошельков
+type RateLimits interface {
+	AllowRequest(ctx context.Context) domain.RateDecision
+	AllowWalletDebit(ctx context.Context, walletID uuid.UUID) domain.RateDecision
+}
+
+type FX interface {
+	CreateQuote(ctx context.Context, req domain.FXQuoteRequest) (domain.FXQuote, error)
+}
+
+// ExchangeRateProvider — источник курсов валют: сколько единиц to стоит одна единица from
+type ExchangeRateProvider interface {
+	GetRate(ctx context.Context, from, to string) (decimal.Decimal, error)
+}
+
+// TokenVerifier проверяет токен JWT и возвращает клиента, от имени которого он выпущен
+type TokenVerifier interface {
+	Verify(token string) (domain.Principal, error)
+}
+
+// RequestVerifier проверяет подписи запросов ключами клиентов
+type RequestVerifier interface {
+	// Requires сообщает, обязан ли клиент подписывать запросы
+	Requires(subject string) bool
+	// Verify проверяет подпись и метку времени и возвращает, до какого момента хранить nonce запроса
+	Verify(subject string, req domain.SignedRequest) (time.Time, error)
+}
+
+// BalanceStore — хранилище, к которому OperationBatcher применяет операции над балансом
+type BalanceStore interface {
+	UpdateBalance(ctx context.Context, update domain.BalanceUpdate) (domain.Transaction, error)
+	UpdateBalanceBatch(ctx context.Context, walletID uuid.UUID, updates []domain.BalanceUpdate) ([]domain.BalanceUpdateResult, error)
 }
 
 type Service struct {
 	Wallet
+	WalletAdmin
+	Ledger
+	Diagnostics
+	Holds
+	FX
+	Auth
+	Grants
+	Limits
+	// RateLimits — ограничение частоты запросов; nil — запросы не ограничиваются
+	RateLimits
 }
 
-func NewService(repo *repository.WalletRepository) *Service {
+func NewService(
+	repo repository.Storage,
+	holdsCfg configs.HoldsConfig,
+	fxCfg configs.FXConfig,
+	batchingCfg configs.BatchingConfig,
+	limitsCfg configs.LimitsConfig,
+	rates ExchangeRateProvider,
+	tokens TokenVerifier,
+	signatures RequestVerifier,
+) *Service {
+	rounding, err := domain.ParseRoundingMode(fxCfg.Rounding)
+	if err != nil {
+		logger.Warnf("Unknown fx rounding mode '%s', using %s", fxCfg.Rounding, domain.RoundHalfEven)
+		rounding = domain.RoundHalfEven
+	}
+
+	var batcher *OperationBatcher
+	if batchingCfg.Enabled {
+		batcher = NewOperationBatcher(repo, batchingCfg.MaxBatchSize, batchingCfg.Linger, batchingCfg.Timeout)
+	}
+
+	authz := NewAuthorizer(repo)
+	limits := NewLimitService(repo, authz, limitsCfg)
+
 	return &Service{
-		Wallet: NewWalletService(repo),
+		Wallet:      NewWalletService(repo, authz, limits, rounding, batcher),
+		WalletAdmin: NewWalletStatusService(repo, authz),
+		Ledger:      NewLedgerService(repo, authz),
+		Diagnostics: NewDiagnosticsService(authz),
+		Holds:       NewHoldService(repo, authz, limits, holdsCfg),
+		FX:          NewFXService(repo, rates, fxCfg),
+		Auth:        NewAuthService(repo, repo, tokens, signatures),
+		Grants:      NewGrantService(repo, authz),
+		Limits:      limits,
 	}
 }
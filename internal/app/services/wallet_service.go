@@ -5,42 +5,174 @@ import (
 	"fmt"
 
 	"github.com/google/uuid"
-	"github.com/shopspring/decimal"
 
 	"wallet-app/internal/app/domain"
 	"wallet-app/internal/app/repository"
 )
 
 type WalletService struct {
-	repo *repository.WalletRepository
+	repo     repository.Wallets
+	authz    *Authorizer
+	limits   *LimitService
+	rounding domain.RoundingMode
+	// Объединение одновременных операций над кошельком в пакеты; nil — каждая операция в своей транзакции
+	batcher *OperationBatcher
 }
 
-func NewWalletService(repo *repository.WalletRepository) *WalletService {
-	return &WalletService{repo: repo}
+func NewWalletService(repo repository.Wallets, authz *Authorizer, limits *LimitService, rounding domain.RoundingMode, batcher *OperationBatcher) *WalletService {
+	return &WalletService{repo: repo, authz: authz, limits: limits, rounding: rounding, batcher: batcher}
 }
 
-// CreateWallet создает новый кошелек с нулевым балансом
-func (s *WalletService) CreateWallet(ctx context.Context) (domain.Wallet, error) {
-	return s.repo.CreateWallet(ctx)
+// CreateWallet создает новый кошелек с нулевым балансом.
+// Если владелец не указан, владельцем становится клиент, создающий кошелек;
+// создать кошелек другому владельцу может только клиент с ролью operator или admin.
+func (s *WalletService) CreateWallet(ctx context.Context, req domain.CreateWalletRequest) (domain.Wallet, error) {
+	if principal, ok := domain.PrincipalFromContext(ctx); ok && req.OwnerID == "" {
+		req.OwnerID = principal.Subject
+	}
+	if err := s.authz.CheckOwner(ctx, req.OwnerID, domain.PermissionOperate); err != nil {
+		return domain.Wallet{}, err
+	}
+
+	return s.repo.CreateWallet(ctx, req)
 }
 
-// ProcessOperation обрабатывает операцию пополнения или снятия средств
-func (s *WalletService) ProcessOperation(ctx context.Context, op domain.WalletOperation) error {
+// ListWalletsByOwner возвращает все кошельки владельца
+func (s *WalletService) ListWalletsByOwner(ctx context.Context, ownerID string) (domain.WalletsByOwner, error) {
+	if err := s.authz.CheckOwner(ctx, ownerID, domain.PermissionRead); err != nil {
+		return domain.WalletsByOwner{}, err
+	}
+
+	wallets, err := s.repo.ListWalletsByOwner(ctx, ownerID)
+	if err != nil {
+		return domain.WalletsByOwner{}, err
+	}
+
+	return domain.WalletsByOwner{OwnerID: ownerID, Wallets: wallets}, nil
+}
+
+// ProcessOperation обрабатывает операцию пополнения, снятия, сторно или возврата средств
+// и возвращает созданную запись истории операций
+func (s *WalletService) ProcessOperation(ctx context.Context, op domain.WalletOperation) (domain.Transaction, error) {
+	if err := s.authz.CheckWallet(ctx, op.WalletID, domain.PermissionOperate); err != nil {
+		return domain.Transaction{}, err
+	}
+
 	signedAmount, err := op.GetSignedAmount()
 	if err != nil {
-		return fmt.Errorf("failed to get signed amount: %w", err)
+		return domain.Transaction{}, fmt.Errorf("failed to get signed amount: %w", err)
+	}
+
+	update := domain.BalanceUpdate{
+		WalletID:              op.WalletID,
+		OperationType:         op.OperationType,
+		Amount:                signedAmount,
+		Currency:              op.Currency,
+		OriginalTransactionID: op.TransactionID,
+		ExpectedVersion:       op.ExpectedVersion,
+	}
+
+	// Отпечаток запроса нужен, чтобы отличить повтор от повторного использования ключа с другими данными
+	if op.IdempotencyKey != "" {
+		// Ключи выбирают клиенты, поэтому ключ одного клиента не должен находить ответы другого
+		principal, _ := domain.PrincipalFromContext(ctx)
+		update.IdempotencyKey = op.IdempotencyKey
+		update.IdempotencySubject = principal.Subject
+		update.Fingerprint, err = op.Fingerprint()
+		if err != nil {
+			return domain.Transaction{}, fmt.Errorf("failed to get request fingerprint: %w", err)
+		}
+	}
+
+	if update.Limits, err = s.limits.Effective(ctx, op.WalletID); err != nil {
+		return domain.Transaction{}, err
+	}
+
+	// Сторно и возврат исправляют уже проверенную операцию, поэтому проверяются только потолком баланса:
+	// возврат снятия зачисляет средства и не должен поднимать баланс выше него
+	if op.IsCorrection() {
+		update.Limits = domain.TransactionLimits{MaxBalance: update.Limits.MaxBalance}
+		return s.repo.ApplyCorrection(ctx, update)
 	}
 
 	// Обновляем баланс
-	return s.repo.UpdateBalance(ctx, op.WalletID, signedAmount)
+	if s.batcher != nil {
+		return s.batcher.Submit(ctx, update)
+	}
+	return s.repo.UpdateBalance(ctx, update)
+}
+
+// Transfer переводит средства между кошельками атомарно.
+// Если указана котировка, сумма зачисления пересчитывается по ее курсу с округлением до точности валюты получателя.
+// Доступ проверяется только к кошельку-отправителю: зачислить средства можно на любой кошелек.
+func (s *WalletService) Transfer(ctx context.Context, op domain.TransferOperation) (domain.TransferResult, error) {
+	if err := s.authz.CheckWallet(ctx, op.FromWalletID, domain.PermissionOperate); err != nil {
+		return domain.TransferResult{}, err
+	}
+
+	amount, err := op.ParseAmount()
+	if err != nil {
+		return domain.TransferResult{}, fmt.Errorf("failed to parse amount: %w", err)
+	}
+
+	update := domain.TransferUpdate{
+		FromWalletID: op.FromWalletID,
+		ToWalletID:   op.ToWalletID,
+		Amount:       amount,
+		Currency:     op.Currency,
+	}
+
+	if update.FromLimits, err = s.limits.Effective(ctx, op.FromWalletID); err != nil {
+		return domain.TransferResult{}, err
+	}
+	if update.ToLimits, err = s.limits.Effective(ctx, op.ToWalletID); err != nil {
+		return domain.TransferResult{}, err
+	}
+
+	if op.QuoteID != nil {
+		// Срок действия и однократность использования котировки проверяются в транзакции перевода,
+		// чтобы повтор запроса с тем же ключом идемпотентности вернул сохраненный результат
+		quote, err := s.repo.GetQuote(ctx, *op.QuoteID)
+		if err != nil {
+			return domain.TransferResult{}, err
+		}
+
+		conversion, err := quote.Convert(amount, s.rounding)
+		if err != nil {
+			return domain.TransferResult{}, err
+		}
+		update.Conversion = &conversion
+	}
+
+	if op.IdempotencyKey != "" {
+		// Ключи выбирают клиенты, поэтому ключ одного клиента не должен находить ответы другого
+		principal, _ := domain.PrincipalFromContext(ctx)
+		update.IdempotencyKey = op.IdempotencyKey
+		update.IdempotencySubject = principal.Subject
+		update.Fingerprint, err = op.Fingerprint()
+		if err != nil {
+			return domain.TransferResult{}, fmt.Errorf("failed to get request fingerprint: %w", err)
+		}
+	}
+
+	return s.repo.Transfer(ctx, update)
 }
 
 // GetBalance возвращает баланс кошелька
-func (s *WalletService) GetBalance(ctx context.Context, walletID uuid.UUID) (decimal.Decimal, error) {
+func (s *WalletService) GetBalance(ctx context.Context, walletID uuid.UUID) (domain.WalletBalance, error) {
+	if err := s.authz.CheckWallet(ctx, walletID, domain.PermissionRead); err != nil {
+		return domain.WalletBalance{}, err
+	}
+
 	// Получаем баланс кошелька через репозиторий
-	balance, err := s.repo.GetWallet(ctx, walletID)
-	if err != nil {
-		return decimal.Decimal{}, err
+	return s.repo.GetBalance(ctx, walletID)
+}
+
+// ListTransactions возвращает страницу истории операций кошелька
+func (s *WalletService) ListTransactions(ctx context.Context, walletID uuid.UUID, filter domain.TransactionFilter) (domain.TransactionPage, error) {
+	if err := s.authz.CheckWallet(ctx, walletID, domain.PermissionRead); err != nil {
+		return domain.TransactionPage{}, err
 	}
-	return *balance, nil
+
+	return s.repo.ListTransactions(ctx, walletID, filter)
 }
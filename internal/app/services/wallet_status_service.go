@@ -0,0 +1,28 @@
+package services
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"wallet-app/internal/app/domain"
+	"wallet-app/internal/app/repository"
+)
+
+type WalletStatusService struct {
+	repo  repository.Wallets
+	authz *Authorizer
+}
+
+func NewWalletStatusService(repo repository.Wallets, authz *Authorizer) *WalletStatusService {
+	return &WalletStatusService{repo: repo, authz: authz}
+}
+
+// SetWalletStatus переводит кошелек в новое состояние жизненного цикла; доступно только администраторам
+func (s *WalletStatusService) SetWalletStatus(ctx context.Context, walletID uuid.UUID, status domain.WalletStatus, req domain.WalletStatusRequest) (domain.WalletState, error) {
+	if err := s.authz.Check(ctx, domain.PermissionAdmin); err != nil {
+		return domain.WalletState{}, err
+	}
+
+	return s.repo.SetWalletStatus(ctx, walletID, status, req.Reason)
+}
@@ -3,10 +3,13 @@ package configs
 import (
 	"fmt"
 	"log"
+	"os"
 	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/joho/godotenv"
+	"github.com/shopspring/decimal"
 	"github.com/spf13/viper"
 )
 
@@ -28,14 +31,139 @@ type LoggerConfig struct {
 
 // Конфигурация базы данных
 type PostgresConfig struct {
-	Dsn string `mapstructure:"dsn"`
+	Dsn   string      `mapstructure:"dsn"`
+	Retry RetryConfig `mapstructure:"retry"`
+}
+
+// Конфигурация повтора транзакций при ошибках сериализации и взаимоблокировках
+type RetryConfig struct {
+	MaxAttempts int           `mapstructure:"max_attempts"`
+	BaseDelay   time.Duration `mapstructure:"base_delay"`
+	MaxDelay    time.Duration `mapstructure:"max_delay"`
+}
+
+// Конфигурация холдов
+type HoldsConfig struct {
+	DefaultTTL     time.Duration `mapstructure:"default_ttl"`
+	MaxTTL         time.Duration `mapstructure:"max_ttl"`
+	SweepInterval  time.Duration `mapstructure:"sweep_interval"`
+	SweepBatchSize int           `mapstructure:"sweep_batch_size"`
+}
+
+// Конфигурация конвертации валют
+type FXConfig struct {
+	RatesFile string        `mapstructure:"rates_file"`
+	QuoteTTL  time.Duration `mapstructure:"quote_ttl"`
+	Rounding  string        `mapstructure:"rounding"`
+}
+
+// Конфигурация объединения одновременных операций над одним кошельком в пакеты
+type BatchingConfig struct {
+	Enabled      bool          `mapstructure:"enabled"`
+	MaxBatchSize int           `mapstructure:"max_batch_size"`
+	Linger       time.Duration `mapstructure:"linger"`
+	Timeout      time.Duration `mapstructure:"timeout"`
+}
+
+// Конфигурация аутентификации: ключи API хранятся в базе, токены JWT проверяются по ключу из jwt
+type AuthConfig struct {
+	JWT     JWTConfig     `mapstructure:"jwt"`
+	Signing SigningConfig `mapstructure:"signing"`
+}
+
+// Конфигурация проверки токенов JWT; пустой algorithm — токены не принимаются
+type JWTConfig struct {
+	Algorithm     string        `mapstructure:"algorithm"`
+	Secret        string        `mapstructure:"secret"`
+	PublicKeyFile string        `mapstructure:"public_key_file"`
+	Issuer        string        `mapstructure:"issuer"`
+	Audience      string        `mapstructure:"audience"`
+	Leeway        time.Duration `mapstructure:"leeway"`
+}
+
+// Конфигурация подписи запросов HMAC-SHA256. Клиенты, для которых заданы ключи, обязаны подписывать
+// каждый запрос; пустой keys — подписи не принимаются
+type SigningConfig struct {
+	// Допустимое расхождение метки времени запроса с часами сервера
+	MaxSkew time.Duration      `mapstructure:"max_skew"`
+	Keys    []SigningKeyConfig `mapstructure:"keys"`
+}
+
+// Ключ подписи клиента. Чтобы сменить ключ, клиенту на время перехода настраиваются оба ключа.
+type SigningKeyConfig struct {
+	ID string `mapstructure:"id"`
+	// Client — клиент (subject ключа API или токена), которому принадлежит ключ
+	Client string `mapstructure:"client"`
+	Secret string `mapstructure:"secret"`
+	// SecretEnv — переменная окружения с секретом, чтобы не хранить его в файле конфигурации
+	SecretEnv string `mapstructure:"secret_env"`
+}
+
+// Конфигурация ограничения частоты запросов корзинами токенов
+type RateLimitConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Backend — где хранятся корзины: memory — в памяти экземпляра, postgres — общие для всех экземпляров
+	Backend string `mapstructure:"backend"`
+	// Client — ограничение запросов одного клиента; Clients — отдельные ограничения для клиентов
+	Client  RateLimitRule     `mapstructure:"client"`
+	Clients []ClientRateLimit `mapstructure:"clients"`
+	// WalletDebits — ограничение списаний с одного кошелька (снятия, сторно и возвраты, переводы, холды); Wallets — для отдельных кошельков
+	WalletDebits RateLimitRule     `mapstructure:"wallet_debits"`
+	Wallets      []WalletRateLimit `mapstructure:"wallets"`
+}
+
+// Ограничение: requests запросов за per с запасом burst запросов подряд (0 — равен requests);
+// requests = 0 — без ограничения
+type RateLimitRule struct {
+	Requests int           `mapstructure:"requests"`
+	Per      time.Duration `mapstructure:"per"`
+	Burst    int           `mapstructure:"burst"`
+}
+
+// Ограничение запросов клиента subject
+type ClientRateLimit struct {
+	Subject       string `mapstructure:"subject"`
+	RateLimitRule `mapstructure:",squash"`
+}
+
+// Ограничение списаний с кошелька wallet_id
+type WalletRateLimit struct {
+	WalletID      string `mapstructure:"wallet_id"`
+	RateLimitRule `mapstructure:",squash"`
+}
+
+// Конфигурация ограничений операций кошельков; пустая сумма — без ограничения. Уровень описывается отдельно
+// для каждой валюты: кошелек получает ограничения своего уровня (назначается через API) или уровня DefaultTier
+// в валюте кошелька, а собственные ограничения кошелька заменяют ограничения уровня. Кошелек в валюте,
+// для которой уровень не описан, ограничениями уровня не ограничен; назначить ему такой уровень нельзя.
+type LimitsConfig struct {
+	// DefaultTier — уровень кошельков, которым уровень не назначен; пустой — без ограничений
+	DefaultTier string            `mapstructure:"default_tier"`
+	Tiers       []LimitTierConfig `mapstructure:"tiers"`
+}
+
+// Уровень ограничений кошельков в одной валюте
+type LimitTierConfig struct {
+	Name string `mapstructure:"name"`
+	// Currency — валюта кошельков, к которым относятся суммы уровня (ISO 4217)
+	Currency          string `mapstructure:"currency"`
+	MaxTransaction    string `mapstructure:"max_transaction"`
+	DailyWithdrawal   string `mapstructure:"daily_withdrawal"`
+	MonthlyWithdrawal string `mapstructure:"monthly_withdrawal"`
+	MaxBalance        string `mapstructure:"max_balance"`
 }
 
 // Полная конфигурация
 type Config struct {
-	Server   ServerConfig   `mapstructure:"server"`
-	Logging  LoggerConfig   `mapstructure:"logging"`
-	Database PostgresConfig `mapstructure:"database"`
+	Server    ServerConfig    `mapstructure:"server"`
+	Logging   LoggerConfig    `mapstructure:"logging"`
+	Database  PostgresConfig  `mapstructure:"database"`
+	Holds     HoldsConfig     `mapstructure:"holds"`
+	FX        FXConfig        `mapstructure:"fx"`
+	Batching  BatchingConfig  `mapstructure:"batching"`
+	Auth      AuthConfig      `mapstructure:"auth"`
+	RateLimit RateLimitConfig `mapstructure:"rate_limit"`
+	Limits    LimitsConfig    `mapstructure:"limits"`
 }
 
 // LoadConfig загружает конфигурацию из файлов и переменных окружения
@@ -73,6 +201,166 @@ func LoadConfig(path string) (*Config, error) {
 	if config.Server.WriteTimeout <= 0 {
 		config.Server.WriteTimeout = 10 * time.Second
 	}
+	if config.Database.Retry.MaxAttempts <= 0 {
+		config.Database.Retry.MaxAttempts = 5
+	}
+	if config.Database.Retry.BaseDelay <= 0 {
+		config.Database.Retry.BaseDelay = 10 * time.Millisecond
+	}
+	if config.Database.Retry.MaxDelay <= 0 {
+		config.Database.Retry.MaxDelay = 500 * time.Millisecond
+	}
+	if config.Holds.DefaultTTL <= 0 {
+		config.Holds.DefaultTTL = 15 * time.Minute
+	}
+	if config.Holds.MaxTTL <= 0 {
+		config.Holds.MaxTTL = 7 * 24 * time.Hour
+	}
+	if config.Holds.SweepInterval <= 0 {
+		config.Holds.SweepInterval = time.Minute
+	}
+	if config.Holds.SweepBatchSize <= 0 {
+		config.Holds.SweepBatchSize = 100
+	}
+	if config.FX.RatesFile == "" {
+		config.FX.RatesFile = path + "/rates.json"
+	}
+	if config.FX.QuoteTTL <= 0 {
+		config.FX.QuoteTTL = 30 * time.Second
+	}
+	if config.FX.Rounding == "" {
+		config.FX.Rounding = "HALF_EVEN"
+	}
+
+	if config.Batching.MaxBatchSize <= 0 {
+		config.Batching.MaxBatchSize = 100
+	}
+	if config.Batching.MaxBatchSize > 1000 {
+		return nil, fmt.Errorf("invalid batching max_batch_size: %d", config.Batching.MaxBatchSize)
+	}
+	if config.Batching.Linger < 0 {
+		config.Batching.Linger = 0
+	}
+	if config.Batching.Timeout <= 0 {
+		config.Batching.Timeout = 5 * time.Second
+	}
+
+	switch config.Auth.JWT.Algorithm {
+	case "":
+	case "HS256":
+		// Секрет HS256 короче выхода хеш-функции ослабляет подпись
+		if len(config.Auth.JWT.Secret) < 32 {
+			return nil, fmt.Errorf("auth jwt secret must be at least 32 bytes for HS256")
+		}
+	case "RS256":
+		if config.Auth.JWT.PublicKeyFile == "" {
+			return nil, fmt.Errorf("auth jwt public_key_file is required for RS256")
+		}
+	default:
+		return nil, fmt.Errorf("unsupported auth jwt algorithm: %s", config.Auth.JWT.Algorithm)
+	}
+	if config.Auth.JWT.Leeway < 0 {
+		config.Auth.JWT.Leeway = 0
+	}
+
+	if config.Auth.Signing.MaxSkew <= 0 {
+		config.Auth.Signing.MaxSkew = 5 * time.Minute
+	}
+	keyIDs := make(map[string]bool, len(config.Auth.Signing.Keys))
+	for i := range config.Auth.Signing.Keys {
+		key := &config.Auth.Signing.Keys[i]
+		if key.SecretEnv != "" {
+			key.Secret = os.Getenv(key.SecretEnv)
+		}
+		if key.ID == "" || key.Client == "" {
+			return nil, fmt.Errorf("auth signing key %d: id and client are required", i)
+		}
+		// Идентификатор ключа входит в запись nonce, длина которой ограничена
+		if len(key.ID) > 64 {
+			return nil, fmt.Errorf("auth signing key %s: id must be at most 64 bytes", key.ID)
+		}
+		if keyIDs[key.ID] {
+			return nil, fmt.Errorf("auth signing key %s is defined twice", key.ID)
+		}
+		keyIDs[key.ID] = true
+		// Как и для HS256, секрет короче выхода хеш-функции ослабляет подпись
+		if len(key.Secret) < 32 {
+			return nil, fmt.Errorf("auth signing key %s: secret must be at least 32 bytes", key.ID)
+		}
+	}
+
+	if err := validateRateLimit(&config.RateLimit); err != nil {
+		return nil, err
+	}
+	if err := validateLimits(config.Limits); err != nil {
+		return nil, err
+	}
 
 	return &config, nil
 }
+
+// validateRateLimit проверяет ограничения частоты запросов и подставляет хранилище корзин по умолчанию
+func validateRateLimit(cfg *RateLimitConfig) error {
+	switch cfg.Backend {
+	case "":
+		cfg.Backend = "memory"
+	case "memory", "postgres":
+	default:
+		return fmt.Errorf("unsupported rate_limit backend: %s", cfg.Backend)
+	}
+
+	rules := []RateLimitRule{cfg.Client, cfg.WalletDebits}
+	for _, client := range cfg.Clients {
+		if client.Subject == "" {
+			return fmt.Errorf("rate_limit clients: subject is required")
+		}
+		rules = append(rules, client.RateLimitRule)
+	}
+	for _, wallet := range cfg.Wallets {
+		if _, err := uuid.Parse(wallet.WalletID); err != nil {
+			return fmt.Errorf("rate_limit wallets: invalid wallet_id %q", wallet.WalletID)
+		}
+		rules = append(rules, wallet.RateLimitRule)
+	}
+	for _, rule := range rules {
+		if rule.Requests < 0 || rule.Burst < 0 || (rule.Requests > 0 && rule.Per <= 0) {
+			return fmt.Errorf("invalid rate limit: %d requests per %s, burst %d", rule.Requests, rule.Per, rule.Burst)
+		}
+	}
+	return nil
+}
+
+// validateLimits проверяет уровни ограничений операций кошельков
+func validateLimits(cfg LimitsConfig) error {
+	tiers := make(map[string]bool, len(cfg.Tiers))
+	currencies := make(map[[2]string]bool, len(cfg.Tiers))
+	for _, tier := range cfg.Tiers {
+		// Имя уровня хранится в wallet_limits.tier
+		if tier.Name == "" || len(tier.Name) > 64 {
+			return fmt.Errorf("limits tiers: name must be 1 to 64 bytes long")
+		}
+		// Суммы без валюты применились бы к кошелькам любой валюты: 1000 RUB и 1000 JPY — разные ограничения
+		if tier.Currency == "" {
+			return fmt.Errorf("limits tier %s: currency is required", tier.Name)
+		}
+		key := [2]string{tier.Name, strings.ToUpper(tier.Currency)}
+		if currencies[key] {
+			return fmt.Errorf("limits tier %s is defined twice for %s", tier.Name, key[1])
+		}
+		currencies[key] = true
+		tiers[tier.Name] = true
+
+		for _, amount := range []string{tier.MaxTransaction, tier.DailyWithdrawal, tier.MonthlyWithdrawal, tier.MaxBalance} {
+			if amount == "" {
+				continue
+			}
+			if value, err := decimal.NewFromString(amount); err != nil || !value.IsPositive() {
+				return fmt.Errorf("limits tier %s: invalid amount %q", tier.Name, amount)
+			}
+		}
+	}
+	if cfg.DefaultTier != "" && !tiers[cfg.DefaultTier] {
+		return fmt.Errorf("limits default_tier %s is not defined", cfg.DefaultTier)
+	}
+	return nil
+}
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS wallet_transactions (
+   transaction_id UUID PRIMARY KEY,
+   wallet_id UUID NOT NULL REFERENCES wallets (wallet_id),
+   operation_type VARCHAR(32) NOT NULL,
+   amount DECIMAL(20, 2) NOT NULL,
+   balance_after DECIMAL(20, 2) NOT NULL,
+   created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_wallet_transactions_wallet_created
+   ON wallet_transactions (wallet_id, created_at DESC);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_wallet_transactions_wallet_created_id;
+
+CREATE INDEX IF NOT EXISTS idx_wallet_transactions_wallet_created
+   ON wallet_transactions (wallet_id, created_at DESC);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_wallet_transactions_wallet_created;
+
+-- Индекс под стабильную сортировку и курсорную пагинацию истории операций
+CREATE INDEX IF NOT EXISTS idx_wallet_transactions_wallet_created_id
+   ON wallet_transactions (wallet_id, created_at DESC, transaction_id DESC);
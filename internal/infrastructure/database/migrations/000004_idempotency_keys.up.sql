@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+   idempotency_key VARCHAR(255) PRIMARY KEY,
+   request_fingerprint CHAR(64) NOT NULL,
+   response JSONB NOT NULL,
+   created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
@@ -0,0 +1,6 @@
+ALTER TABLE wallet_transactions
+   ADD COLUMN IF NOT EXISTS transfer_id UUID,
+   ADD COLUMN IF NOT EXISTS counterparty_wallet_id UUID REFERENCES wallets (wallet_id);
+
+CREATE INDEX IF NOT EXISTS idx_wallet_transactions_transfer
+   ON wallet_transactions (transfer_id) WHERE transfer_id IS NOT NULL;
@@ -0,0 +1,10 @@
+ALTER TABLE wallet_transactions DROP COLUMN IF EXISTS entry_id;
+
+COMMENT ON COLUMN wallets.balance IS NULL;
+
+DROP TABLE IF EXISTS ledger_postings;
+DROP TABLE IF EXISTS journal_entries;
+DROP TABLE IF EXISTS ledger_accounts;
+
+DROP FUNCTION IF EXISTS forbid_ledger_mutation();
+DROP FUNCTION IF EXISTS check_journal_entry_balanced();
@@ -0,0 +1,84 @@
+-- Счета двойной записи: у каждого кошелька свой счет (account_id = wallet_id),
+-- системные счета отражают движение денег за пределами системы
+CREATE TABLE IF NOT EXISTS ledger_accounts (
+   account_id UUID PRIMARY KEY,
+   account_type VARCHAR(16) NOT NULL,
+   code VARCHAR(64) UNIQUE,
+   wallet_id UUID UNIQUE REFERENCES wallets (wallet_id),
+   created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+   CHECK ((account_type = 'WALLET' AND wallet_id IS NOT NULL) OR (account_type = 'SYSTEM' AND code IS NOT NULL))
+);
+
+CREATE TABLE IF NOT EXISTS journal_entries (
+   entry_id UUID PRIMARY KEY,
+   operation_type VARCHAR(32) NOT NULL,
+   created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS ledger_postings (
+   posting_id BIGSERIAL PRIMARY KEY,
+   entry_id UUID NOT NULL REFERENCES journal_entries (entry_id),
+   account_id UUID NOT NULL REFERENCES ledger_accounts (account_id),
+   amount DECIMAL(20, 2) NOT NULL CHECK (amount <> 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_ledger_postings_entry ON ledger_postings (entry_id);
+CREATE INDEX IF NOT EXISTS idx_ledger_postings_account ON ledger_postings (account_id);
+
+-- Сумма проводок каждой записи журнала должна быть равна нулю; проверка откладывается до COMMIT,
+-- чтобы все проводки записи успели вставиться
+CREATE OR REPLACE FUNCTION check_journal_entry_balanced() RETURNS trigger AS $$
+BEGIN
+   IF (SELECT SUM(amount) FROM ledger_postings WHERE entry_id = NEW.entry_id) <> 0 THEN
+      RAISE EXCEPTION 'journal entry % is not balanced', NEW.entry_id USING ERRCODE = 'check_violation';
+   END IF;
+   RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE CONSTRAINT TRIGGER trg_ledger_postings_balanced
+   AFTER INSERT ON ledger_postings
+   DEFERRABLE INITIALLY DEFERRED
+   FOR EACH ROW EXECUTE FUNCTION check_journal_entry_balanced();
+
+-- Журнал только дополняется: исправления делаются новыми записями
+CREATE OR REPLACE FUNCTION forbid_ledger_mutation() RETURNS trigger AS $$
+BEGIN
+   RAISE EXCEPTION 'ledger is append-only' USING ERRCODE = 'restrict_violation';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_journal_entries_append_only
+   BEFORE UPDATE OR DELETE ON journal_entries
+   FOR EACH ROW EXECUTE FUNCTION forbid_ledger_mutation();
+
+CREATE TRIGGER trg_ledger_postings_append_only
+   BEFORE UPDATE OR DELETE ON ledger_postings
+   FOR EACH ROW EXECUTE FUNCTION forbid_ledger_mutation();
+
+INSERT INTO ledger_accounts (account_id, account_type, code) VALUES
+   ('00000000-0000-0000-0000-000000000001', 'SYSTEM', 'external_cash_in'),
+   ('00000000-0000-0000-0000-000000000002', 'SYSTEM', 'external_cash_out'),
+   ('00000000-0000-0000-0000-000000000003', 'SYSTEM', 'opening_balance')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO ledger_accounts (account_id, account_type, wallet_id)
+SELECT wallet_id, 'WALLET', wallet_id FROM wallets
+ON CONFLICT DO NOTHING;
+
+-- Переносим накопленные балансы в журнал вступительными записями против счета opening_balance
+CREATE TEMPORARY TABLE opening_entries ON COMMIT DROP AS
+SELECT gen_random_uuid() AS entry_id, wallet_id, balance FROM wallets WHERE balance <> 0;
+
+INSERT INTO journal_entries (entry_id, operation_type)
+SELECT entry_id, 'OPENING_BALANCE' FROM opening_entries;
+
+INSERT INTO ledger_postings (entry_id, account_id, amount)
+SELECT entry_id, wallet_id, balance FROM opening_entries
+UNION ALL
+SELECT entry_id, '00000000-0000-0000-0000-000000000003', -balance FROM opening_entries;
+
+-- wallets.balance остается проекцией суммы проводок по счету кошелька
+COMMENT ON COLUMN wallets.balance IS 'Projection of SUM(ledger_postings.amount) for the wallet account';
+
+ALTER TABLE wallet_transactions ADD COLUMN IF NOT EXISTS entry_id UUID REFERENCES journal_entries (entry_id);
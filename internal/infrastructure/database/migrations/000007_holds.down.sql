@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS wallet_holds;
+
+ALTER TABLE wallets DROP COLUMN IF EXISTS held;
@@ -0,0 +1,19 @@
+-- Сумма активных холдов кошелька; доступный остаток = balance - held
+ALTER TABLE wallets ADD COLUMN IF NOT EXISTS held DECIMAL(20, 2) NOT NULL DEFAULT 0.00 CHECK (held >= 0);
+
+CREATE TABLE IF NOT EXISTS wallet_holds (
+   hold_id UUID PRIMARY KEY,
+   wallet_id UUID NOT NULL REFERENCES wallets (wallet_id),
+   amount DECIMAL(20, 2) NOT NULL CHECK (amount > 0),
+   captured_amount DECIMAL(20, 2),
+   status VARCHAR(16) NOT NULL,
+   expires_at TIMESTAMPTZ NOT NULL,
+   created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+   updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_wallet_holds_wallet ON wallet_holds (wallet_id);
+
+-- Индекс для фонового снятия просроченных холдов
+CREATE INDEX IF NOT EXISTS idx_wallet_holds_active_expires
+   ON wallet_holds (expires_at) WHERE status = 'ACTIVE';
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_wallet_transactions_original;
+
+ALTER TABLE wallet_transactions DROP COLUMN IF EXISTS original_transaction_id;
@@ -0,0 +1,6 @@
+ALTER TABLE wallet_transactions
+   ADD COLUMN IF NOT EXISTS original_transaction_id UUID REFERENCES wallet_transactions (transaction_id);
+
+-- Индекс для подсчета уже возвращенной суммы по исходной операции
+CREATE INDEX IF NOT EXISTS idx_wallet_transactions_original
+   ON wallet_transactions (original_transaction_id) WHERE original_transaction_id IS NOT NULL;
@@ -0,0 +1,28 @@
+CREATE OR REPLACE FUNCTION check_journal_entry_balanced() RETURNS trigger AS $$
+BEGIN
+   IF (SELECT SUM(amount) FROM ledger_postings WHERE entry_id = NEW.entry_id) <> 0 THEN
+      RAISE EXCEPTION 'journal entry % is not balanced', NEW.entry_id USING ERRCODE = 'check_violation';
+   END IF;
+   RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE ledger_postings
+   DROP COLUMN IF EXISTS currency,
+   ALTER COLUMN amount TYPE DECIMAL(20, 2);
+
+ALTER TABLE wallet_holds
+   ALTER COLUMN amount TYPE DECIMAL(20, 2),
+   ALTER COLUMN captured_amount TYPE DECIMAL(20, 2);
+
+ALTER TABLE wallet_transactions
+   DROP COLUMN IF EXISTS currency,
+   ALTER COLUMN amount TYPE DECIMAL(20, 2),
+   ALTER COLUMN balance_after TYPE DECIMAL(20, 2);
+
+ALTER TABLE wallets
+   ALTER COLUMN balance TYPE DECIMAL(20, 2),
+   ALTER COLUMN balance SET DEFAULT 0.00,
+   ALTER COLUMN held TYPE DECIMAL(20, 2),
+   ALTER COLUMN held SET DEFAULT 0.00,
+   DROP COLUMN IF EXISTS currency;
@@ -0,0 +1,42 @@
+-- Валюта кошелька задается при создании и не меняется; существующие кошельки считаются рублевыми
+ALTER TABLE wallets ADD COLUMN IF NOT EXISTS currency VARCHAR(8) NOT NULL DEFAULT 'RUB';
+ALTER TABLE wallets ALTER COLUMN currency DROP DEFAULT;
+
+-- Точность хранения не ограничивает валюту: допустимое число знаков проверяет приложение
+ALTER TABLE wallets
+   ALTER COLUMN balance TYPE NUMERIC(38, 18),
+   ALTER COLUMN balance SET DEFAULT 0,
+   ALTER COLUMN held TYPE NUMERIC(38, 18),
+   ALTER COLUMN held SET DEFAULT 0;
+
+ALTER TABLE wallet_transactions
+   ALTER COLUMN amount TYPE NUMERIC(38, 18),
+   ALTER COLUMN balance_after TYPE NUMERIC(38, 18),
+   ADD COLUMN IF NOT EXISTS currency VARCHAR(8);
+
+UPDATE wallet_transactions t SET currency = w.currency FROM wallets w WHERE w.wallet_id = t.wallet_id;
+ALTER TABLE wallet_transactions ALTER COLUMN currency SET NOT NULL;
+
+ALTER TABLE wallet_holds
+   ALTER COLUMN amount TYPE NUMERIC(38, 18),
+   ALTER COLUMN captured_amount TYPE NUMERIC(38, 18);
+
+ALTER TABLE ledger_postings
+   ALTER COLUMN amount TYPE NUMERIC(38, 18),
+   ADD COLUMN IF NOT EXISTS currency VARCHAR(8) NOT NULL DEFAULT 'RUB';
+ALTER TABLE ledger_postings ALTER COLUMN currency DROP DEFAULT;
+
+-- Запись журнала должна быть сбалансирована в каждой валюте отдельно
+CREATE OR REPLACE FUNCTION check_journal_entry_balanced() RETURNS trigger AS $$
+BEGIN
+   IF EXISTS (
+      SELECT 1 FROM ledger_postings
+      WHERE entry_id = NEW.entry_id
+      GROUP BY currency
+      HAVING SUM(amount) <> 0
+   ) THEN
+      RAISE EXCEPTION 'journal entry % is not balanced', NEW.entry_id USING ERRCODE = 'check_violation';
+   END IF;
+   RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,11 @@
+-- Счет fx_conversion остается, если по нему уже есть проводки: журнал только дополняется
+DELETE FROM ledger_accounts
+WHERE account_id = '00000000-0000-0000-0000-000000000004'
+   AND NOT EXISTS (SELECT 1 FROM ledger_postings WHERE account_id = '00000000-0000-0000-0000-000000000004');
+
+ALTER TABLE wallet_transactions
+   DROP COLUMN IF EXISTS counter_currency,
+   DROP COLUMN IF EXISTS counter_amount,
+   DROP COLUMN IF EXISTS exchange_rate;
+
+DROP TABLE IF EXISTS fx_quotes;
@@ -0,0 +1,21 @@
+-- Котировки фиксируют курс на короткое время; использованная котировка связывается с переводом
+CREATE TABLE IF NOT EXISTS fx_quotes (
+   quote_id UUID PRIMARY KEY,
+   from_currency VARCHAR(8) NOT NULL,
+   to_currency VARCHAR(8) NOT NULL,
+   rate NUMERIC(38, 18) NOT NULL CHECK (rate > 0),
+   expires_at TIMESTAMPTZ NOT NULL,
+   transfer_id UUID,
+   created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+   CHECK (from_currency <> to_currency)
+);
+
+-- Для переводов с конвертацией записываются курс и сумма второй стороны в ее валюте
+ALTER TABLE wallet_transactions
+   ADD COLUMN IF NOT EXISTS exchange_rate NUMERIC(38, 18),
+   ADD COLUMN IF NOT EXISTS counter_amount NUMERIC(38, 18),
+   ADD COLUMN IF NOT EXISTS counter_currency VARCHAR(8);
+
+INSERT INTO ledger_accounts (account_id, account_type, code) VALUES
+   ('00000000-0000-0000-0000-000000000004', 'SYSTEM', 'fx_conversion')
+ON CONFLICT DO NOTHING;
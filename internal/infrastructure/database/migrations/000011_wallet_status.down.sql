@@ -0,0 +1,4 @@
+ALTER TABLE wallets
+   DROP COLUMN IF EXISTS status_changed_at,
+   DROP COLUMN IF EXISTS status_reason,
+   DROP COLUMN IF EXISTS status;
@@ -0,0 +1,6 @@
+-- Состояние кошелька: FROZEN запрещает списания, BLOCKED — любые движения, CLOSED — окончательное закрытие
+ALTER TABLE wallets
+   ADD COLUMN IF NOT EXISTS status VARCHAR(16) NOT NULL DEFAULT 'ACTIVE'
+      CHECK (status IN ('ACTIVE', 'FROZEN', 'BLOCKED', 'CLOSED')),
+   ADD COLUMN IF NOT EXISTS status_reason TEXT,
+   ADD COLUMN IF NOT EXISTS status_changed_at TIMESTAMPTZ;
@@ -0,0 +1,12 @@
+DROP TRIGGER IF EXISTS trg_wallets_updated_at ON wallets;
+DROP FUNCTION IF EXISTS touch_wallet_updated_at();
+
+DROP INDEX IF EXISTS idx_wallets_owner;
+
+ALTER TABLE wallets
+   DROP COLUMN IF EXISTS updated_at,
+   DROP COLUMN IF EXISTS created_at,
+   DROP COLUMN IF EXISTS labels,
+   DROP COLUMN IF EXISTS metadata,
+   DROP COLUMN IF EXISTS display_name,
+   DROP COLUMN IF EXISTS owner_id;
@@ -0,0 +1,22 @@
+-- Владелец и описательные данные кошелька; owner_id — идентификатор клиента во внешней системе
+ALTER TABLE wallets
+   ADD COLUMN IF NOT EXISTS owner_id VARCHAR(255),
+   ADD COLUMN IF NOT EXISTS display_name VARCHAR(255),
+   ADD COLUMN IF NOT EXISTS metadata JSONB NOT NULL DEFAULT '{}',
+   ADD COLUMN IF NOT EXISTS labels TEXT[] NOT NULL DEFAULT '{}',
+   ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+   ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ NOT NULL DEFAULT now();
+
+CREATE INDEX IF NOT EXISTS idx_wallets_owner ON wallets (owner_id, created_at) WHERE owner_id IS NOT NULL;
+
+-- updated_at отражает любое изменение строки кошелька, включая баланс и состояние
+CREATE OR REPLACE FUNCTION touch_wallet_updated_at() RETURNS trigger AS $$
+BEGIN
+   NEW.updated_at = now();
+   RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_wallets_updated_at
+   BEFORE UPDATE ON wallets
+   FOR EACH ROW EXECUTE FUNCTION touch_wallet_updated_at();
@@ -0,0 +1,11 @@
+-- Несведенные зачисления переносятся в баланс кошелька, чтобы не потерять средства
+UPDATE wallets w SET balance = w.balance + s.total
+FROM (SELECT wallet_id, SUM(balance) AS total FROM wallet_balance_shards GROUP BY wallet_id) s
+WHERE w.wallet_id = s.wallet_id;
+
+DROP TABLE IF EXISTS wallet_balance_shards;
+
+ALTER TABLE wallets DROP COLUMN IF EXISTS shard_count;
+
+UPDATE wallet_transactions t SET balance_after = 0 WHERE balance_after IS NULL;
+ALTER TABLE wallet_transactions ALTER COLUMN balance_after SET NOT NULL;
@@ -0,0 +1,17 @@
+-- Шардированный кошелек: зачисления распределяются по shard_count строкам-шардам, чтобы параллельные
+-- зачисления не ждали блокировки одной строки. 0 — обычный кошелек. Число шардов задается при создании.
+ALTER TABLE wallets
+   ADD COLUMN IF NOT EXISTS shard_count INT NOT NULL DEFAULT 0
+   CHECK (shard_count = 0 OR shard_count BETWEEN 2 AND 64);
+
+-- Зачисления, еще не сведенные в баланс кошелька; шарды только пополняются
+-- и обнуляются при сведении, поэтому их баланс не бывает отрицательным
+CREATE TABLE IF NOT EXISTS wallet_balance_shards (
+   wallet_id UUID NOT NULL REFERENCES wallets (wallet_id),
+   shard_id INT NOT NULL,
+   balance NUMERIC(38, 18) NOT NULL DEFAULT 0 CHECK (balance >= 0),
+   PRIMARY KEY (wallet_id, shard_id)
+);
+
+-- Баланс шардированного кошелька после зачисления на шард не вычисляется
+ALTER TABLE wallet_transactions ALTER COLUMN balance_after DROP NOT NULL;
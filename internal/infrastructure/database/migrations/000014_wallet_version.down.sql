@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS trg_wallets_version ON wallets;
+DROP FUNCTION IF EXISTS bump_wallet_version();
+
+ALTER TABLE wallets DROP COLUMN IF EXISTS version;
@@ -0,0 +1,14 @@
+-- Версия кошелька для оптимистичной блокировки: увеличивается при каждом изменении строки кошелька
+-- (баланс, холды, состояние). Клиент получает ее в ETag и может передать в If-Match.
+ALTER TABLE wallets ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
+
+CREATE OR REPLACE FUNCTION bump_wallet_version() RETURNS trigger AS $$
+BEGIN
+   NEW.version = OLD.version + 1;
+   RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_wallets_version
+   BEFORE UPDATE ON wallets
+   FOR EACH ROW EXECUTE FUNCTION bump_wallet_version();
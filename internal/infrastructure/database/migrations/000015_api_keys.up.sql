@@ -0,0 +1,14 @@
+-- Ключи API: хранится только SHA-256 ключа, сам ключ показывается клиенту один раз при выпуске
+CREATE TABLE IF NOT EXISTS api_keys (
+   key_id UUID PRIMARY KEY,
+   name VARCHAR(255) NOT NULL,
+   subject VARCHAR(255) NOT NULL,
+   roles TEXT[] NOT NULL DEFAULT '{}',
+   key_prefix VARCHAR(16) NOT NULL,
+   key_hash BYTEA NOT NULL UNIQUE,
+   created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+   expires_at TIMESTAMPTZ,
+   revoked_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_subject ON api_keys (subject);
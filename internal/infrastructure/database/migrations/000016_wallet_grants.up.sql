@@ -0,0 +1,9 @@
+-- Роли, выданные клиентам на кошельки; владелец кошелька (wallets.owner_id) имеет роль owner без записи здесь
+CREATE TABLE IF NOT EXISTS wallet_grants (
+   wallet_id UUID NOT NULL REFERENCES wallets (wallet_id),
+   subject VARCHAR(255) NOT NULL,
+   role VARCHAR(16) NOT NULL CHECK (role IN ('owner', 'operator', 'viewer')),
+   granted_by VARCHAR(255),
+   created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+   PRIMARY KEY (wallet_id, subject)
+);
@@ -0,0 +1,7 @@
+-- Использованные nonce подписанных запросов; запись нужна, пока метка времени запроса остается в допустимом окне
+CREATE TABLE IF NOT EXISTS request_nonces (
+   nonce VARCHAR(255) PRIMARY KEY,
+   expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_request_nonces_expires ON request_nonces (expires_at);
@@ -0,0 +1,7 @@
+-- Корзины токенов ограничения частоты запросов, общие для всех экземпляров приложения;
+-- updated_at пуст у корзины, которой еще не пользовались
+CREATE TABLE IF NOT EXISTS rate_limit_buckets (
+   bucket_key VARCHAR(255) PRIMARY KEY,
+   tokens DOUBLE PRECISION NOT NULL DEFAULT 0,
+   updated_at TIMESTAMPTZ
+);
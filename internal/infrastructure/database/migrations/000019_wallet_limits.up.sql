@@ -0,0 +1,11 @@
+-- Уровень ограничений кошелька и собственные ограничения, заменяющие ограничения уровня; NULL — берется из уровня.
+-- Уровни описаны в конфигурации (limits.tiers); кошелек без записи здесь получает уровень по умолчанию.
+CREATE TABLE IF NOT EXISTS wallet_limits (
+   wallet_id UUID PRIMARY KEY REFERENCES wallets (wallet_id),
+   tier VARCHAR(64),
+   max_transaction NUMERIC(38, 18) CHECK (max_transaction > 0),
+   daily_withdrawal NUMERIC(38, 18) CHECK (daily_withdrawal > 0),
+   monthly_withdrawal NUMERIC(38, 18) CHECK (monthly_withdrawal > 0),
+   max_balance NUMERIC(38, 18) CHECK (max_balance > 0),
+   updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
@@ -0,0 +1,8 @@
+ALTER TABLE idempotency_keys DROP CONSTRAINT IF EXISTS idempotency_keys_pkey;
+
+-- Одинаковые ключи разных клиентов не уместятся в прежний первичный ключ: остается ответ одного из них
+DELETE FROM idempotency_keys a USING idempotency_keys b
+WHERE a.idempotency_key = b.idempotency_key AND a.subject > b.subject;
+
+ALTER TABLE idempotency_keys DROP COLUMN IF EXISTS subject;
+ALTER TABLE idempotency_keys ADD PRIMARY KEY (idempotency_key);
@@ -0,0 +1,6 @@
+-- Ключи идемпотентности выбирают клиенты, поэтому они уникальны только в пределах клиента (subject ключа API или токена).
+-- Пустой subject — внутренние вызовы без клиента и ключи, сохраненные до появления аутентификации.
+ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS subject VARCHAR(255) NOT NULL DEFAULT '';
+
+ALTER TABLE idempotency_keys DROP CONSTRAINT IF EXISTS idempotency_keys_pkey;
+ALTER TABLE idempotency_keys ADD PRIMARY KEY (subject, idempotency_key);
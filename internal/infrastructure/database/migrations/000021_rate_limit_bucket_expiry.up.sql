@@ -0,0 +1,6 @@
+-- full_at — момент, когда корзина наполнится до емкости: такая корзина не отличается от новой и удаляется.
+-- Для корзин, сохраненных раньше, емкость неизвестна, поэтому они удаляются через сутки простоя.
+ALTER TABLE rate_limit_buckets ADD COLUMN IF NOT EXISTS full_at TIMESTAMPTZ;
+UPDATE rate_limit_buckets SET full_at = COALESCE(updated_at, now()) + INTERVAL '1 day' WHERE full_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_rate_limit_buckets_full_at ON rate_limit_buckets (full_at);
@@ -0,0 +1,68 @@
+package fxrates
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/shopspring/decimal"
+
+	"wallet-app/internal/app/app_errors"
+)
+
+// ratePrecision — число знаков кросс-курса; совпадает с точностью хранения сумм в базе
+const ratePrecision = 18
+
+// ratesFile — формат файла курсов: сколько единиц каждой валюты стоит одна единица базовой
+type ratesFile struct {
+	Base  string                     `json:"base"`
+	Rates map[string]decimal.Decimal `json:"rates"`
+}
+
+// FileProvider отдает курсы из JSON-файла и позволяет работать без внешнего источника курсов.
+// Курс между двумя валютами вычисляется через базовую валюту файла.
+type FileProvider struct {
+	rates map[string]decimal.Decimal
+}
+
+// NewFileProvider загружает курсы из файла path
+func NewFileProvider(path string) (*FileProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file ratesFile
+	if err = json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse rates file: %w", err)
+	}
+	if file.Base == "" {
+		return nil, fmt.Errorf("rates file %s has no base currency", path)
+	}
+
+	rates := make(map[string]decimal.Decimal, len(file.Rates)+1)
+	for currency, rate := range file.Rates {
+		if !rate.IsPositive() {
+			return nil, fmt.Errorf("rates file %s: rate for %s must be positive", path, currency)
+		}
+		rates[currency] = rate
+	}
+	rates[file.Base] = decimal.NewFromInt(1)
+
+	return &FileProvider{rates: rates}, nil
+}
+
+// GetRate возвращает, сколько единиц to стоит одна единица from
+func (p *FileProvider) GetRate(_ context.Context, from, to string) (decimal.Decimal, error) {
+	fromRate, ok := p.rates[from]
+	if !ok {
+		return decimal.Decimal{}, app_errors.ErrExchangeRateUnavailable
+	}
+	toRate, ok := p.rates[to]
+	if !ok {
+		return decimal.Decimal{}, app_errors.ErrExchangeRateUnavailable
+	}
+
+	return toRate.DivRound(fromRate, ratePrecision), nil
+}
@@ -0,0 +1,226 @@
+// Package jwt проверяет токены JWT в компактной записи (RFC 7519), подписанные HS256 или RS256.
+package jwt
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"wallet-app/internal/app/app_errors"
+	"wallet-app/internal/app/domain"
+	"wallet-app/internal/configs"
+)
+
+const (
+	HS256 = "HS256"
+	RS256 = "RS256"
+)
+
+// maxTokenLength — ограничение длины токена, чтобы не разбирать произвольно большие заголовки
+const maxTokenLength = 8192
+
+// Verifier проверяет подпись и сроки действия токена и извлекает из него клиента
+type Verifier struct {
+	algorithm string
+	secret    []byte
+	publicKey *rsa.PublicKey
+	issuer    string
+	audience  string
+	leeway    time.Duration
+	now       func() time.Time
+}
+
+// header — заголовок токена
+type header struct {
+	Algorithm string `json:"alg"`
+	Type      string `json:"typ"`
+}
+
+// claims — поля токена, которые учитывает приложение
+type claims struct {
+	Subject   string   `json:"sub"`
+	Issuer    string   `json:"iss"`
+	Audience  audience `json:"aud"`
+	ExpiresAt *int64   `json:"exp"`
+	NotBefore *int64   `json:"nbf"`
+	Roles     []string `json:"roles"`
+}
+
+// audience — claim aud, который по RFC 7519 может быть строкой или массивом строк
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*a = list
+	return nil
+}
+
+// NewVerifier создает Verifier по конфигурации cfg
+func NewVerifier(cfg configs.JWTConfig) (*Verifier, error) {
+	v := &Verifier{
+		algorithm: cfg.Algorithm,
+		issuer:    cfg.Issuer,
+		audience:  cfg.Audience,
+		leeway:    cfg.Leeway,
+		now:       time.Now,
+	}
+
+	switch cfg.Algorithm {
+	case HS256:
+		if cfg.Secret == "" {
+			return nil, errors.New("jwt secret is required for HS256")
+		}
+		v.secret = []byte(cfg.Secret)
+	case RS256:
+		key, err := loadPublicKey(cfg.PublicKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		v.publicKey = key
+	default:
+		return nil, fmt.Errorf("unsupported jwt algorithm: %s", cfg.Algorithm)
+	}
+
+	return v, nil
+}
+
+// loadPublicKey читает открытый ключ RSA из PEM-файла (PKIX или PKCS #1)
+func loadPublicKey(path string) (*rsa.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM block in %s", path)
+	}
+
+	if key, err := x509.ParsePKCS1PublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("public key in %s is not an RSA key", path)
+	}
+	return key, nil
+}
+
+// Verify проверяет токен и возвращает клиента, от имени которого он выпущен.
+// Алгоритм берется из конфигурации, а не из заголовка токена, поэтому токены с "alg": "none"
+// или с другим алгоритмом отклоняются. Токен без exp не принимается.
+func (v *Verifier) Verify(token string) (domain.Principal, error) {
+	if len(token) > maxTokenLength {
+		return domain.Principal{}, invalid(errors.New("token is too long"))
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return domain.Principal{}, invalid(errors.New("malformed token"))
+	}
+
+	var h header
+	if err := decodeSegment(parts[0], &h); err != nil {
+		return domain.Principal{}, invalid(fmt.Errorf("header: %w", err))
+	}
+	if h.Algorithm != v.algorithm {
+		return domain.Principal{}, invalid(fmt.Errorf("unexpected algorithm %q", h.Algorithm))
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return domain.Principal{}, invalid(fmt.Errorf("signature: %w", err))
+	}
+	if err = v.verifySignature(parts[0]+"."+parts[1], signature); err != nil {
+		return domain.Principal{}, invalid(err)
+	}
+
+	var c claims
+	if err = decodeSegment(parts[1], &c); err != nil {
+		return domain.Principal{}, invalid(fmt.Errorf("claims: %w", err))
+	}
+	if err = v.validateClaims(c); err != nil {
+		return domain.Principal{}, invalid(err)
+	}
+
+	return domain.Principal{Subject: c.Subject, Method: domain.AuthJWT, Roles: c.Roles}, nil
+}
+
+func (v *Verifier) verifySignature(signingInput string, signature []byte) error {
+	switch v.algorithm {
+	case HS256:
+		mac := hmac.New(sha256.New, v.secret)
+		mac.Write([]byte(signingInput))
+		if !hmac.Equal(mac.Sum(nil), signature) {
+			return errors.New("invalid signature")
+		}
+		return nil
+	case RS256:
+		digest := sha256.Sum256([]byte(signingInput))
+		if err := rsa.VerifyPKCS1v15(v.publicKey, crypto.SHA256, digest[:], signature); err != nil {
+			return errors.New("invalid signature")
+		}
+		return nil
+	}
+	return fmt.Errorf("unsupported algorithm %q", v.algorithm)
+}
+
+func (v *Verifier) validateClaims(c claims) error {
+	now := v.now()
+
+	if c.Subject == "" {
+		return errors.New("sub is required")
+	}
+	if c.ExpiresAt == nil {
+		return errors.New("exp is required")
+	}
+	if !now.Before(time.Unix(*c.ExpiresAt, 0).Add(v.leeway)) {
+		return errors.New("token has expired")
+	}
+	if c.NotBefore != nil && now.Add(v.leeway).Before(time.Unix(*c.NotBefore, 0)) {
+		return errors.New("token is not valid yet")
+	}
+	if v.issuer != "" && c.Issuer != v.issuer {
+		return fmt.Errorf("unexpected issuer %q", c.Issuer)
+	}
+	if v.audience != "" && !slices.Contains(c.Audience, v.audience) {
+		return errors.New("token is not intended for this audience")
+	}
+	return nil
+}
+
+// decodeSegment декодирует часть токена из base64url и разбирает JSON; неизвестные поля допускаются
+func decodeSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func invalid(cause error) error {
+	return app_errors.ErrInvalidCredentials.Wrap(cause)
+}
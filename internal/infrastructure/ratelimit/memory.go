@@ -0,0 +1,66 @@
+// Package ratelimit хранит корзины токенов ограничения частоты запросов в памяти процесса.
+// Ограничения действуют на каждый экземпляр приложения отдельно; чтобы они были общими для всех
+// экземпляров, используется хранилище корзин в Postgres (repository.WalletRepository).
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"wallet-app/internal/app/domain"
+	"wallet-app/internal/app/repository"
+)
+
+// sweepInterval — как часто удаляются корзины, которые успели наполниться и ничем не отличаются от новых
+const sweepInterval = time.Minute
+
+var _ repository.RateLimits = (*MemoryLimiter)(nil)
+
+// MemoryLimiter — корзины токенов в памяти процесса
+type MemoryLimiter struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	swept   time.Time
+	now     func() time.Time
+}
+
+type bucket struct {
+	domain.TokenBucket
+	// fullAt — когда корзина наполнится, если ею не пользоваться
+	fullAt time.Time
+}
+
+func NewMemoryLimiter() *MemoryLimiter {
+	return &MemoryLimiter{buckets: make(map[string]*bucket), now: time.Now}
+}
+
+// TakeToken берет токен из корзины key
+func (l *MemoryLimiter) TakeToken(ctx context.Context, key string, limit domain.RateLimit) (domain.RateDecision, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.RateDecision{}, err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if now.Sub(l.swept) >= sweepInterval {
+		for key, b := range l.buckets {
+			if now.After(b.fullAt) {
+				delete(l.buckets, key)
+			}
+		}
+		l.swept = now
+	}
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{}
+		l.buckets[key] = b
+	}
+
+	decision := limit.Take(&b.TokenBucket, now)
+	b.fullAt = now.Add(limit.RefillTime(b.TokenBucket))
+	return decision, nil
+}
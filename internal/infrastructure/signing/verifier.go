@@ -0,0 +1,112 @@
+// Package signing проверяет подписи HMAC-SHA256 запросов, которыми серверы клиентов подтверждают
+// целостность запроса: метода, пути, метки времени, nonce и тела.
+package signing
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"regexp"
+	"strconv"
+	"time"
+
+	"wallet-app/internal/app/app_errors"
+	"wallet-app/internal/app/domain"
+	"wallet-app/internal/configs"
+)
+
+// noncePattern — допустимый nonce: достаточно длинный, чтобы не повторяться случайно,
+// и без перевода строки, разделяющего поля подписываемой строки
+var noncePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{16,128}$`)
+
+// Verifier проверяет подписи запросов ключами клиентов из конфигурации
+type Verifier struct {
+	// Ключи по идентификатору; у клиента может быть несколько ключей на время их смены
+	keys    map[string]key
+	clients map[string]bool
+	maxSkew time.Duration
+	now     func() time.Time
+}
+
+type key struct {
+	client string
+	secret []byte
+}
+
+// NewVerifier создает Verifier по конфигурации cfg
+func NewVerifier(cfg configs.SigningConfig) (*Verifier, error) {
+	if len(cfg.Keys) == 0 {
+		return nil, errors.New("no signing keys configured")
+	}
+
+	v := &Verifier{
+		keys:    make(map[string]key, len(cfg.Keys)),
+		clients: make(map[string]bool),
+		maxSkew: cfg.MaxSkew,
+		now:     time.Now,
+	}
+	for _, k := range cfg.Keys {
+		v.keys[k.ID] = key{client: k.Client, secret: []byte(k.Secret)}
+		v.clients[k.Client] = true
+	}
+
+	return v, nil
+}
+
+// Requires сообщает, обязан ли клиент subject подписывать запросы: да, если для него настроен хотя бы один ключ
+func (v *Verifier) Requires(subject string) bool {
+	return v.clients[subject]
+}
+
+// Verify проверяет, что запрос подписан ключом клиента subject и метка времени не вышла из допустимого окна.
+// Возвращает момент, до которого nonce запроса нужно помнить, чтобы отклонить его повтор.
+func (v *Verifier) Verify(subject string, req domain.SignedRequest) (time.Time, error) {
+	k, ok := v.keys[req.KeyID]
+	if !ok {
+		return time.Time{}, invalid(errors.New("unknown signing key"))
+	}
+	// Ключ одного клиента не подтверждает запросы другого
+	if k.client != subject {
+		return time.Time{}, invalid(errors.New("signing key belongs to another client"))
+	}
+	if !noncePattern.MatchString(req.Nonce) {
+		return time.Time{}, invalid(errors.New("nonce must be 16 to 128 characters of [A-Za-z0-9_-]"))
+	}
+
+	signature, err := hex.DecodeString(req.Signature)
+	if err != nil {
+		return time.Time{}, invalid(errors.New("signature must be hex encoded"))
+	}
+	if !hmac.Equal(signature, mac(k.secret, req)) {
+		return time.Time{}, invalid(errors.New("signature mismatch"))
+	}
+
+	seconds, err := strconv.ParseInt(req.Timestamp, 10, 64)
+	if err != nil {
+		return time.Time{}, invalid(errors.New("timestamp must be unix seconds"))
+	}
+	signedAt := time.Unix(seconds, 0)
+	now := v.now()
+	if signedAt.Before(now.Add(-v.maxSkew)) || signedAt.After(now.Add(v.maxSkew)) {
+		return time.Time{}, app_errors.ErrSignatureExpired
+	}
+
+	// Позже запрос с этой меткой времени отклоняется по окну, и nonce больше не нужен
+	return signedAt.Add(v.maxSkew), nil
+}
+
+// Sign вычисляет подпись запроса секретом secret в шестнадцатеричной записи
+func Sign(secret []byte, req domain.SignedRequest) string {
+	return hex.EncodeToString(mac(secret, req))
+}
+
+func mac(secret []byte, req domain.SignedRequest) []byte {
+	h := hmac.New(sha256.New, secret)
+	h.Write([]byte(req.StringToSign()))
+	return h.Sum(nil)
+}
+
+func invalid(cause error) error {
+	return app_errors.ErrInvalidSignature.Wrap(cause)
+}
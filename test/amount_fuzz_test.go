@@ -0,0 +1,107 @@
+package test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"wallet-app/internal/app/app_errors"
+	"wallet-app/internal/app/domain"
+)
+
+// amountSeeds — необычные записи сумм для начального корпуса
+var amountSeeds = []string{
+	"", "0", "-0", "+1", "1", "100.00", "0.01", "-5", " 1", "1 ", "1,5", ".5", "5.", "1..2", "--1",
+	"1e3", "1E-3", "1e400000000", "1e-400000000", "0x10", "NaN", "nan", "Inf", "-Infinity", "١٢٣",
+	"0.000000000000000001", "0.0000000000000000001", "99999999999999999999", "100000000000000000000",
+	"99999999999999999999.999999999999999999", "1." + strings.Repeat("0", 500), strings.Repeat("0", 500) + "1",
+	strings.Repeat("9", 2000),
+}
+
+func FuzzParseAmount(f *testing.F) {
+	for _, seed := range amountSeeds {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, value string) {
+		amount, err := domain.ParseAmount(value)
+		if err != nil {
+			if !errors.Is(err, app_errors.ErrInvalidAmount) && !errors.Is(err, app_errors.ErrAmountOutOfRange) {
+				t.Fatalf("ParseAmount(%q): unexpected error %v", value, err)
+			}
+			return
+		}
+
+		// Принятая сумма записана без экспоненты и помещается в NUMERIC(38, 18)
+		if strings.ContainsAny(value, "eE") {
+			t.Fatalf("ParseAmount(%q) accepted exponent notation", value)
+		}
+		if !amount.Equal(amount.Truncate(domain.MaxAmountScale)) {
+			t.Fatalf("ParseAmount(%q) = %s: scale exceeds %d", value, amount, domain.MaxAmountScale)
+		}
+		if amount.Abs().GreaterThanOrEqual(decimal.New(1, domain.MaxAmountIntegerDigits)) {
+			t.Fatalf("ParseAmount(%q) = %s: too many integer digits", value, amount)
+		}
+
+		// Каноническая запись разбирается в то же значение
+		again, err := domain.ParseAmount(amount.String())
+		if err != nil || !again.Equal(amount) {
+			t.Fatalf("ParseAmount(%q) = %s does not round-trip: %s, %v", value, amount, again, err)
+		}
+	})
+}
+
+func FuzzWalletOperationValidate(f *testing.F) {
+	operationTypes := []domain.OperationType{domain.Deposit, domain.Withdraw, domain.Reversal, domain.Refund, "", "BONUS"}
+	currencies := []string{"", "USD", "JPY", "BHD", "ETH", "XXX", "usd"}
+	for i, seed := range amountSeeds {
+		f.Add(seed, string(operationTypes[i%len(operationTypes)]), currencies[i%len(currencies)], i%3 == 0)
+	}
+
+	f.Fuzz(func(t *testing.T, amount, operationType, currency string, withOriginal bool) {
+		op := domain.WalletOperation{
+			WalletID:      uuid.New(),
+			OperationType: domain.OperationType(operationType),
+			Amount:        amount,
+			Currency:      currency,
+		}
+		if withOriginal {
+			original := uuid.New()
+			op.TransactionID = &original
+		}
+
+		if err := op.Validate(); err != nil {
+			return
+		}
+
+		// Прошедшая проверку операция имеет допустимый тип и положительную сумму в пределах точности валюты
+		if !op.IsCorrection() && op.OperationType != domain.Deposit && op.OperationType != domain.Withdraw {
+			t.Fatalf("accepted operation type %q", op.OperationType)
+		}
+		if op.OperationType == domain.Reversal && op.Amount == "" {
+			return
+		}
+
+		parsed, err := op.ParseAmount()
+		if err != nil {
+			t.Fatalf("validated amount %q does not parse: %v", amount, err)
+		}
+		if !parsed.IsPositive() {
+			t.Fatalf("validated amount %q is not positive", amount)
+		}
+		if currency != "" {
+			if err = domain.ValidateAmountScale(currency, parsed); err != nil {
+				t.Fatalf("validated amount %q exceeds %s scale", amount, currency)
+			}
+		}
+		if _, err = op.Fingerprint(); err != nil {
+			t.Fatalf("validated operation has no fingerprint: %v", err)
+		}
+		if _, err = op.GetSignedAmount(); err != nil {
+			t.Fatalf("validated operation has no signed amount: %v", err)
+		}
+	})
+}
@@ -0,0 +1,261 @@
+package test
+
+import (
+	"context"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"wallet-app/internal/app/app_errors"
+	delivery "wallet-app/internal/app/delivery/http"
+	"wallet-app/internal/app/domain"
+	"wallet-app/internal/app/repository"
+	"wallet-app/internal/app/services"
+	"wallet-app/internal/app/services/mocks"
+	"wallet-app/internal/configs"
+	"wallet-app/internal/infrastructure/jwt"
+)
+
+const testJWTSecret = "0123456789abcdef0123456789abcdef"
+
+// signToken собирает токен JWT с заголовком alg и подписью, вычисленной sign
+func signToken(t *testing.T, alg string, claims map[string]any, sign func(input []byte) []byte) string {
+	t.Helper()
+	header, err := json.Marshal(map[string]string{"alg": alg, "typ": "JWT"})
+	require.NoError(t, err)
+	payload, err := json.Marshal(claims)
+	require.NoError(t, err)
+
+	input := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return input + "." + base64.RawURLEncoding.EncodeToString(sign([]byte(input)))
+}
+
+func hs256(secret string) func([]byte) []byte {
+	return func(input []byte) []byte {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(input)
+		return mac.Sum(nil)
+	}
+}
+
+func validClaims() map[string]any {
+	return map[string]any{
+		"sub":   "user-1",
+		"iss":   "issuer",
+		"aud":   []string{"wallet-app", "other"},
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"roles": []string{"owner"},
+	}
+}
+
+func TestJWTVerifier_HS256(t *testing.T) {
+	verifier, err := jwt.NewVerifier(configs.JWTConfig{
+		Algorithm: jwt.HS256, Secret: testJWTSecret, Issuer: "issuer", Audience: "wallet-app",
+	})
+	require.NoError(t, err)
+
+	principal, err := verifier.Verify(signToken(t, jwt.HS256, validClaims(), hs256(testJWTSecret)))
+	require.NoError(t, err)
+	assert.Equal(t, domain.Principal{Subject: "user-1", Method: domain.AuthJWT, Roles: []string{"owner"}}, principal)
+
+	tests := []struct {
+		name  string
+		token func() string
+	}{
+		{"wrong secret", func() string {
+			return signToken(t, jwt.HS256, validClaims(), hs256("another-secret-another-secret-00"))
+		}},
+		{"alg none", func() string {
+			return signToken(t, "none", validClaims(), func([]byte) []byte { return nil })
+		}},
+		{"expired", func() string {
+			claims := validClaims()
+			claims["exp"] = time.Now().Add(-time.Minute).Unix()
+			return signToken(t, jwt.HS256, claims, hs256(testJWTSecret))
+		}},
+		{"without exp", func() string {
+			claims := validClaims()
+			delete(claims, "exp")
+			return signToken(t, jwt.HS256, claims, hs256(testJWTSecret))
+		}},
+		{"not valid yet", func() string {
+			claims := validClaims()
+			claims["nbf"] = time.Now().Add(time.Hour).Unix()
+			return signToken(t, jwt.HS256, claims, hs256(testJWTSecret))
+		}},
+		{"wrong issuer", func() string {
+			claims := validClaims()
+			claims["iss"] = "someone-else"
+			return signToken(t, jwt.HS256, claims, hs256(testJWTSecret))
+		}},
+		{"wrong audience", func() string {
+			claims := validClaims()
+			claims["aud"] = "other"
+			return signToken(t, jwt.HS256, claims, hs256(testJWTSecret))
+		}},
+		{"malformed", func() string { return "not-a-token" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := verifier.Verify(tt.token())
+			assert.ErrorIs(t, err, app_errors.ErrInvalidCredentials)
+		})
+	}
+}
+
+func TestJWTVerifier_RS256(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+	keyFile := filepath.Join(t.TempDir(), "public.pem")
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o600))
+
+	verifier, err := jwt.NewVerifier(configs.JWTConfig{Algorithm: jwt.RS256, PublicKeyFile: keyFile})
+	require.NoError(t, err)
+
+	rs256 := func(input []byte) []byte {
+		digest := sha256.Sum256(input)
+		signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+		require.NoError(t, err)
+		return signature
+	}
+
+	principal, err := verifier.Verify(signToken(t, jwt.RS256, validClaims(), rs256))
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", principal.Subject)
+
+	// Токен, подписанный HS256 открытым ключом как секретом, не принимается
+	_, err = verifier.Verify(signToken(t, jwt.HS256, validClaims(), hs256(string(der))))
+	assert.ErrorIs(t, err, app_errors.ErrInvalidCredentials)
+}
+
+func TestAuthService_APIKeys(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryRepository()
+	auth := services.NewAuthService(repo, repo, nil, nil)
+
+	issued, err := auth.IssueAPIKey(ctx, domain.APIKeyRequest{Name: "backend", Subject: "svc-1", Roles: []string{"operator"}})
+	require.NoError(t, err)
+	assert.Contains(t, issued.Key, issued.Prefix)
+
+	principal, err := auth.Authenticate(ctx, domain.Credentials{APIKey: issued.Key})
+	require.NoError(t, err)
+	assert.Equal(t, "svc-1", principal.Subject)
+	assert.Equal(t, domain.AuthAPIKey, principal.Method)
+	assert.Equal(t, []string{"operator"}, principal.Roles)
+	assert.Equal(t, issued.ID, *principal.KeyID)
+
+	_, err = auth.Authenticate(ctx, domain.Credentials{APIKey: issued.Key + "x"})
+	assert.ErrorIs(t, err, app_errors.ErrInvalidCredentials)
+
+	_, err = auth.Authenticate(ctx, domain.Credentials{})
+	assert.ErrorIs(t, err, app_errors.ErrUnauthenticated)
+
+	_, err = auth.Authenticate(ctx, domain.Credentials{APIKey: issued.Key, BearerToken: "token"})
+	assert.ErrorIs(t, err, app_errors.ErrAmbiguousCredentials)
+
+	// Без настроенного алгоритма токены не принимаются
+	_, err = auth.Authenticate(ctx, domain.Credentials{BearerToken: "token"})
+	assert.ErrorIs(t, err, app_errors.ErrInvalidCredentials)
+
+	expiring, err := auth.IssueAPIKey(ctx, domain.APIKeyRequest{Name: "temp", Subject: "svc-2", TTL: time.Nanosecond})
+	require.NoError(t, err)
+	time.Sleep(time.Millisecond)
+	_, err = auth.Authenticate(ctx, domain.Credentials{APIKey: expiring.Key})
+	assert.ErrorIs(t, err, app_errors.ErrInvalidCredentials)
+}
+
+func TestAuthenticateMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuth := mocks.NewMockAuth(ctrl)
+	principal := domain.Principal{Subject: "user-1", Method: domain.AuthJWT}
+
+	router := gin.New()
+	router.GET("/protected", delivery.Authenticate(mockAuth), func(c *gin.Context) {
+		// Обработчик получает клиента из контекста запроса
+		got, ok := domain.PrincipalFromContext(c.Request.Context())
+		require.True(t, ok)
+		c.String(http.StatusOK, got.Subject)
+	})
+
+	tests := []struct {
+		name        string
+		headers     map[string]string
+		credentials *domain.Credentials
+		authErr     error
+		wantStatus  int
+		wantCode    string
+	}{
+		{
+			name:        "bearer token",
+			headers:     map[string]string{delivery.AuthorizationHeader: "Bearer abc.def.ghi"},
+			credentials: &domain.Credentials{BearerToken: "abc.def.ghi"},
+			wantStatus:  http.StatusOK,
+		},
+		{
+			name:        "api key",
+			headers:     map[string]string{delivery.APIKeyHeader: "wk_key"},
+			credentials: &domain.Credentials{APIKey: "wk_key"},
+			wantStatus:  http.StatusOK,
+		},
+		{
+			name:        "no credentials",
+			credentials: &domain.Credentials{},
+			authErr:     app_errors.ErrUnauthenticated,
+			wantStatus:  http.StatusUnauthorized,
+			wantCode:    "unauthenticated",
+		},
+		{
+			name:       "basic scheme",
+			headers:    map[string]string{delivery.AuthorizationHeader: "Basic dXNlcjpwYXNz"},
+			wantStatus: http.StatusUnauthorized,
+			wantCode:   "invalid_credentials",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.credentials != nil {
+				mockAuth.EXPECT().Authenticate(gomock.Any(), *tt.credentials).Return(principal, tt.authErr).Times(1)
+			}
+
+			req, _ := http.NewRequest(http.MethodGet, "/protected", nil)
+			for name, value := range tt.headers {
+				req.Header.Set(name, value)
+			}
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.wantStatus, resp.Code)
+			if tt.wantCode == "" {
+				assert.Equal(t, principal.Subject, resp.Body.String())
+				return
+			}
+			var problem delivery.ProblemDetails
+			require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &problem))
+			assert.Equal(t, tt.wantCode, problem.Code)
+			assert.NotEmpty(t, resp.Header().Get("WWW-Authenticate"))
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"wallet-app/internal/app/app_errors"
+	"wallet-app/internal/app/domain"
+)
+
+// as возвращает контекст запроса клиента subject с ролями roles
+func as(subject string, roles ...domain.Role) context.Context {
+	principal := domain.Principal{Subject: subject, Method: domain.AuthAPIKey}
+	for _, role := range roles {
+		principal.Roles = append(principal.Roles, string(role))
+	}
+	return domain.ContextWithPrincipal(context.Background(), principal)
+}
+
+func TestRolePermissions(t *testing.T) {
+	tests := []struct {
+		role    domain.Role
+		allowed []domain.Permission
+	}{
+		{domain.RoleOwner, []domain.Permission{domain.PermissionRead, domain.PermissionOperate, domain.PermissionManage}},
+		{domain.RoleOperator, []domain.Permission{domain.PermissionRead, domain.PermissionOperate}},
+		{domain.RoleViewer, []domain.Permission{domain.PermissionRead}},
+		{domain.RoleAdmin, []domain.Permission{domain.PermissionRead, domain.PermissionOperate, domain.PermissionManage, domain.PermissionAdmin}},
+		{"unknown", nil},
+	}
+
+	all := []domain.Permission{domain.PermissionRead, domain.PermissionOperate, domain.PermissionManage, domain.PermissionAdmin}
+	for _, tt := range tests {
+		for _, permission := range all {
+			assert.Equal(t, slices.Contains(tt.allowed, permission), tt.role.Allows(permission),
+				"role %s, permission %s", tt.role, permission)
+		}
+	}
+
+	// Роль owner в ключе или токене не дает доступа к чужим кошелькам
+	principal := domain.Principal{Subject: "user-1", Roles: []string{"owner"}}
+	assert.False(t, principal.Allows(domain.PermissionRead))
+}
+
+func TestWalletAuthorization(t *testing.T) {
+	service := newMemoryService(false)
+	alice, mallory, victor := as("alice"), as("mallory"), as("victor")
+
+	// Кошелек без владельца принадлежит создавшему его клиенту
+	wallet, err := service.CreateWallet(alice, domain.CreateWalletRequest{})
+	require.NoError(t, err)
+	require.Equal(t, "alice", wallet.OwnerID)
+
+	deposit := domain.WalletOperation{WalletID: wallet.ID, OperationType: domain.Deposit, Amount: "100"}
+	withdraw := domain.WalletOperation{WalletID: wallet.ID, OperationType: domain.Withdraw, Amount: "10"}
+
+	_, err = service.ProcessOperation(alice, deposit)
+	require.NoError(t, err)
+
+	// Чужой клиент не видит баланс и не двигает средства
+	_, err = service.GetBalance(mallory, wallet.ID)
+	assert.ErrorIs(t, err, app_errors.ErrForbidden)
+	_, err = service.ProcessOperation(mallory, withdraw)
+	assert.ErrorIs(t, err, app_errors.ErrForbidden)
+	_, err = service.ListTransactions(mallory, wallet.ID, domain.TransactionFilter{})
+	assert.ErrorIs(t, err, app_errors.ErrForbidden)
+	_, err = service.ListWalletsByOwner(mallory, "alice")
+	assert.ErrorIs(t, err, app_errors.ErrForbidden)
+	_, err = service.CreateWallet(mallory, domain.CreateWalletRequest{OwnerID: "alice"})
+	assert.ErrorIs(t, err, app_errors.ErrForbidden)
+	_, err = service.CreateHold(mallory, wallet.ID, domain.HoldRequest{Amount: "1"})
+	assert.ErrorIs(t, err, app_errors.ErrForbidden)
+
+	malloryWallet, err := service.CreateWallet(mallory, domain.CreateWalletRequest{})
+	require.NoError(t, err)
+	_, err = service.Transfer(mallory, domain.TransferOperation{FromWalletID: wallet.ID, ToWalletID: malloryWallet.ID, Amount: "1"})
+	assert.ErrorIs(t, err, app_errors.ErrForbidden)
+	_, err = service.GrantAccess(mallory, wallet.ID, "mallory", domain.WalletGrantRequest{Role: domain.RoleOwner})
+	assert.ErrorIs(t, err, app_errors.ErrForbidden)
+
+	// Несуществующий кошелек не найден, а не запрещен
+	_, err = service.GetBalance(mallory, uuid.New())
+	assert.ErrorIs(t, err, app_errors.ErrWalletNotFound)
+
+	// Наблюдатель только читает
+	grant, err := service.GrantAccess(alice, wallet.ID, "victor", domain.WalletGrantRequest{Role: domain.RoleViewer})
+	require.NoError(t, err)
+	assert.Equal(t, "alice", grant.GrantedBy)
+
+	balance, err := service.GetBalance(victor, wallet.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "100", balance.Total.String())
+	_, err = service.ProcessOperation(victor, withdraw)
+	assert.ErrorIs(t, err, app_errors.ErrForbidden)
+	_, err = service.ListGrants(victor, wallet.ID)
+	assert.ErrorIs(t, err, app_errors.ErrForbidden)
+
+	// Оператор двигает средства, в том числе переводом
+	_, err = service.GrantAccess(alice, wallet.ID, "victor", domain.WalletGrantRequest{Role: domain.RoleOperator})
+	require.NoError(t, err)
+	_, err = service.ProcessOperation(victor, withdraw)
+	require.NoError(t, err)
+	_, err = service.Transfer(victor, domain.TransferOperation{FromWalletID: wallet.ID, ToWalletID: malloryWallet.ID, Amount: "5"})
+	require.NoError(t, err)
+
+	grants, err := service.ListGrants(alice, wallet.ID)
+	require.NoError(t, err)
+	require.Len(t, grants.Grants, 1)
+	assert.Equal(t, domain.RoleOperator, grants.Grants[0].Role)
+
+	// После отзыва доступа нет
+	require.NoError(t, service.RevokeAccess(alice, wallet.ID, "victor"))
+	assert.ErrorIs(t, service.RevokeAccess(alice, wallet.ID, "victor"), app_errors.ErrGrantNotFound)
+	_, err = service.GetBalance(victor, wallet.ID)
+	assert.ErrorIs(t, err, app_errors.ErrForbidden)
+
+	// Роли из ключа действуют на все кошельки
+	_, err = service.GetBalance(as("auditor", domain.RoleViewer), wallet.ID)
+	require.NoError(t, err)
+	_, err = service.ProcessOperation(as("auditor", domain.RoleViewer), withdraw)
+	assert.ErrorIs(t, err, app_errors.ErrForbidden)
+	_, err = service.ProcessOperation(as("backend", domain.RoleOperator), withdraw)
+	require.NoError(t, err)
+
+	// Административные действия доступны только администратору
+	_, err = service.VerifyLedger(alice)
+	assert.ErrorIs(t, err, app_errors.ErrForbidden)
+	_, err = service.SetWalletStatus(alice, wallet.ID, domain.WalletFrozen, domain.WalletStatusRequest{})
+	assert.ErrorIs(t, err, app_errors.ErrForbidden)
+	_, err = service.SetWalletStatus(as("root", domain.RoleAdmin), wallet.ID, domain.WalletFrozen, domain.WalletStatusRequest{})
+	require.NoError(t, err)
+
+	// Вызовы без клиента в контексте выполняются изнутри приложения и не проверяются
+	_, err = service.GetBalance(context.Background(), wallet.ID)
+	require.NoError(t, err)
+}
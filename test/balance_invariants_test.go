@@ -0,0 +1,198 @@
+package test
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"wallet-app/internal/app/app_errors"
+	"wallet-app/internal/app/domain"
+	"wallet-app/internal/app/services"
+)
+
+// invariantsSeed позволяет воспроизвести упавший прогон: go test ./test -run Invariants -invariants.seed=<seed>
+var invariantsSeed = flag.Uint64("invariants.seed", 0, "seed for randomized balance invariant tests (0 — random)")
+
+// invariantModel — ожидаемое состояние кошельков по успешно выполненным операциям
+type invariantModel struct {
+	mu         sync.Mutex
+	balances   map[uuid.UUID]decimal.Decimal
+	operations map[uuid.UUID]int
+}
+
+func (m *invariantModel) apply(walletID uuid.UUID, amount decimal.Decimal) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.balances[walletID] = m.balances[walletID].Add(amount)
+	m.operations[walletID]++
+}
+
+// randomAmount возвращает сумму от 0.01 до max с точностью до копейки
+func randomAmount(rng *rand.Rand, max int) decimal.Decimal {
+	return decimal.New(int64(1+rng.IntN(max*100)), -2)
+}
+
+// TestBalanceInvariants выполняет случайные чередования зачислений, списаний и переводов между
+// несколькими кошельками и проверяет инварианты: баланс никогда не уходит в минус, сумма операций
+// кошелька равна его балансу, ни одно успешное изменение не потеряно.
+func TestBalanceInvariants(t *testing.T) {
+	seed := *invariantsSeed
+	if seed == 0 {
+		seed = uint64(time.Now().UnixNano())
+	}
+	t.Logf("seed: %d", seed)
+
+	rounds, workers, opsPerWorker := 5, 16, 40
+	if testing.Short() {
+		rounds, opsPerWorker = 1, 15
+	}
+
+	for _, batching := range []bool{false, true} {
+		for round := 0; round < rounds; round++ {
+			roundSeed := seed + uint64(round)
+			t.Run(fmt.Sprintf("batching=%t/seed=%d", batching, roundSeed), func(t *testing.T) {
+				checkBalanceInvariants(t, newMemoryService(batching), roundSeed, workers, opsPerWorker)
+			})
+		}
+	}
+}
+
+func checkBalanceInvariants(t *testing.T, service *services.Service, seed uint64, workers, opsPerWorker int) {
+	ctx := context.Background()
+	rng := rand.New(rand.NewPCG(seed, seed))
+
+	model := &invariantModel{balances: map[uuid.UUID]decimal.Decimal{}, operations: map[uuid.UUID]int{}}
+	wallets := make([]uuid.UUID, 2+rng.IntN(3))
+	for i := range wallets {
+		wallet, err := service.CreateWallet(ctx, domain.CreateWalletRequest{Currency: "USD"})
+		require.NoError(t, err)
+		wallets[i] = wallet.ID
+		model.balances[wallet.ID] = decimal.Zero
+	}
+
+	// У каждого исполнителя свой генератор: последовательность операций определяется seed,
+	// а их чередование — планировщиком
+	seeds := make([]uint64, workers)
+	for i := range seeds {
+		seeds[i] = rng.Uint64()
+	}
+
+	stop := make(chan struct{})
+	var observer sync.WaitGroup
+	observer.Add(1)
+	go func() {
+		defer observer.Done()
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			for _, walletID := range wallets {
+				balance, err := service.GetBalance(ctx, walletID)
+				if assert.NoError(t, err) {
+					assert.False(t, balance.Total.IsNegative(), "negative balance observed: %s", balance.Total)
+				}
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for _, workerSeed := range seeds {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rng := rand.New(rand.NewPCG(workerSeed, workerSeed))
+			for i := 0; i < opsPerWorker; i++ {
+				runRandomOperation(t, ctx, service, model, rng, wallets)
+			}
+		}()
+	}
+	wg.Wait()
+	close(stop)
+	observer.Wait()
+
+	for _, walletID := range wallets {
+		balance, err := service.GetBalance(ctx, walletID)
+		require.NoError(t, err)
+
+		// Нет потерянных обновлений: баланс равен сумме успешных операций
+		expected := model.balances[walletID]
+		assert.True(t, balance.Total.Equal(expected), "wallet %s: balance %s, expected %s", walletID, balance.Total, expected)
+		assert.False(t, balance.Total.IsNegative())
+
+		// История кошелька полна и сходится с балансом
+		sum, count := decimal.Zero, 0
+		filter := domain.TransactionFilter{Limit: domain.MaxTransactionsLimit}
+		for {
+			page, err := service.ListTransactions(ctx, walletID, filter)
+			require.NoError(t, err)
+			for _, transaction := range page.Transactions {
+				sum = sum.Add(transaction.Amount)
+				count++
+			}
+			if page.NextCursor == "" {
+				break
+			}
+			filter.Cursor = page.NextCursor
+		}
+		assert.True(t, sum.Equal(balance.Total), "wallet %s: transactions sum %s, balance %s", walletID, sum, balance.Total)
+		assert.Equal(t, model.operations[walletID], count)
+	}
+
+	report, err := service.VerifyLedger(ctx)
+	require.NoError(t, err)
+	assert.True(t, report.Balanced)
+}
+
+// runRandomOperation выполняет случайную операцию и записывает ее результат в модель.
+// Отказ из-за недостатка средств — допустимый исход; любая другая ошибка проваливает тест.
+func runRandomOperation(t *testing.T, ctx context.Context, service *services.Service, model *invariantModel,
+	rng *rand.Rand, wallets []uuid.UUID) {
+	walletID := wallets[rng.IntN(len(wallets))]
+
+	switch rng.IntN(3) {
+	case 0:
+		amount := randomAmount(rng, 50)
+		_, err := service.ProcessOperation(ctx, domain.WalletOperation{
+			WalletID: walletID, OperationType: domain.Deposit, Amount: amount.String(),
+		})
+		if assert.NoError(t, err) {
+			model.apply(walletID, amount)
+		}
+	case 1:
+		amount := randomAmount(rng, 40)
+		_, err := service.ProcessOperation(ctx, domain.WalletOperation{
+			WalletID: walletID, OperationType: domain.Withdraw, Amount: amount.String(),
+		})
+		if err == nil {
+			model.apply(walletID, amount.Neg())
+		} else {
+			assert.ErrorIs(t, err, app_errors.ErrInsufficientFunds)
+		}
+	default:
+		toWalletID := wallets[rng.IntN(len(wallets))]
+		if toWalletID == walletID {
+			return
+		}
+		amount := randomAmount(rng, 40)
+		_, err := service.Transfer(ctx, domain.TransferOperation{
+			FromWalletID: walletID, ToWalletID: toWalletID, Amount: amount.String(),
+		})
+		if err == nil {
+			model.apply(walletID, amount.Neg())
+			model.apply(toWalletID, amount)
+		} else {
+			assert.ErrorIs(t, err, app_errors.ErrInsufficientFunds)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/gin-gonic/gin"
@@ -26,9 +27,10 @@ func TestCreateWallet(t *testing.T) {
 	mockService := mocks.NewMockWallet(ctrl)
 
 	// Настроим ожидания на мок-сервис
-	mockService.EXPECT().CreateWallet(gomock.Any()).Return(domain.Wallet{
-		ID:      uuid.New(),
-		Balance: decimal.NewFromInt(0),
+	mockService.EXPECT().CreateWallet(gomock.Any(), domain.CreateWalletRequest{}).Return(domain.Wallet{
+		ID:       uuid.New(),
+		Balance:  decimal.NewFromInt(0),
+		Currency: domain.DefaultCurrency,
 	}, nil).Times(1)
 
 	// Создаем сервис, передавая мок-сервис, реализующий интерфейс Wallet
@@ -54,4 +56,207 @@ func TestCreateWallet(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Contains(t, response, "walletId")
 	assert.Contains(t, response, "balance")
+	assert.Equal(t, domain.DefaultCurrency, response["currency"])
+}
+
+func TestCreateWallet_WithCurrency(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockWallet(ctrl)
+	mockService.EXPECT().CreateWallet(gomock.Any(), domain.CreateWalletRequest{Currency: "JPY"}).Return(domain.Wallet{
+		ID:       uuid.New(),
+		Balance:  decimal.Zero,
+		Currency: "JPY",
+	}, nil).Times(1)
+
+	h := delivery.NewHandler(&services.Service{Wallet: mockService})
+	router := gin.Default()
+	router.POST("/api/v1/create-wallet", h.CreateWallet)
+
+	req, _ := http.NewRequest("POST", "/api/v1/create-wallet", strings.NewReader(`{"currency":"JPY"}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusCreated, resp.Code)
+
+	var response domain.Wallet
+	err := json.Unmarshal(resp.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, "JPY", response.Currency)
+}
+
+func TestCreateWallet_UnsupportedCurrency(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Сервис не должен вызываться для неизвестной валюты
+	mockService := mocks.NewMockWallet(ctrl)
+
+	h := delivery.NewHandler(&services.Service{Wallet: mockService})
+	router := gin.Default()
+	router.POST("/api/v1/create-wallet", h.CreateWallet)
+
+	req, _ := http.NewRequest("POST", "/api/v1/create-wallet", strings.NewReader(`{"currency":"XXX"}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
+
+func TestCreateWallet_WithOwnerAndMetadata(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	request := domain.CreateWalletRequest{
+		OwnerID:     "customer-42",
+		DisplayName: "Основной",
+		Metadata:    map[string]any{"segment": "retail"},
+		Labels:      []string{"primary"},
+	}
+
+	mockService := mocks.NewMockWallet(ctrl)
+	mockService.EXPECT().CreateWallet(gomock.Any(), request).Return(domain.Wallet{
+		ID:          uuid.New(),
+		Balance:     decimal.Zero,
+		Currency:    domain.DefaultCurrency,
+		Status:      domain.WalletActive,
+		OwnerID:     request.OwnerID,
+		DisplayName: request.DisplayName,
+		Metadata:    request.Metadata,
+		Labels:      request.Labels,
+	}, nil).Times(1)
+
+	h := delivery.NewHandler(&services.Service{Wallet: mockService})
+	router := gin.Default()
+	router.POST("/api/v1/create-wallet", h.CreateWallet)
+
+	body := `{"ownerId":"customer-42","displayName":"Основной","metadata":{"segment":"retail"},"labels":["primary"]}`
+	req, _ := http.NewRequest("POST", "/api/v1/create-wallet", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusCreated, resp.Code)
+
+	var response domain.Wallet
+	err := json.Unmarshal(resp.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, "customer-42", response.OwnerID)
+	assert.Equal(t, "retail", response.Metadata["segment"])
+	assert.Equal(t, []string{"primary"}, response.Labels)
+}
+
+func TestCreateWallet_InvalidLabels(t *testing.T) {
+	// Сервис не должен вызываться при пустой метке
+	h := delivery.NewHandler(nil)
+	router := gin.Default()
+	router.POST("/api/v1/create-wallet", h.CreateWallet)
+
+	req, _ := http.NewRequest("POST", "/api/v1/create-wallet", strings.NewReader(`{"labels":[""]}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
+
+func TestCreateWallet_Sharded(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockWallet(ctrl)
+	mockService.EXPECT().CreateWallet(gomock.Any(), domain.CreateWalletRequest{Shards: 16}).Return(domain.Wallet{
+		ID:       uuid.New(),
+		Balance:  decimal.Zero,
+		Currency: domain.DefaultCurrency,
+		Shards:   16,
+	}, nil).Times(1)
+
+	h := delivery.NewHandler(&services.Service{Wallet: mockService})
+	router := gin.Default()
+	router.POST("/api/v1/create-wallet", h.CreateWallet)
+
+	req, _ := http.NewRequest("POST", "/api/v1/create-wallet", strings.NewReader(`{"shards":16}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusCreated, resp.Code)
+
+	var response domain.Wallet
+	err := json.Unmarshal(resp.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, 16, response.Shards)
+}
+
+func TestCreateWallet_InvalidShards(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		message string
+	}{
+		{"single shard", `{"shards":1}`, "shards must be at least 2"},
+		{"too many shards", `{"shards":65}`, "shards must be at most 64"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Сервис не должен вызываться
+			h := delivery.NewHandler(nil)
+			router := gin.Default()
+			router.POST("/api/v1/create-wallet", h.CreateWallet)
+
+			req, _ := http.NewRequest("POST", "/api/v1/create-wallet", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+			var problem delivery.ProblemDetails
+			assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &problem))
+			assert.Equal(t, []domain.FieldError{{Field: "shards", Message: tt.message}}, problem.Errors)
+		})
+	}
+}
+
+func TestListWalletsByOwner(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockWallet(ctrl)
+	mockService.EXPECT().ListWalletsByOwner(gomock.Any(), "customer-42").Return(domain.WalletsByOwner{
+		OwnerID: "customer-42",
+		Wallets: []domain.Wallet{{ID: uuid.New(), OwnerID: "customer-42"}, {ID: uuid.New(), OwnerID: "customer-42"}},
+	}, nil).Times(1)
+
+	h := delivery.NewHandler(&services.Service{Wallet: mockService})
+	router := gin.Default()
+	router.GET("/api/v1/wallets", h.ListWalletsByOwner)
+
+	req, _ := http.NewRequest("GET", "/api/v1/wallets?ownerId=customer-42", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var response domain.WalletsByOwner
+	err := json.Unmarshal(resp.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Len(t, response.Wallets, 2)
+}
+
+func TestListWalletsByOwner_MissingOwner(t *testing.T) {
+	h := delivery.NewHandler(nil)
+	router := gin.Default()
+	router.GET("/api/v1/wallets", h.ListWalletsByOwner)
+
+	req, _ := http.NewRequest("GET", "/api/v1/wallets", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
 }
@@ -0,0 +1,161 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"wallet-app/internal/app/app_errors"
+	delivery "wallet-app/internal/app/delivery/http"
+	"wallet-app/internal/app/domain"
+	"wallet-app/internal/app/services"
+	"wallet-app/internal/app/services/mocks"
+)
+
+func TestChangeBalance_ErrorMapping(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+		wantTitle  string
+	}{
+		{"insufficient funds", app_errors.ErrInsufficientFunds, http.StatusUnprocessableEntity, "insufficient_funds", "insufficient funds"},
+		{"wallet not found", app_errors.ErrWalletNotFound, http.StatusNotFound, "wallet_not_found", "wallet not found"},
+		{
+			"forbidden",
+			app_errors.ErrForbidden.Wrap(errors.New(`subject "mallory" has no operate permission`)),
+			http.StatusForbidden, "forbidden", "principal is not allowed to perform this action",
+		},
+		{"wallet frozen", app_errors.ErrWalletFrozen, http.StatusConflict, "wallet_frozen", "wallet is frozen: debits are not allowed"},
+		{
+			"serialization failure",
+			app_errors.ErrConcurrentUpdate.Wrap(errors.New("ERROR: could not serialize access (SQLSTATE 40001)")),
+			http.StatusConflict, "concurrent_update", "concurrent update, retry the request",
+		},
+		{
+			"database unavailable",
+			app_errors.ErrUnavailable.Wrap(errors.New("dial tcp 10.0.0.1:5432: connection refused")),
+			http.StatusServiceUnavailable, "service_unavailable", "storage is temporarily unavailable",
+		},
+		// Неизвестная ошибка не раскрывает клиенту внутренние детали
+		{"unknown error", errors.New("pq: relation wallets does not exist"), http.StatusInternalServerError, "internal_error", "internal error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockService := mocks.NewMockWallet(ctrl)
+			mockService.EXPECT().ProcessOperation(gomock.Any(), gomock.Any()).Return(domain.Transaction{}, tt.err).Times(1)
+
+			h := delivery.NewHandler(&services.Service{Wallet: mockService})
+			router := gin.New()
+			router.POST("/api/v1/wallets/change-balance", h.ChangeBalance)
+
+			requestBody, err := json.Marshal(map[string]string{
+				"walletId":      uuid.New().String(),
+				"operationType": "WITHDRAW",
+				"amount":        "100.00",
+			})
+			assert.NoError(t, err)
+
+			req, _ := http.NewRequest("POST", "/api/v1/wallets/change-balance", bytes.NewBuffer(requestBody))
+			req.Header.Set("Content-Type", "application/json")
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.wantStatus, resp.Code)
+
+			var response map[string]interface{}
+			assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &response))
+			assert.Equal(t, tt.wantCode, response["code"])
+			assert.Equal(t, tt.wantTitle, response["title"])
+		})
+	}
+}
+
+func TestChangeBalance_InvalidJSON(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	h := delivery.NewHandler(nil)
+	router := gin.New()
+	router.POST("/api/v1/wallets/change-balance", h.ChangeBalance)
+
+	req, _ := http.NewRequest("POST", "/api/v1/wallets/change-balance", bytes.NewBufferString("{"))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	var response map[string]interface{}
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &response))
+	assert.Equal(t, "invalid_request", response["code"])
+}
+
+func TestProblemDetails_ValidationErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	h := delivery.NewHandler(nil)
+	router := gin.New()
+	router.Use(delivery.RequestID())
+	router.POST("/api/v1/wallet", h.ChangeBalance)
+
+	// Не указан walletId и неизвестный тип операции
+	requestBody := `{"operationType": "STEAL", "amount": "100"}`
+	req, _ := http.NewRequest("POST", "/api/v1/wallet", bytes.NewBufferString(requestBody))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(delivery.RequestIDHeader, "req-123")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.Equal(t, "application/problem+json", resp.Header().Get("Content-Type"))
+	assert.Equal(t, "req-123", resp.Header().Get(delivery.RequestIDHeader))
+
+	var problem delivery.ProblemDetails
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &problem))
+	assert.Equal(t, "urn:wallet-app:problem:validation_failed", problem.Type)
+	assert.Equal(t, http.StatusBadRequest, problem.Status)
+	assert.Equal(t, "validation_failed", problem.Code)
+	assert.Equal(t, "/api/v1/wallet", problem.Instance)
+	assert.Equal(t, "req-123", problem.RequestID)
+	assert.Equal(t, []domain.FieldError{
+		{Field: "walletId", Message: "walletId is required"},
+		{Field: "operationType", Message: "operationType must be one of: DEPOSIT WITHDRAW REVERSAL REFUND"},
+	}, problem.Errors)
+}
+
+func TestRequestID_Generated(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	h := delivery.NewHandler(nil)
+	router := gin.New()
+	router.Use(delivery.RequestID())
+	router.GET("/api/v1/wallets/:walletId", h.GetBalance)
+
+	req, _ := http.NewRequest("GET", "/api/v1/wallets/invalid-uuid", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	requestID := resp.Header().Get(delivery.RequestIDHeader)
+	assert.NotEmpty(t, requestID)
+
+	var problem delivery.ProblemDetails
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &problem))
+	assert.Equal(t, requestID, problem.RequestID)
+	assert.Equal(t, "invalid_uuid", problem.Code)
+	assert.Nil(t, problem.Errors)
+}
@@ -0,0 +1,125 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	"wallet-app/internal/app/app_errors"
+	delivery "wallet-app/internal/app/delivery/http"
+	"wallet-app/internal/app/domain"
+	"wallet-app/internal/app/services"
+	"wallet-app/internal/app/services/mocks"
+)
+
+func TestGetBalance_ETag(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name     string
+		version  int64
+		wantETag string
+	}{
+		{"wallet with version", 7, `"7"`},
+		// Шардированный кошелек версию не возвращает
+		{"sharded wallet", 0, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			walletID := uuid.New()
+			mockWallet := mocks.NewMockWallet(ctrl)
+			mockWallet.EXPECT().GetBalance(gomock.Any(), walletID).
+				Return(domain.WalletBalance{Total: decimal.NewFromInt(100), Version: tt.version}, nil).Times(1)
+
+			h := delivery.NewHandler(&services.Service{Wallet: mockWallet})
+			router := gin.New()
+			router.GET("/api/v1/wallets/:walletId", h.GetBalance)
+
+			req, _ := http.NewRequest("GET", "/api/v1/wallets/"+walletID.String(), nil)
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, http.StatusOK, resp.Code)
+			assert.Equal(t, tt.wantETag, resp.Header().Get(delivery.ETagHeader))
+		})
+	}
+}
+
+func TestChangeBalance_IfMatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	version := int64(7)
+	tests := []struct {
+		name            string
+		ifMatch         string
+		wantVersion     *int64
+		serviceErr      error
+		wantStatus      int
+		wantCode        string
+		serviceExpected bool
+	}{
+		{"no header", "", nil, nil, http.StatusOK, "", true},
+		{"any version", "*", nil, nil, http.StatusOK, "", true},
+		{"matching version", `"7"`, &version, nil, http.StatusOK, "", true},
+		{"wallet modified", `"7"`, &version, app_errors.ErrPreconditionFailed, http.StatusPreconditionFailed, "precondition_failed", true},
+		{"weak etag", `W/"7"`, nil, nil, http.StatusBadRequest, "invalid_if_match", false},
+		{"unquoted", "7", nil, nil, http.StatusBadRequest, "invalid_if_match", false},
+		{"not a number", `"abc"`, nil, nil, http.StatusBadRequest, "invalid_if_match", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			walletID := uuid.New()
+			mockWallet := mocks.NewMockWallet(ctrl)
+			if tt.serviceExpected {
+				mockWallet.EXPECT().ProcessOperation(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ any, op domain.WalletOperation) (domain.Transaction, error) {
+						assert.Equal(t, tt.wantVersion, op.ExpectedVersion)
+						return domain.Transaction{ID: uuid.New(), WalletID: walletID}, tt.serviceErr
+					},
+				).Times(1)
+			}
+
+			h := delivery.NewHandler(&services.Service{Wallet: mockWallet})
+			router := gin.New()
+			router.POST("/api/v1/wallet", h.ChangeBalance)
+
+			requestBody, err := json.Marshal(map[string]string{
+				"walletId":      walletID.String(),
+				"operationType": "DEPOSIT",
+				"amount":        "100.00",
+			})
+			assert.NoError(t, err)
+
+			req, _ := http.NewRequest("POST", "/api/v1/wallet", bytes.NewBuffer(requestBody))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.ifMatch != "" {
+				req.Header.Set(delivery.IfMatchHeader, tt.ifMatch)
+			}
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.wantStatus, resp.Code)
+			if tt.wantCode != "" {
+				var problem delivery.ProblemDetails
+				assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &problem))
+				assert.Equal(t, tt.wantCode, problem.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	"wallet-app/internal/app/app_errors"
+	delivery "wallet-app/internal/app/delivery/http"
+	"wallet-app/internal/app/domain"
+	"wallet-app/internal/app/services"
+	"wallet-app/internal/app/services/mocks"
+)
+
+func TestCreateQuote(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	quoteID := uuid.New()
+
+	mockFX := mocks.NewMockFX(ctrl)
+	mockFX.EXPECT().CreateQuote(gomock.Any(), domain.FXQuoteRequest{FromCurrency: "USD", ToCurrency: "JPY"}).
+		Return(domain.FXQuote{
+			ID:           quoteID,
+			FromCurrency: "USD",
+			ToCurrency:   "JPY",
+			Rate:         decimal.RequireFromString("151.6"),
+			ExpiresAt:    time.Now().Add(30 * time.Second),
+		}, nil).Times(1)
+
+	h := delivery.NewHandler(&services.Service{FX: mockFX})
+	router := gin.Default()
+	router.POST("/api/v1/fx/quotes", h.CreateQuote)
+
+	requestBody, err := json.Marshal(map[string]string{"fromCurrency": "USD", "toCurrency": "JPY"})
+	assert.NoError(t, err)
+
+	req, _ := http.NewRequest("POST", "/api/v1/fx/quotes", bytes.NewBuffer(requestBody))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusCreated, resp.Code)
+
+	var response domain.FXQuote
+	err = json.Unmarshal(resp.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, quoteID, response.ID)
+	assert.True(t, decimal.RequireFromString("151.6").Equal(response.Rate))
+}
+
+func TestCreateQuote_SameCurrency(t *testing.T) {
+	// Сервис не должен вызываться для котировки валюты к самой себе
+	h := delivery.NewHandler(nil)
+	router := gin.Default()
+	router.POST("/api/v1/fx/quotes", h.CreateQuote)
+
+	requestBody, err := json.Marshal(map[string]string{"fromCurrency": "USD", "toCurrency": "USD"})
+	assert.NoError(t, err)
+
+	req, _ := http.NewRequest("POST", "/api/v1/fx/quotes", bytes.NewBuffer(requestBody))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
+
+func TestTransfer_QuoteNotActive(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	fromID := uuid.New()
+	toID := uuid.New()
+	quoteID := uuid.New()
+
+	mockService := mocks.NewMockWallet(ctrl)
+	mockService.EXPECT().Transfer(gomock.Any(), domain.TransferOperation{
+		FromWalletID: fromID,
+		ToWalletID:   toID,
+		Amount:       "10",
+		QuoteID:      &quoteID,
+	}).Return(domain.TransferResult{}, app_errors.ErrQuoteNotActive).Times(1)
+
+	h := delivery.NewHandler(&services.Service{Wallet: mockService})
+	router := gin.Default()
+	router.POST("/api/v1/transfer", h.Transfer)
+
+	requestBody, err := json.Marshal(map[string]string{
+		"fromWalletId": fromID.String(),
+		"toWalletId":   toID.String(),
+		"amount":       "10",
+		"quoteId":      quoteID.String(),
+	})
+	assert.NoError(t, err)
+
+	req, _ := http.NewRequest("POST", "/api/v1/transfer", bytes.NewBuffer(requestBody))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusConflict, resp.Code)
+}
+
+func TestQuoteConvert_Rounding(t *testing.T) {
+	quote := domain.FXQuote{ID: uuid.New(), FromCurrency: "USD", ToCurrency: "EUR", Rate: decimal.RequireFromString("0.925")}
+
+	tests := []struct {
+		mode     domain.RoundingMode
+		amount   string
+		expected string
+	}{
+		// 10.10 * 0.925 = 9.3425
+		{mode: domain.RoundHalfEven, amount: "10.10", expected: "9.34"},
+		{mode: domain.RoundHalfUp, amount: "10.10", expected: "9.34"},
+		{mode: domain.RoundDown, amount: "10.10", expected: "9.34"},
+		// 1 * 0.925 = 0.925: половина округляется к четному или от нуля
+		{mode: domain.RoundHalfEven, amount: "1", expected: "0.92"},
+		{mode: domain.RoundHalfUp, amount: "1", expected: "0.93"},
+		{mode: domain.RoundDown, amount: "1", expected: "0.92"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.mode)+"_"+tt.amount, func(t *testing.T) {
+			conversion, err := quote.Convert(decimal.RequireFromString(tt.amount), tt.mode)
+			assert.NoError(t, err)
+			assert.True(t, decimal.RequireFromString(tt.expected).Equal(conversion.ConvertedAmount),
+				"got %s", conversion.ConvertedAmount)
+		})
+	}
+
+	// Сумма меньше минимальной единицы валюты зачисления не переводится
+	_, err := quote.Convert(decimal.RequireFromString("0.001"), domain.RoundDown)
+	assert.ErrorIs(t, err, app_errors.ErrConvertedAmountTooSmall)
+}
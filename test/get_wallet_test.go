@@ -2,7 +2,6 @@ package test
 
 import (
 	"encoding/json"
-	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -13,6 +12,7 @@ import (
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 
+	"wallet-app/internal/app/app_errors"
 	delivery "wallet-app/internal/app/delivery/http"
 	"wallet-app/internal/app/domain"
 	"wallet-app/internal/app/services"
@@ -31,7 +31,11 @@ func TestGetBalance(t *testing.T) {
 	mockWallet := mocks.NewMockWallet(ctrl)
 
 	// Ожидаем, что метод GetBalance будет вызван с walletID и вернет нужный баланс
-	expectedBalance := decimal.NewFromInt(100)
+	expectedBalance := domain.WalletBalance{
+		Available: decimal.NewFromInt(70),
+		Held:      decimal.NewFromInt(30),
+		Total:     decimal.NewFromInt(100),
+	}
 	mockWallet.
 		EXPECT().
 		GetBalance(gomock.Any(), gomock.Eq(walletID)). // Ожидаем вызов именно с этим UUID
@@ -57,7 +61,9 @@ func TestGetBalance(t *testing.T) {
 	var response domain.WalletBalance
 	err := json.Unmarshal(resp.Body.Bytes(), &response)
 	assert.NoError(t, err)
-	assert.Equal(t, expectedBalance, response.Balance)
+	assert.True(t, expectedBalance.Available.Equal(response.Available))
+	assert.True(t, expectedBalance.Held.Equal(response.Held))
+	assert.True(t, expectedBalance.Total.Equal(response.Total))
 }
 
 func TestGetBalance_NotFound(t *testing.T) {
@@ -71,7 +77,7 @@ func TestGetBalance_NotFound(t *testing.T) {
 	mockService := mocks.NewMockWallet(ctrl)
 
 	// Ожидаем, что метод GetBalance будет вызван с UUID и вернет ошибку
-	mockService.EXPECT().GetBalance(gomock.Any(), walletID).Return(decimal.Decimal{}, errors.New("wallet not found")).Times(1)
+	mockService.EXPECT().GetBalance(gomock.Any(), walletID).Return(domain.WalletBalance{}, app_errors.ErrWalletNotFound).Times(1)
 
 	// Создаем сервис с мок-сервисом
 	service := &services.Service{
@@ -95,7 +101,8 @@ func TestGetBalance_NotFound(t *testing.T) {
 	var response map[string]interface{}
 	err := json.Unmarshal(resp.Body.Bytes(), &response)
 	assert.NoError(t, err)
-	assert.Equal(t, "Wallet not found", response["error"])
+	assert.Equal(t, "wallet_not_found", response["code"])
+	assert.Equal(t, "wallet not found", response["title"])
 }
 
 func TestGetBalance_InvalidUUID(t *testing.T) {
@@ -112,10 +119,10 @@ func TestGetBalance_InvalidUUID(t *testing.T) {
 	router.ServeHTTP(resp, req)
 
 	// Проверяем, что возвращена ошибка с корректным статусом
-	assert.Equal(t, http.StatusForbidden, resp.Code)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
 
 	var response map[string]interface{}
 	err := json.Unmarshal(resp.Body.Bytes(), &response)
 	assert.NoError(t, err)
-	assert.Equal(t, "Invalid UUID format", response["error"])
+	assert.Equal(t, "invalid_uuid", response["code"])
 }
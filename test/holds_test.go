@@ -0,0 +1,107 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	"wallet-app/internal/app/app_errors"
+	delivery "wallet-app/internal/app/delivery/http"
+	"wallet-app/internal/app/domain"
+	"wallet-app/internal/app/services"
+	"wallet-app/internal/app/services/mocks"
+)
+
+func TestCreateHold(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	walletID := uuid.New()
+	holdID := uuid.New()
+
+	mockHolds := mocks.NewMockHolds(ctrl)
+	mockHolds.EXPECT().CreateHold(gomock.Any(), walletID, domain.HoldRequest{Amount: "40", TTLSeconds: 60}).
+		Return(domain.Hold{
+			ID:        holdID,
+			WalletID:  walletID,
+			Amount:    decimal.NewFromInt(40),
+			Status:    domain.HoldActive,
+			ExpiresAt: time.Now().Add(time.Minute),
+		}, nil).Times(1)
+
+	h := delivery.NewHandler(&services.Service{Holds: mockHolds})
+	router := gin.Default()
+	router.POST("/api/v1/wallets/:walletId/holds", h.CreateHold)
+
+	requestBody, err := json.Marshal(map[string]any{"amount": "40", "ttlSeconds": 60})
+	assert.NoError(t, err)
+
+	req, _ := http.NewRequest("POST", "/api/v1/wallets/"+walletID.String()+"/holds", bytes.NewBuffer(requestBody))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusCreated, resp.Code)
+
+	var response domain.Hold
+	err = json.Unmarshal(resp.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, holdID, response.ID)
+	assert.Equal(t, domain.HoldActive, response.Status)
+}
+
+func TestCaptureHold_WithoutBody(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	walletID := uuid.New()
+	holdID := uuid.New()
+
+	// Без тела запроса списывается весь холд
+	mockHolds := mocks.NewMockHolds(ctrl)
+	mockHolds.EXPECT().CaptureHold(gomock.Any(), walletID, holdID, domain.CaptureRequest{}).
+		Return(domain.HoldCaptureResult{
+			Hold: domain.Hold{ID: holdID, WalletID: walletID, Status: domain.HoldCaptured},
+		}, nil).Times(1)
+
+	h := delivery.NewHandler(&services.Service{Holds: mockHolds})
+	router := gin.Default()
+	router.POST("/api/v1/wallets/:walletId/holds/:holdId/capture", h.CaptureHold)
+
+	req, _ := http.NewRequest("POST", "/api/v1/wallets/"+walletID.String()+"/holds/"+holdID.String()+"/capture", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+}
+
+func TestReleaseHold_NotActive(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	walletID := uuid.New()
+	holdID := uuid.New()
+
+	mockHolds := mocks.NewMockHolds(ctrl)
+	mockHolds.EXPECT().ReleaseHold(gomock.Any(), walletID, holdID).
+		Return(domain.Hold{}, app_errors.ErrHoldNotActive).Times(1)
+
+	h := delivery.NewHandler(&services.Service{Holds: mockHolds})
+	router := gin.Default()
+	router.POST("/api/v1/wallets/:walletId/holds/:holdId/release", h.ReleaseHold)
+
+	req, _ := http.NewRequest("POST", "/api/v1/wallets/"+walletID.String()+"/holds/"+holdID.String()+"/release", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusConflict, resp.Code)
+}
@@ -0,0 +1,486 @@
+//go:build integration
+
+package integration
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	delivery "wallet-app/internal/app/delivery/http"
+	"wallet-app/internal/app/domain"
+)
+
+func TestWalletLifecycle(t *testing.T) {
+	owner := "owner-" + uuid.NewString()
+	wallet := api.createWallet(t, domain.CreateWalletRequest{
+		Currency: "USD", OwnerID: owner, DisplayName: "Main", Labels: []string{"primary"},
+	})
+	assert.Equal(t, "USD", wallet.Currency)
+	assert.Equal(t, domain.WalletActive, wallet.Status)
+	assert.True(t, wallet.Balance.IsZero())
+
+	deposit := api.deposit(t, wallet.ID, "100.50")
+	require.NotNil(t, deposit.BalanceAfter)
+	assert.True(t, deposit.BalanceAfter.Equal(decimal.RequireFromString("100.50")))
+
+	withdraw := api.mustOperate(t, domain.WalletOperation{
+		WalletID: wallet.ID, OperationType: domain.Withdraw, Amount: "0.50", Currency: "USD",
+	})
+	assert.True(t, withdraw.BalanceAfter.Equal(decimal.NewFromInt(100)))
+	api.requireTotal(t, wallet.ID, "100")
+
+	api.createWallet(t, domain.CreateWalletRequest{Currency: "EUR", OwnerID: owner})
+	var owned domain.WalletsByOwner
+	api.expect(t, http.StatusOK, http.MethodGet, "/api/v1/wallets?ownerId="+url.QueryEscape(owner), nil).decode(t, &owned)
+	assert.Len(t, owned.Wallets, 2)
+
+	// Запрос без X-Request-ID получает сгенерированный идентификатор
+	resp := api.expect(t, http.StatusOK, http.MethodGet, walletPath(wallet.ID), nil)
+	assert.NotEmpty(t, resp.Header.Get(delivery.RequestIDHeader))
+}
+
+func TestValidationAndNotFound(t *testing.T) {
+	wallet := api.createWallet(t, domain.CreateWalletRequest{})
+
+	api.expectProblem(t, http.StatusBadRequest, "invalid_uuid", http.MethodGet, "/api/v1/wallets/not-a-uuid", nil)
+	api.expectProblem(t, http.StatusNotFound, "wallet_not_found", http.MethodGet, walletPath(uuid.New()), nil)
+	api.expectProblem(t, http.StatusBadRequest, "owner_id_required", http.MethodGet, "/api/v1/wallets", nil)
+	api.expectProblem(t, http.StatusBadRequest, "unsupported_currency", http.MethodPost, "/api/v1/create-wallet",
+		domain.CreateWalletRequest{Currency: "XXX"})
+	api.expectProblem(t, http.StatusNotFound, "wallet_not_found", http.MethodPost, "/api/v1/wallet",
+		domain.WalletOperation{WalletID: uuid.New(), OperationType: domain.Deposit, Amount: "10"})
+	api.expectProblem(t, http.StatusBadRequest, "amount_not_positive", http.MethodPost, "/api/v1/wallet",
+		domain.WalletOperation{WalletID: wallet.ID, OperationType: domain.Deposit, Amount: "-10"})
+	api.expectProblem(t, http.StatusBadRequest, "invalid_amount_scale", http.MethodPost, "/api/v1/wallet",
+		domain.WalletOperation{WalletID: wallet.ID, OperationType: domain.Deposit, Amount: "1.001"})
+	api.expectProblem(t, http.StatusBadRequest, "currency_mismatch", http.MethodPost, "/api/v1/wallet",
+		domain.WalletOperation{WalletID: wallet.ID, OperationType: domain.Deposit, Amount: "1", Currency: "EUR"})
+
+	resp := api.expect(t, http.StatusBadRequest, http.MethodPost, "/api/v1/wallet",
+		domain.WalletOperation{WalletID: wallet.ID, OperationType: "BONUS", Amount: "1"})
+	problem := resp.problem(t)
+	assert.Equal(t, "validation_failed", problem.Code)
+	assert.NotEmpty(t, problem.Errors)
+	assert.Equal(t, "application/problem+json", resp.Header.Get("Content-Type"))
+}
+
+func TestInsufficientFunds(t *testing.T) {
+	wallet := api.createWallet(t, domain.CreateWalletRequest{})
+	api.deposit(t, wallet.ID, "10")
+
+	api.expectProblem(t, http.StatusUnprocessableEntity, "insufficient_funds", http.MethodPost, "/api/v1/wallet",
+		domain.WalletOperation{WalletID: wallet.ID, OperationType: domain.Withdraw, Amount: "10.01"})
+	api.requireTotal(t, wallet.ID, "10")
+}
+
+func TestIdempotency(t *testing.T) {
+	wallet := api.createWallet(t, domain.CreateWalletRequest{})
+	op := domain.WalletOperation{WalletID: wallet.ID, OperationType: domain.Deposit, Amount: "25"}
+	key := uuid.NewString()
+
+	first := api.mustOperate(t, op, delivery.IdempotencyKeyHeader, key)
+	second := api.mustOperate(t, op, delivery.IdempotencyKeyHeader, key)
+	assert.Equal(t, first.ID, second.ID)
+	api.requireTotal(t, wallet.ID, "25")
+
+	op.Amount = "26"
+	api.expectProblem(t, http.StatusUnprocessableEntity, "idempotency_key_reused", http.MethodPost, "/api/v1/wallet",
+		op, delivery.IdempotencyKeyHeader, key)
+	api.requireTotal(t, wallet.ID, "25")
+
+	// Тот же ключ другого клиента — независимый запрос: ни отказа, ни чужого ответа
+	other := api.as(t, "idempotency-other", string(domain.RoleAdmin)).mustOperate(t, op, delivery.IdempotencyKeyHeader, key)
+	assert.NotEqual(t, first.ID, other.ID)
+	api.requireTotal(t, wallet.ID, "51")
+}
+
+func TestIfMatch(t *testing.T) {
+	wallet := api.createWallet(t, domain.CreateWalletRequest{})
+	balance, resp := api.balance(t, wallet.ID)
+	etag := resp.Header.Get(delivery.ETagHeader)
+	require.Equal(t, strconv.Quote(strconv.FormatInt(balance.Version, 10)), etag)
+
+	op := domain.WalletOperation{WalletID: wallet.ID, OperationType: domain.Deposit, Amount: "10"}
+	api.mustOperate(t, op, delivery.IfMatchHeader, etag)
+
+	// ETag устарел после первой операции
+	api.expectProblem(t, http.StatusPreconditionFailed, "precondition_failed", http.MethodPost, "/api/v1/wallet",
+		op, delivery.IfMatchHeader, etag)
+	api.expectProblem(t, http.StatusBadRequest, "invalid_if_match", http.MethodPost, "/api/v1/wallet",
+		op, delivery.IfMatchHeader, `W/"1"`)
+
+	_, resp = api.balance(t, wallet.ID)
+	assert.NotEqual(t, etag, resp.Header.Get(delivery.ETagHeader))
+	api.mustOperate(t, op, delivery.IfMatchHeader, "*")
+	api.requireTotal(t, wallet.ID, "20")
+}
+
+func TestTransactionsPagination(t *testing.T) {
+	wallet := api.createWallet(t, domain.CreateWalletRequest{})
+	for i := 1; i <= 5; i++ {
+		api.deposit(t, wallet.ID, strconv.Itoa(i*10))
+	}
+	api.mustOperate(t, domain.WalletOperation{WalletID: wallet.ID, OperationType: domain.Withdraw, Amount: "5"})
+
+	var seen []domain.Transaction
+	cursor := ""
+	for {
+		query := url.Values{"limit": {"2"}}
+		if cursor != "" {
+			query.Set("cursor", cursor)
+		}
+		var page domain.TransactionPage
+		api.expect(t, http.StatusOK, http.MethodGet, walletPath(wallet.ID, "transactions")+"?"+query.Encode(), nil).decode(t, &page)
+		require.LessOrEqual(t, len(page.Transactions), 2)
+		seen = append(seen, page.Transactions...)
+		if page.NextCursor == "" {
+			break
+		}
+		cursor = page.NextCursor
+	}
+	require.Len(t, seen, 6)
+	assert.Equal(t, domain.Withdraw, seen[0].OperationType)
+
+	query := url.Values{"operationType": {"DEPOSIT"}, "minAmount": {"20"}, "maxAmount": {"40"}}
+	var filtered domain.TransactionPage
+	api.expect(t, http.StatusOK, http.MethodGet, walletPath(wallet.ID, "transactions")+"?"+query.Encode(), nil).decode(t, &filtered)
+	assert.Len(t, filtered.Transactions, 3)
+
+	api.expectProblem(t, http.StatusBadRequest, "invalid_amount_range", http.MethodGet,
+		walletPath(wallet.ID, "transactions")+"?minAmount=10&maxAmount=1", nil)
+	api.expectProblem(t, http.StatusBadRequest, "invalid_cursor", http.MethodGet,
+		walletPath(wallet.ID, "transactions")+"?cursor=garbage", nil)
+}
+
+func TestReversalAndRefund(t *testing.T) {
+	wallet := api.createWallet(t, domain.CreateWalletRequest{})
+	deposit := api.deposit(t, wallet.ID, "100")
+	withdraw := api.mustOperate(t, domain.WalletOperation{WalletID: wallet.ID, OperationType: domain.Withdraw, Amount: "40"})
+
+	// Частичный возврат списания и затем возврат сверх остатка
+	api.mustOperate(t, domain.WalletOperation{
+		WalletID: wallet.ID, OperationType: domain.Refund, Amount: "15", TransactionID: &withdraw.ID,
+	})
+	api.expectProblem(t, http.StatusConflict, "refund_exceeds_original", http.MethodPost, "/api/v1/wallet",
+		domain.WalletOperation{WalletID: wallet.ID, OperationType: domain.Refund, Amount: "30", TransactionID: &withdraw.ID})
+	api.requireTotal(t, wallet.ID, "75")
+
+	// Сторно зачисления не может увести баланс в минус
+	api.expectProblem(t, http.StatusUnprocessableEntity, "insufficient_funds", http.MethodPost, "/api/v1/wallet",
+		domain.WalletOperation{WalletID: wallet.ID, OperationType: domain.Reversal, TransactionID: &deposit.ID})
+
+	bonus := api.deposit(t, wallet.ID, "30")
+	api.mustOperate(t, domain.WalletOperation{WalletID: wallet.ID, OperationType: domain.Reversal, TransactionID: &bonus.ID})
+	api.requireTotal(t, wallet.ID, "75")
+	api.expectProblem(t, http.StatusConflict, "transaction_not_reversible", http.MethodPost, "/api/v1/wallet",
+		domain.WalletOperation{WalletID: wallet.ID, OperationType: domain.Reversal, TransactionID: &bonus.ID})
+
+	missing := uuid.New()
+	api.expectProblem(t, http.StatusNotFound, "transaction_not_found", http.MethodPost, "/api/v1/wallet",
+		domain.WalletOperation{WalletID: wallet.ID, OperationType: domain.Reversal, TransactionID: &missing})
+}
+
+func TestHolds(t *testing.T) {
+	wallet := api.createWallet(t, domain.CreateWalletRequest{})
+	api.deposit(t, wallet.ID, "100")
+
+	var hold domain.Hold
+	api.expect(t, http.StatusCreated, http.MethodPost, walletPath(wallet.ID, "holds"),
+		domain.HoldRequest{Amount: "60", TTLSeconds: 60}).decode(t, &hold)
+	assert.Equal(t, domain.HoldActive, hold.Status)
+
+	balance, _ := api.balance(t, wallet.ID)
+	assert.True(t, balance.Held.Equal(decimal.NewFromInt(60)))
+	assert.True(t, balance.Available.Equal(decimal.NewFromInt(40)))
+
+	// Холд уменьшает доступный остаток для списаний и новых холдов
+	api.expectProblem(t, http.StatusUnprocessableEntity, "insufficient_funds", http.MethodPost, "/api/v1/wallet",
+		domain.WalletOperation{WalletID: wallet.ID, OperationType: domain.Withdraw, Amount: "50"})
+	api.expectProblem(t, http.StatusUnprocessableEntity, "insufficient_funds", http.MethodPost, walletPath(wallet.ID, "holds"),
+		domain.HoldRequest{Amount: "50"})
+
+	var captured domain.HoldCaptureResult
+	api.expect(t, http.StatusOK, http.MethodPost, walletPath(wallet.ID, "holds", hold.ID, "capture"),
+		domain.CaptureRequest{Amount: "35"}).decode(t, &captured)
+	assert.Equal(t, domain.HoldCaptured, captured.Hold.Status)
+	assert.Equal(t, domain.Capture, captured.Transaction.OperationType)
+	api.requireTotal(t, wallet.ID, "65")
+
+	api.expectProblem(t, http.StatusConflict, "hold_not_active", http.MethodPost,
+		walletPath(wallet.ID, "holds", hold.ID, "release"), nil)
+
+	var second domain.Hold
+	api.expect(t, http.StatusCreated, http.MethodPost, walletPath(wallet.ID, "holds"),
+		domain.HoldRequest{Amount: "65"}).decode(t, &second)
+	var released domain.Hold
+	api.expect(t, http.StatusOK, http.MethodPost, walletPath(wallet.ID, "holds", second.ID, "release"), nil).decode(t, &released)
+	assert.Equal(t, domain.HoldReleased, released.Status)
+
+	balance, _ = api.balance(t, wallet.ID)
+	assert.True(t, balance.Held.IsZero())
+	assert.True(t, balance.Available.Equal(decimal.NewFromInt(65)))
+
+	api.expectProblem(t, http.StatusNotFound, "hold_not_found", http.MethodPost,
+		walletPath(wallet.ID, "holds", uuid.New(), "capture"), nil)
+}
+
+func TestTransfers(t *testing.T) {
+	from := api.createWallet(t, domain.CreateWalletRequest{Currency: "USD"})
+	to := api.createWallet(t, domain.CreateWalletRequest{Currency: "USD"})
+	api.deposit(t, from.ID, "100")
+
+	var result domain.TransferResult
+	api.expect(t, http.StatusOK, http.MethodPost, "/api/v1/transfer",
+		domain.TransferOperation{FromWalletID: from.ID, ToWalletID: to.ID, Amount: "30"}).decode(t, &result)
+	assert.Equal(t, domain.Transfer, result.Debit.OperationType)
+	api.requireTotal(t, from.ID, "70")
+	api.requireTotal(t, to.ID, "30")
+
+	api.expectProblem(t, http.StatusBadRequest, "same_wallet_transfer", http.MethodPost, "/api/v1/transfer",
+		domain.TransferOperation{FromWalletID: from.ID, ToWalletID: from.ID, Amount: "1"})
+	api.expectProblem(t, http.StatusUnprocessableEntity, "insufficient_funds", http.MethodPost, "/api/v1/transfer",
+		domain.TransferOperation{FromWalletID: from.ID, ToWalletID: to.ID, Amount: "70.01"})
+	api.expectProblem(t, http.StatusNotFound, "wallet_not_found", http.MethodPost, "/api/v1/transfer",
+		domain.TransferOperation{FromWalletID: from.ID, ToWalletID: uuid.New(), Amount: "1"})
+	api.requireTotal(t, from.ID, "70")
+}
+
+func TestCrossCurrencyTransfer(t *testing.T) {
+	from := api.createWallet(t, domain.CreateWalletRequest{Currency: "USD"})
+	to := api.createWallet(t, domain.CreateWalletRequest{Currency: "EUR"})
+	api.deposit(t, from.ID, "200")
+
+	// Без котировки перевод между валютами невозможен
+	resp := api.do(t, http.MethodPost, "/api/v1/transfer",
+		domain.TransferOperation{FromWalletID: from.ID, ToWalletID: to.ID, Amount: "100"})
+	assert.Equal(t, http.StatusBadRequest, resp.Status, string(resp.Body))
+
+	var quote domain.FXQuote
+	api.expect(t, http.StatusCreated, http.MethodPost, "/api/v1/fx/quotes",
+		domain.FXQuoteRequest{FromCurrency: "USD", ToCurrency: "EUR"}).decode(t, &quote)
+	assert.True(t, quote.Rate.Equal(decimal.RequireFromString("0.92")))
+
+	var result domain.TransferResult
+	api.expect(t, http.StatusOK, http.MethodPost, "/api/v1/transfer",
+		domain.TransferOperation{FromWalletID: from.ID, ToWalletID: to.ID, Amount: "100", QuoteID: &quote.ID}).decode(t, &result)
+	require.NotNil(t, result.Conversion)
+	assert.True(t, result.Conversion.ConvertedAmount.Equal(decimal.NewFromInt(92)))
+	api.requireTotal(t, from.ID, "100")
+	api.requireTotal(t, to.ID, "92")
+
+	// Котировка одноразовая
+	api.expectProblem(t, http.StatusConflict, "quote_not_active", http.MethodPost, "/api/v1/transfer",
+		domain.TransferOperation{FromWalletID: from.ID, ToWalletID: to.ID, Amount: "100", QuoteID: &quote.ID})
+	api.expectProblem(t, http.StatusBadRequest, "same_currency_quote", http.MethodPost, "/api/v1/fx/quotes",
+		domain.FXQuoteRequest{FromCurrency: "USD", ToCurrency: "USD"})
+}
+
+func TestWalletStatus(t *testing.T) {
+	wallet := api.createWallet(t, domain.CreateWalletRequest{})
+	api.deposit(t, wallet.ID, "10")
+	admin := "/api/v1/admin/wallets/" + wallet.ID.String()
+
+	var state domain.WalletState
+	api.expect(t, http.StatusOK, http.MethodPost, admin+"/freeze", domain.WalletStatusRequest{Reason: "audit"}).decode(t, &state)
+	assert.Equal(t, domain.WalletFrozen, state.Status)
+	assert.Equal(t, "audit", state.Reason)
+
+	// Замороженный кошелек принимает зачисления, но не списания
+	api.deposit(t, wallet.ID, "5")
+	api.expectProblem(t, http.StatusConflict, "wallet_frozen", http.MethodPost, "/api/v1/wallet",
+		domain.WalletOperation{WalletID: wallet.ID, OperationType: domain.Withdraw, Amount: "1"})
+
+	api.expect(t, http.StatusOK, http.MethodPost, admin+"/block", nil)
+	api.expectProblem(t, http.StatusConflict, "wallet_blocked", http.MethodPost, "/api/v1/wallet",
+		domain.WalletOperation{WalletID: wallet.ID, OperationType: domain.Deposit, Amount: "1"})
+
+	api.expect(t, http.StatusOK, http.MethodPost, admin+"/activate", nil)
+	api.expectProblem(t, http.StatusConflict, "wallet_balance_not_zero", http.MethodPost, admin+"/close", nil)
+
+	api.mustOperate(t, domain.WalletOperation{WalletID: wallet.ID, OperationType: domain.Withdraw, Amount: "15"})
+	api.expect(t, http.StatusOK, http.MethodPost, admin+"/close", nil).decode(t, &state)
+	assert.Equal(t, domain.WalletClosed, state.Status)
+
+	api.expectProblem(t, http.StatusConflict, "wallet_closed", http.MethodPost, "/api/v1/wallet",
+		domain.WalletOperation{WalletID: wallet.ID, OperationType: domain.Deposit, Amount: "1"})
+	api.expectProblem(t, http.StatusConflict, "wallet_closed", http.MethodPost, admin+"/activate", nil)
+}
+
+func TestLedgerVerifyAndMetrics(t *testing.T) {
+	from := api.createWallet(t, domain.CreateWalletRequest{})
+	to := api.createWallet(t, domain.CreateWalletRequest{})
+	api.deposit(t, from.ID, "50")
+	api.expect(t, http.StatusOK, http.MethodPost, "/api/v1/transfer",
+		domain.TransferOperation{FromWalletID: from.ID, ToWalletID: to.ID, Amount: "20"})
+
+	api.requireLedgerBalanced(t)
+
+	var vars map[string]any
+	api.expect(t, http.StatusOK, http.MethodGet, "/debug/vars", nil).decode(t, &vars)
+	assert.Contains(t, vars, "operation_batcher")
+
+	// Метрики раскрывают командную строку процесса: без ключа и не администраторам они недоступны
+	api.expectProblem(t, http.StatusUnauthorized, "unauthenticated", http.MethodGet, "/debug/vars", nil, delivery.APIKeyHeader, "")
+	api.as(t, "metrics-reader", string(domain.RoleViewer)).
+		expectProblem(t, http.StatusForbidden, "forbidden", http.MethodGet, "/debug/vars", nil)
+}
+
+func TestAuthentication(t *testing.T) {
+	wallet := api.createWallet(t, domain.CreateWalletRequest{})
+
+	// Пустое значение заголовка равносильно его отсутствию
+	resp := api.expect(t, http.StatusUnauthorized, http.MethodGet, walletPath(wallet.ID), nil, delivery.APIKeyHeader, "")
+	assert.Equal(t, "unauthenticated", resp.problem(t).Code)
+	assert.NotEmpty(t, resp.Header.Get("WWW-Authenticate"))
+
+	api.expectProblem(t, http.StatusUnauthorized, "invalid_credentials", http.MethodGet, walletPath(wallet.ID), nil,
+		delivery.APIKeyHeader, "wk_unknown")
+	api.expectProblem(t, http.StatusUnauthorized, "invalid_credentials", http.MethodPost, "/api/v1/admin/wallets/"+wallet.ID.String()+"/freeze", nil,
+		delivery.APIKeyHeader, "", delivery.AuthorizationHeader, "Bearer token")
+}
+
+func TestAuthorization(t *testing.T) {
+	owner := api.as(t, "alice")
+	stranger := api.as(t, "mallory")
+	viewer := api.as(t, "victor")
+
+	// Кошелек без указанного владельца принадлежит создавшему его клиенту
+	wallet := owner.createWallet(t, domain.CreateWalletRequest{})
+	require.Equal(t, "alice", wallet.OwnerID)
+	owner.deposit(t, wallet.ID, "100")
+
+	withdraw := domain.WalletOperation{WalletID: wallet.ID, OperationType: domain.Withdraw, Amount: "10"}
+	stranger.expectProblem(t, http.StatusForbidden, "forbidden", http.MethodGet, walletPath(wallet.ID), nil)
+	stranger.expectProblem(t, http.StatusForbidden, "forbidden", http.MethodPost, "/api/v1/wallet", withdraw)
+	stranger.expectProblem(t, http.StatusForbidden, "forbidden", http.MethodGet, "/api/v1/wallets?ownerId=alice", nil)
+	stranger.expectProblem(t, http.StatusForbidden, "forbidden", http.MethodPost, "/api/v1/create-wallet",
+		domain.CreateWalletRequest{OwnerID: "alice"})
+	owner.expectProblem(t, http.StatusForbidden, "forbidden", http.MethodGet, "/api/v1/ledger/verify", nil)
+
+	// Наблюдатель читает баланс, но не двигает средства и не управляет доступом
+	var grant domain.WalletGrant
+	owner.expect(t, http.StatusOK, http.MethodPut, walletPath(wallet.ID, "grants", "victor"),
+		domain.WalletGrantRequest{Role: domain.RoleViewer}).decode(t, &grant)
+	assert.Equal(t, "alice", grant.GrantedBy)
+	viewer.requireTotal(t, wallet.ID, "100")
+	viewer.expectProblem(t, http.StatusForbidden, "forbidden", http.MethodPost, "/api/v1/wallet", withdraw)
+	viewer.expectProblem(t, http.StatusForbidden, "forbidden", http.MethodGet, walletPath(wallet.ID, "grants"), nil)
+
+	// Оператор может списывать средства
+	owner.expect(t, http.StatusOK, http.MethodPut, walletPath(wallet.ID, "grants", "victor"),
+		domain.WalletGrantRequest{Role: domain.RoleOperator})
+	viewer.mustOperate(t, withdraw)
+
+	var grants domain.WalletGrants
+	owner.expect(t, http.StatusOK, http.MethodGet, walletPath(wallet.ID, "grants"), nil).decode(t, &grants)
+	require.Len(t, grants.Grants, 1)
+	assert.Equal(t, domain.RoleOperator, grants.Grants[0].Role)
+
+	owner.expect(t, http.StatusNoContent, http.MethodDelete, walletPath(wallet.ID, "grants", "victor"), nil)
+	owner.expectProblem(t, http.StatusNotFound, "grant_not_found", http.MethodDelete, walletPath(wallet.ID, "grants", "victor"), nil)
+	viewer.expectProblem(t, http.StatusForbidden, "forbidden", http.MethodGet, walletPath(wallet.ID), nil)
+
+	// Роль из ключа действует на все кошельки
+	api.as(t, "auditor", string(domain.RoleViewer)).requireTotal(t, wallet.ID, "90")
+}
+
+func TestWalletLimits(t *testing.T) {
+	for name, server := range servers() {
+		t.Run(name, func(t *testing.T) {
+			wallet := server.createWallet(t, domain.CreateWalletRequest{})
+			other := server.createWallet(t, domain.CreateWalletRequest{})
+			path := walletPath(wallet.ID, "limits")
+
+			// Уровень по умолчанию не задан: без назначенных ограничений кошелек ничем не ограничен
+			var limits domain.WalletLimits
+			server.expect(t, http.StatusOK, http.MethodGet, path, nil).decode(t, &limits)
+			assert.True(t, limits.Effective.IsZero())
+
+			server.expectProblem(t, http.StatusBadRequest, "unknown_limit_tier", http.MethodPut, path,
+				domain.WalletLimitsRequest{Tier: "gold"})
+			server.as(t, "alice").expectProblem(t, http.StatusForbidden, "forbidden", http.MethodPut, path,
+				domain.WalletLimitsRequest{})
+
+			server.expect(t, http.StatusOK, http.MethodPut, path,
+				domain.WalletLimitsRequest{Tier: "restricted", MaxBalance: "300"}).decode(t, &limits)
+			assert.Equal(t, "restricted", limits.Tier)
+			assert.Equal(t, "300", limits.Effective.MaxBalance.String())
+			assert.Equal(t, "100", limits.Effective.MaxTransaction.String())
+
+			exceeded := func(resp response, limit domain.LimitName) {
+				t.Helper()
+				require.Equal(t, http.StatusUnprocessableEntity, resp.Status, resp.Body)
+				problem := resp.problem(t)
+				assert.Equal(t, "limit_exceeded", problem.Code)
+				assert.Equal(t, limit, problem.Limit)
+			}
+
+			exceeded(server.operate(t, domain.WalletOperation{WalletID: wallet.ID, OperationType: domain.Deposit, Amount: "150"}),
+				domain.LimitMaxTransaction)
+			for i := 0; i < 3; i++ {
+				server.deposit(t, wallet.ID, "100")
+			}
+			exceeded(server.operate(t, domain.WalletOperation{WalletID: wallet.ID, OperationType: domain.Deposit, Amount: "0.01"}),
+				domain.LimitMaxBalance)
+
+			// Снятия и переводы за день складываются по истории операций
+			withdrawal := server.mustOperate(t, domain.WalletOperation{WalletID: wallet.ID, OperationType: domain.Withdraw, Amount: "100"})
+			server.expect(t, http.StatusOK, http.MethodPost, "/api/v1/transfer",
+				domain.TransferOperation{FromWalletID: wallet.ID, ToWalletID: other.ID, Amount: "50"})
+			exceeded(server.operate(t, domain.WalletOperation{WalletID: wallet.ID, OperationType: domain.Withdraw, Amount: "0.01"}),
+				domain.LimitDailyWithdrawal)
+			exceeded(server.do(t, http.MethodPost, "/api/v1/transfer",
+				domain.TransferOperation{FromWalletID: wallet.ID, ToWalletID: other.ID, Amount: "0.01"}),
+				domain.LimitDailyWithdrawal)
+
+			server.requireTotal(t, wallet.ID, "150")
+
+			// Возврат снятия — зачисление: баланс не поднимается выше потолка
+			server.deposit(t, wallet.ID, "100")
+			server.deposit(t, wallet.ID, "50")
+			exceeded(server.operate(t, domain.WalletOperation{
+				WalletID: wallet.ID, OperationType: domain.Refund, Amount: "100", TransactionID: &withdrawal.ID,
+			}), domain.LimitMaxBalance)
+
+			server.requireTotal(t, wallet.ID, "300")
+			server.requireLedgerBalanced(t)
+		})
+	}
+}
+
+func TestWalletLimits_HoldCapture(t *testing.T) {
+	for name, server := range servers() {
+		t.Run(name, func(t *testing.T) {
+			wallet := server.createWallet(t, domain.CreateWalletRequest{})
+			server.deposit(t, wallet.ID, "300")
+
+			// Холд создан до ограничения; его списание — списание за день и проверяется при capture
+			var hold domain.Hold
+			server.expect(t, http.StatusCreated, http.MethodPost, walletPath(wallet.ID, "holds"),
+				domain.HoldRequest{Amount: "200"}).decode(t, &hold)
+			server.expect(t, http.StatusOK, http.MethodPut, walletPath(wallet.ID, "limits"),
+				domain.WalletLimitsRequest{DailyWithdrawal: "150"})
+
+			resp := server.do(t, http.MethodPost, walletPath(wallet.ID, "holds", hold.ID, "capture"), nil)
+			require.Equal(t, http.StatusUnprocessableEntity, resp.Status, resp.Body)
+			assert.Equal(t, "limit_exceeded", resp.problem(t).Code)
+			assert.Equal(t, domain.LimitDailyWithdrawal, resp.problem(t).Limit)
+
+			// Активный холд учитывается в списаниях за день при создании следующего
+			server.expectProblem(t, http.StatusUnprocessableEntity, "limit_exceeded", http.MethodPost, walletPath(wallet.ID, "holds"),
+				domain.HoldRequest{Amount: "1"})
+
+			server.expect(t, http.StatusOK, http.MethodPost, walletPath(wallet.ID, "holds", hold.ID, "capture"),
+				domain.CaptureRequest{Amount: "150"})
+			server.requireTotal(t, wallet.ID, "150")
+			server.requireLedgerBalanced(t)
+		})
+	}
+}
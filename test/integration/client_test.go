@@ -0,0 +1,141 @@
+//go:build integration
+
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+
+	delivery "wallet-app/internal/app/delivery/http"
+	"wallet-app/internal/app/domain"
+)
+
+// response — ответ API: статус, заголовки и тело
+type response struct {
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+// decode разбирает тело ответа в v
+func (r response) decode(t *testing.T, v any) {
+	t.Helper()
+	require.NoError(t, json.Unmarshal(r.Body, v), string(r.Body))
+}
+
+// problem разбирает тело ответа с ошибкой
+func (r response) problem(t *testing.T) delivery.ProblemDetails {
+	t.Helper()
+	var problem delivery.ProblemDetails
+	r.decode(t, &problem)
+	return problem
+}
+
+// do выполняет запрос к серверу с ключом API сервера; body сериализуется в JSON, headers — пары имя/значение
+func (s *apiServer) do(t *testing.T, method, path string, body any, headers ...string) response {
+	t.Helper()
+
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		require.NoError(t, err)
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequest(method, s.URL+path, reader)
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(delivery.APIKeyHeader, s.apiKey)
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+
+	resp, err := s.Client().Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return response{Status: resp.StatusCode, Header: resp.Header, Body: data}
+}
+
+// expect выполняет запрос и проверяет статус ответа
+func (s *apiServer) expect(t *testing.T, status int, method, path string, body any, headers ...string) response {
+	t.Helper()
+	resp := s.do(t, method, path, body, headers...)
+	require.Equal(t, status, resp.Status, "%s %s: %s", method, path, resp.Body)
+	return resp
+}
+
+// expectProblem выполняет запрос и проверяет статус и код ошибки
+func (s *apiServer) expectProblem(t *testing.T, status int, code, method, path string, body any, headers ...string) {
+	t.Helper()
+	resp := s.expect(t, status, method, path, body, headers...)
+	require.Equal(t, code, resp.problem(t).Code)
+}
+
+func (s *apiServer) createWallet(t *testing.T, req domain.CreateWalletRequest) domain.Wallet {
+	t.Helper()
+	var wallet domain.Wallet
+	s.expect(t, http.StatusCreated, http.MethodPost, "/api/v1/create-wallet", req).decode(t, &wallet)
+	return wallet
+}
+
+// operate выполняет операцию над балансом и возвращает ответ как есть
+func (s *apiServer) operate(t *testing.T, op domain.WalletOperation, headers ...string) response {
+	t.Helper()
+	return s.do(t, http.MethodPost, "/api/v1/wallet", op, headers...)
+}
+
+// mustOperate выполняет операцию и ожидает успеха
+func (s *apiServer) mustOperate(t *testing.T, op domain.WalletOperation, headers ...string) domain.Transaction {
+	t.Helper()
+	var result delivery.OperationResponse
+	s.expect(t, http.StatusOK, http.MethodPost, "/api/v1/wallet", op, headers...).decode(t, &result)
+	return result.Transaction
+}
+
+func (s *apiServer) deposit(t *testing.T, walletID uuid.UUID, amount string) domain.Transaction {
+	t.Helper()
+	return s.mustOperate(t, domain.WalletOperation{WalletID: walletID, OperationType: domain.Deposit, Amount: amount})
+}
+
+func (s *apiServer) balance(t *testing.T, walletID uuid.UUID) (domain.WalletBalance, response) {
+	t.Helper()
+	var balance domain.WalletBalance
+	resp := s.expect(t, http.StatusOK, http.MethodGet, "/api/v1/wallets/"+walletID.String(), nil)
+	resp.decode(t, &balance)
+	return balance, resp
+}
+
+// requireTotal проверяет баланс кошелька по журналу
+func (s *apiServer) requireTotal(t *testing.T, walletID uuid.UUID, expected string) {
+	t.Helper()
+	balance, _ := s.balance(t, walletID)
+	require.True(t, balance.Total.Equal(decimal.RequireFromString(expected)),
+		"wallet %s: total %s, expected %s", walletID, balance.Total, expected)
+}
+
+// requireLedgerBalanced проверяет, что журнал сходится и совпадает с балансами кошельков
+func (s *apiServer) requireLedgerBalanced(t *testing.T) {
+	t.Helper()
+	var report domain.LedgerReport
+	s.expect(t, http.StatusOK, http.MethodGet, "/api/v1/ledger/verify", nil).decode(t, &report)
+	require.True(t, report.Balanced, "ledger is not balanced: %+v", report)
+}
+
+func walletPath(walletID uuid.UUID, parts ...any) string {
+	path := "/api/v1/wallets/" + walletID.String()
+	for _, part := range parts {
+		path += fmt.Sprintf("/%v", part)
+	}
+	return path
+}
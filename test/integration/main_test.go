@@ -0,0 +1,191 @@
+//go:build integration
+
+// Package integration — сквозные тесты API на настоящем Postgres.
+// Запуск: INTEGRATION_DSN=postgres://... go test -tags integration ./test/integration/...
+// Каждый запуск создает отдельную схему, применяет к ней миграции и удаляет ее по завершении.
+package integration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	logger "github.com/sirupsen/logrus"
+
+	delivery "wallet-app/internal/app/delivery/http"
+	"wallet-app/internal/app/domain"
+	"wallet-app/internal/app/repository"
+	"wallet-app/internal/app/services"
+	"wallet-app/internal/configs"
+	db "wallet-app/internal/infrastructure/database"
+	"wallet-app/internal/infrastructure/fxrates"
+)
+
+// dsnEnv — переменная окружения с DSN Postgres в формате URL
+const dsnEnv = "INTEGRATION_DSN"
+
+const migrationsPath = "file://../../internal/infrastructure/database/migrations"
+
+var (
+	// api — сервер с настройками по умолчанию (пакетная запись включена)
+	api *apiServer
+	// unbatchedAPI — сервер, в котором каждая операция выполняется своей транзакцией
+	unbatchedAPI *apiServer
+	// storage — репозиторий тестовой схемы для проверок в обход API
+	storage *repository.WalletRepository
+	// database — пул соединений тестовой схемы для проверок состояния таблиц
+	database *pgxpool.Pool
+)
+
+func TestMain(m *testing.M) {
+	dsn := os.Getenv(dsnEnv)
+	if dsn == "" {
+		fmt.Printf("%s is not set, skipping integration tests\n", dsnEnv)
+		os.Exit(0)
+	}
+
+	gin.SetMode(gin.TestMode)
+	logger.SetLevel(logger.WarnLevel)
+
+	os.Exit(run(m, dsn))
+}
+
+func run(m *testing.M, dsn string) int {
+	ctx := context.Background()
+
+	admin, err := pgxpool.New(ctx, dsn)
+	if err != nil {
+		log.Fatalf("connect: %v", err)
+	}
+	defer admin.Close()
+
+	// Отдельная схема изолирует тесты от данных в базе и от параллельных запусков
+	schema := "it_" + uuid.NewString()[:8]
+	if _, err = admin.Exec(ctx, "CREATE SCHEMA "+schema); err != nil {
+		log.Fatalf("create schema: %v", err)
+	}
+	defer func() {
+		if _, err := admin.Exec(context.Background(), "DROP SCHEMA "+schema+" CASCADE"); err != nil {
+			log.Printf("drop schema %s: %v", schema, err)
+		}
+	}()
+
+	schemaDSN, err := withSearchPath(dsn, schema)
+	if err != nil {
+		log.Fatalf("dsn: %v", err)
+	}
+	if err = applyMigrations(schemaDSN); err != nil {
+		log.Fatalf("migrations: %v", err)
+	}
+
+	pool, err := db.ConnectPostgres(schemaDSN)
+	if err != nil {
+		log.Fatalf("connect: %v", err)
+	}
+	defer pool.Close()
+	database = pool
+
+	// Под нагрузкой тестов гонок транзакциям нужно больше попыток, чем в конфигурации по умолчанию
+	storage = repository.NewRepository(pool, repository.RetryPolicy{
+		MaxAttempts: 50,
+		BaseDelay:   5 * time.Millisecond,
+		MaxDelay:    200 * time.Millisecond,
+	})
+
+	api = newAPIServer(storage, true)
+	defer api.Close()
+	unbatchedAPI = newAPIServer(storage, false)
+	defer unbatchedAPI.Close()
+
+	return m.Run()
+}
+
+// withSearchPath добавляет к DSN параметр search_path, чтобы все запросы шли в схему schema
+func withSearchPath(dsn, schema string) (string, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme != "postgres" && u.Scheme != "postgresql" {
+		return "", fmt.Errorf("%s must be a postgres:// URL", dsnEnv)
+	}
+	query := u.Query()
+	query.Set("search_path", schema)
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+func applyMigrations(dsn string) error {
+	m, err := migrate.New(migrationsPath, dsn)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	if err = m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return err
+	}
+	return nil
+}
+
+// apiServer — HTTP-сервер приложения поверх тестовой схемы
+type apiServer struct {
+	*httptest.Server
+	// apiKey — ключ API, с которым отправляются запросы
+	apiKey  string
+	service *services.Service
+}
+
+func newAPIServer(repo repository.Storage, batching bool) *apiServer {
+	rates, err := fxrates.NewFileProvider("../../internal/configs/rates.json")
+	if err != nil {
+		log.Fatalf("rates: %v", err)
+	}
+
+	service := services.NewService(repo,
+		configs.HoldsConfig{DefaultTTL: 15 * time.Minute, MaxTTL: 24 * time.Hour},
+		configs.FXConfig{QuoteTTL: time.Minute, Rounding: "HALF_EVEN"},
+		configs.BatchingConfig{Enabled: batching, MaxBatchSize: 100, Timeout: 10 * time.Second},
+		// Уровень по умолчанию не задан, чтобы ограничения действовали только на кошельки TestWalletLimits
+		configs.LimitsConfig{Tiers: []configs.LimitTierConfig{
+			{Name: "restricted", Currency: domain.DefaultCurrency, MaxTransaction: "100", DailyWithdrawal: "150", MonthlyWithdrawal: "1000", MaxBalance: "500"},
+		}},
+		rates,
+		nil,
+		nil,
+	)
+
+	// Тесты работают с кошельками разных владельцев, поэтому основной ключ — администраторский
+	issued, err := service.IssueAPIKey(context.Background(), domain.APIKeyRequest{
+		Name: "integration", Subject: "integration", Roles: []string{string(domain.RoleAdmin)},
+	})
+	if err != nil {
+		log.Fatalf("api key: %v", err)
+	}
+
+	return &apiServer{
+		Server:  httptest.NewServer(delivery.NewHandler(service).InitRoutes()),
+		apiKey:  issued.Key,
+		service: service,
+	}
+}
+
+// as возвращает клиента того же сервера, отправляющего запросы с новым ключом клиента subject
+func (s *apiServer) as(t *testing.T, subject string, roles ...string) *apiServer {
+	t.Helper()
+	issued, err := s.service.IssueAPIKey(context.Background(), domain.APIKeyRequest{Name: subject, Subject: subject, Roles: roles})
+	if err != nil {
+		t.Fatalf("api key: %v", err)
+	}
+	return &apiServer{Server: s.Server, apiKey: issued.Key, service: s.service}
+}
@@ -0,0 +1,314 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"wallet-app/internal/app/domain"
+)
+
+// servers — варианты сервера, на которых прогоняются тесты гонок
+func servers() map[string]*apiServer {
+	return map[string]*apiServer{"batched": api, "unbatched": unbatchedAPI}
+}
+
+// parallel запускает n горутин и ждет их завершения
+func parallel(n int, fn func(i int)) {
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			fn(i)
+		}()
+	}
+	wg.Wait()
+}
+
+// outcome классифицирует ответ на операцию в гонке: успех, отказ по бизнес-правилу или
+// конфликт, который клиент должен повторить. Любой другой ответ — ошибка теста.
+func outcome(t *testing.T, resp response, rejectedCode string) (succeeded, rejected bool) {
+	switch resp.Status {
+	case http.StatusOK:
+		return true, false
+	case http.StatusConflict:
+		if assert.Equal(t, "concurrent_update", resp.problem(t).Code) {
+			return false, false
+		}
+	case http.StatusUnprocessableEntity:
+		if assert.Equal(t, rejectedCode, resp.problem(t).Code) {
+			return false, true
+		}
+	default:
+		t.Errorf("unexpected response %d: %s", resp.Status, resp.Body)
+	}
+	return false, false
+}
+
+// watchBalance опрашивает баланс кошелька, пока не закрыт stop, и проверяет, что он не уходит в минус
+func watchBalance(t *testing.T, server *apiServer, wallet domain.Wallet, stop <-chan struct{}) *sync.WaitGroup {
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			resp := server.do(t, http.MethodGet, walletPath(wallet.ID), nil)
+			if resp.Status != http.StatusOK {
+				t.Errorf("get balance: %d %s", resp.Status, resp.Body)
+				return
+			}
+			var balance domain.WalletBalance
+			resp.decode(t, &balance)
+			if balance.Total.IsNegative() || balance.Available.IsNegative() {
+				t.Errorf("negative balance observed: %+v", balance)
+				return
+			}
+		}
+	}()
+	return &wg
+}
+
+func TestRace_ConcurrentDeposits(t *testing.T) {
+	for name, server := range servers() {
+		t.Run(name, func(t *testing.T) {
+			wallet := server.createWallet(t, domain.CreateWalletRequest{})
+
+			// Каждое зачисление должно быть учтено ровно один раз
+			const n = 100
+			var succeeded atomic.Int64
+			parallel(n, func(i int) {
+				resp := server.operate(t, domain.WalletOperation{
+					WalletID: wallet.ID, OperationType: domain.Deposit, Amount: "1.25",
+				})
+				if ok, _ := outcome(t, resp, ""); ok {
+					succeeded.Add(1)
+				}
+			})
+
+			expected := decimal.RequireFromString("1.25").Mul(decimal.NewFromInt(succeeded.Load()))
+			server.requireTotal(t, wallet.ID, expected.String())
+			assert.Greater(t, succeeded.Load(), int64(n/2))
+			server.requireLedgerBalanced(t)
+		})
+	}
+}
+
+func TestRace_ConcurrentWithdrawals(t *testing.T) {
+	for name, server := range servers() {
+		t.Run(name, func(t *testing.T) {
+			wallet := server.createWallet(t, domain.CreateWalletRequest{})
+			server.deposit(t, wallet.ID, "500")
+
+			stop := make(chan struct{})
+			watcher := watchBalance(t, server, wallet, stop)
+
+			// 100 снятий по 10 с баланса 500: проходит не больше 50, баланс не уходит в минус
+			var succeeded, rejected atomic.Int64
+			parallel(100, func(i int) {
+				resp := server.operate(t, domain.WalletOperation{
+					WalletID: wallet.ID, OperationType: domain.Withdraw, Amount: "10",
+				})
+				ok, insufficient := outcome(t, resp, "insufficient_funds")
+				if ok {
+					succeeded.Add(1)
+				}
+				if insufficient {
+					rejected.Add(1)
+				}
+			})
+			close(stop)
+			watcher.Wait()
+
+			assert.LessOrEqual(t, succeeded.Load(), int64(50))
+			assert.Positive(t, rejected.Load())
+			expected := 500 - 10*succeeded.Load()
+			server.requireTotal(t, wallet.ID, strconv.FormatInt(expected, 10))
+			server.requireLedgerBalanced(t)
+		})
+	}
+}
+
+func TestRace_MixedOperations(t *testing.T) {
+	for name, server := range servers() {
+		t.Run(name, func(t *testing.T) {
+			wallet := server.createWallet(t, domain.CreateWalletRequest{})
+			server.deposit(t, wallet.ID, "100")
+
+			stop := make(chan struct{})
+			watcher := watchBalance(t, server, wallet, stop)
+
+			// Четные горутины зачисляют 7, нечетные списывают 13
+			var deposited, withdrawn atomic.Int64
+			parallel(120, func(i int) {
+				op := domain.WalletOperation{WalletID: wallet.ID, OperationType: domain.Deposit, Amount: "7"}
+				if i%2 == 1 {
+					op.OperationType, op.Amount = domain.Withdraw, "13"
+				}
+				ok, _ := outcome(t, server.operate(t, op), "insufficient_funds")
+				switch {
+				case ok && op.OperationType == domain.Deposit:
+					deposited.Add(7)
+				case ok:
+					withdrawn.Add(13)
+				}
+			})
+			close(stop)
+			watcher.Wait()
+
+			expected := 100 + deposited.Load() - withdrawn.Load()
+			require.GreaterOrEqual(t, expected, int64(0))
+			server.requireTotal(t, wallet.ID, strconv.FormatInt(expected, 10))
+			server.requireLedgerBalanced(t)
+		})
+	}
+}
+
+func TestRace_OpposingTransfers(t *testing.T) {
+	for name, server := range servers() {
+		t.Run(name, func(t *testing.T) {
+			first := server.createWallet(t, domain.CreateWalletRequest{})
+			second := server.createWallet(t, domain.CreateWalletRequest{})
+			server.deposit(t, first.ID, "100")
+			server.deposit(t, second.ID, "100")
+
+			// Встречные переводы не должны ни взаимно блокироваться, ни терять или создавать деньги
+			parallel(100, func(i int) {
+				from, to := first, second
+				if i%2 == 1 {
+					from, to = second, first
+				}
+				resp := server.do(t, http.MethodPost, "/api/v1/transfer",
+					domain.TransferOperation{FromWalletID: from.ID, ToWalletID: to.ID, Amount: "3"})
+				outcome(t, resp, "insufficient_funds")
+			})
+
+			firstBalance, _ := server.balance(t, first.ID)
+			secondBalance, _ := server.balance(t, second.ID)
+			assert.False(t, firstBalance.Total.IsNegative())
+			assert.False(t, secondBalance.Total.IsNegative())
+			assert.True(t, firstBalance.Total.Add(secondBalance.Total).Equal(decimal.NewFromInt(200)),
+				"%s + %s", firstBalance.Total, secondBalance.Total)
+			server.requireLedgerBalanced(t)
+		})
+	}
+}
+
+func TestRace_ShardedWallet(t *testing.T) {
+	wallet := api.createWallet(t, domain.CreateWalletRequest{Shards: 8})
+	require.Equal(t, 8, wallet.Shards)
+
+	var deposited atomic.Int64
+	parallel(100, func(i int) {
+		resp := api.operate(t, domain.WalletOperation{WalletID: wallet.ID, OperationType: domain.Deposit, Amount: "2"})
+		if ok, _ := outcome(t, resp, ""); ok {
+			deposited.Add(2)
+		}
+	})
+	api.requireTotal(t, wallet.ID, strconv.FormatInt(deposited.Load(), 10))
+
+	// Списания с шардированного кошелька собирают средства со всех шардов и не уводят баланс в минус
+	var withdrawn atomic.Int64
+	parallel(60, func(i int) {
+		resp := api.operate(t, domain.WalletOperation{WalletID: wallet.ID, OperationType: domain.Withdraw, Amount: "5"})
+		if ok, _ := outcome(t, resp, "insufficient_funds"); ok {
+			withdrawn.Add(5)
+		}
+	})
+
+	balance, resp := api.balance(t, wallet.ID)
+	assert.Empty(t, resp.Header.Get("ETag"))
+	assert.False(t, balance.Total.IsNegative())
+	assert.True(t, balance.Total.Equal(decimal.NewFromInt(deposited.Load()-withdrawn.Load())))
+	api.requireLedgerBalanced(t)
+}
+
+// Суммы списаний за день читаются под блокировкой кошелька: параллельные снятия не превышают ограничение вместе
+func TestRace_DailyWithdrawalLimit(t *testing.T) {
+	for name, server := range servers() {
+		t.Run(name, func(t *testing.T) {
+			wallet := server.createWallet(t, domain.CreateWalletRequest{})
+			server.expect(t, http.StatusOK, http.MethodPut, walletPath(wallet.ID, "limits"),
+				domain.WalletLimitsRequest{Tier: "restricted"})
+			for i := 0; i < 5; i++ {
+				server.deposit(t, wallet.ID, "100")
+			}
+
+			// 50 снятий по 10 при ограничении 150 в день: проходит не больше 15
+			var succeeded atomic.Int64
+			parallel(50, func(i int) {
+				resp := server.operate(t, domain.WalletOperation{WalletID: wallet.ID, OperationType: domain.Withdraw, Amount: "10"})
+				if ok, _ := outcome(t, resp, "limit_exceeded"); ok {
+					succeeded.Add(1)
+				}
+			})
+
+			assert.LessOrEqual(t, succeeded.Load(), int64(15))
+			server.requireTotal(t, wallet.ID, strconv.FormatInt(500-10*succeeded.Load(), 10))
+			server.requireLedgerBalanced(t)
+		})
+	}
+}
+
+// Корзина в Postgres общая для всех конкурентных запросов: сверх емкости не проходит ни один
+func TestPostgresRateLimiter(t *testing.T) {
+	limit := domain.RateLimit{Requests: 10, Per: time.Hour}
+	key := "test:" + uuid.NewString()
+
+	var allowed atomic.Int64
+	parallel(30, func(int) {
+		decision, err := storage.TakeToken(context.Background(), key, limit)
+		assert.NoError(t, err)
+		if decision.Allowed {
+			allowed.Add(1)
+		}
+	})
+
+	require.EqualValues(t, 10, allowed.Load())
+	decision, err := storage.TakeToken(context.Background(), key, limit)
+	require.NoError(t, err)
+	assert.False(t, decision.Allowed)
+	assert.Greater(t, decision.RetryAfter, time.Duration(0))
+}
+
+// Наполнившиеся корзины удаляются при следующем обращении к любой другой корзине
+func TestPostgresRateLimiterPrunesFullBuckets(t *testing.T) {
+	limit := domain.RateLimit{Requests: 10, Per: 10 * time.Millisecond}
+	idle := "test:" + uuid.NewString()
+	active := "test:" + uuid.NewString()
+
+	_, err := storage.TakeToken(context.Background(), idle, limit)
+	require.NoError(t, err)
+	time.Sleep(50 * time.Millisecond)
+
+	_, err = storage.TakeToken(context.Background(), active, limit)
+	require.NoError(t, err)
+
+	var keys []string
+	rows, err := database.Query(context.Background(),
+		"SELECT bucket_key FROM rate_limit_buckets WHERE bucket_key IN ($1, $2)", idle, active)
+	require.NoError(t, err)
+	for rows.Next() {
+		var key string
+		require.NoError(t, rows.Scan(&key))
+		keys = append(keys, key)
+	}
+	require.NoError(t, rows.Err())
+	assert.Equal(t, []string{active}, keys)
+}
@@ -0,0 +1,88 @@
+package test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	"wallet-app/internal/app/app_errors"
+	delivery "wallet-app/internal/app/delivery/http"
+	"wallet-app/internal/app/domain"
+	"wallet-app/internal/app/services"
+	"wallet-app/internal/app/services/mocks"
+)
+
+func TestVerifyLedger(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Сверка сбалансированного журнала: пополнение на 100 против счета external_cash_in
+	mockLedger := mocks.NewMockLedger(ctrl)
+	mockLedger.EXPECT().VerifyLedger(gomock.Any()).Return(domain.LedgerReport{
+		Totals: []domain.CurrencyTotal{{Currency: "RUB", Total: decimal.Zero}},
+		SystemAccounts: []domain.AccountBalance{{
+			AccountID: domain.SystemAccountCashIn,
+			Code:      "external_cash_in",
+			Currency:  "RUB",
+			Balance:   decimal.NewFromInt(-100),
+		}},
+		WalletDiscrepancies: []domain.WalletDiscrepancy{},
+		Balanced:            true,
+	}, nil).Times(1)
+
+	h := delivery.NewHandler(&services.Service{Ledger: mockLedger})
+	router := gin.Default()
+	router.GET("/api/v1/ledger/verify", h.VerifyLedger)
+
+	req, _ := http.NewRequest("GET", "/api/v1/ledger/verify", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var response domain.LedgerReport
+	err := json.Unmarshal(resp.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.True(t, response.Balanced)
+	assert.Len(t, response.SystemAccounts, 1)
+}
+
+func TestDebugVars(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDiagnostics := mocks.NewMockDiagnostics(ctrl)
+	h := delivery.NewHandler(&services.Service{Diagnostics: mockDiagnostics})
+	router := gin.Default()
+	router.GET("/debug/vars", h.DebugVars)
+
+	t.Run("Admin", func(t *testing.T) {
+		mockDiagnostics.EXPECT().CheckDiagnostics(gomock.Any()).Return(nil).Times(1)
+
+		req, _ := http.NewRequest("GET", "/debug/vars", nil)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusOK, resp.Code)
+		var vars map[string]any
+		assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &vars))
+		assert.Contains(t, vars, "cmdline")
+	})
+
+	t.Run("Forbidden", func(t *testing.T) {
+		mockDiagnostics.EXPECT().CheckDiagnostics(gomock.Any()).Return(app_errors.ErrForbidden).Times(1)
+
+		req, _ := http.NewRequest("GET", "/debug/vars", nil)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusForbidden, resp.Code)
+		assert.NotContains(t, resp.Body.String(), "cmdline")
+	})
+}
@@ -0,0 +1,370 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"wallet-app/internal/app/app_errors"
+	delivery "wallet-app/internal/app/delivery/http"
+	"wallet-app/internal/app/domain"
+	"wallet-app/internal/app/repository"
+	"wallet-app/internal/app/services"
+	"wallet-app/internal/app/services/mocks"
+	"wallet-app/internal/configs"
+)
+
+func decimalPtr(value string) *decimal.Decimal {
+	amount := decimal.RequireFromString(value)
+	return &amount
+}
+
+// limitOf возвращает ограничение, которое нарушила операция, или пустую строку
+func limitOf(err error) domain.LimitName {
+	var violation *domain.LimitViolation
+	if errors.As(err, &violation) {
+		return violation.Limit
+	}
+	return ""
+}
+
+func TestTransactionLimits_Check(t *testing.T) {
+	limits := domain.TransactionLimits{
+		MaxTransaction:    decimalPtr("100"),
+		DailyWithdrawal:   decimalPtr("150"),
+		MonthlyWithdrawal: decimalPtr("400"),
+		MaxBalance:        decimalPtr("1000"),
+	}
+	usage := func(daily, monthly string) domain.WithdrawalUsage {
+		return domain.WithdrawalUsage{Daily: decimal.RequireFromString(daily), Monthly: decimal.RequireFromString(monthly)}
+	}
+
+	tests := []struct {
+		name         string
+		amount       string
+		balanceAfter string
+		usage        domain.WithdrawalUsage
+		want         domain.LimitName
+	}{
+		{"deposit within limits", "100", "1000", usage("0", "0"), ""},
+		{"deposit over max transaction", "100.01", "200", usage("0", "0"), domain.LimitMaxTransaction},
+		{"withdrawal over max transaction", "-101", "0", usage("0", "0"), domain.LimitMaxTransaction},
+		{"deposit over max balance", "50", "1000.5", usage("0", "0"), domain.LimitMaxBalance},
+		// Потолок баланса не мешает списанию, даже если баланс уже выше него
+		{"withdrawal above max balance", "-50", "1200", usage("0", "0"), ""},
+		{"withdrawal up to daily limit", "-50", "0", usage("100", "100"), ""},
+		{"withdrawal over daily limit", "-50.01", "0", usage("100", "100"), domain.LimitDailyWithdrawal},
+		{"withdrawal over monthly limit", "-10", "0", usage("0", "395"), domain.LimitMonthlyWithdrawal},
+		// Списания за день и месяц не ограничивают зачисления
+		{"deposit after daily limit", "10", "10", usage("150", "400"), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := limits.Check(decimal.RequireFromString(tt.amount), decimal.RequireFromString(tt.balanceAfter), tt.usage)
+			if tt.want == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, app_errors.ErrLimitExceeded)
+			assert.Equal(t, tt.want, limitOf(err))
+		})
+	}
+
+	assert.NoError(t, domain.TransactionLimits{}.Check(decimal.NewFromInt(-1e9), decimal.NewFromInt(1e9), usage("1e9", "1e9")))
+
+	// Собственные ограничения кошелька заменяют ограничения уровня, остальные берутся из уровня
+	merged := domain.TransactionLimits{MaxTransaction: decimalPtr("500")}.Merge(limits)
+	assert.Equal(t, "500", merged.MaxTransaction.String())
+	assert.Equal(t, "150", merged.DailyWithdrawal.String())
+}
+
+func TestWithdrawalPeriods(t *testing.T) {
+	moscow := time.FixedZone("MSK", 3*60*60)
+	// 1 марта 01:30 по Москве — еще 28 февраля в UTC
+	dayStart, monthStart := domain.WithdrawalPeriods(time.Date(2024, time.March, 1, 1, 30, 0, 0, moscow))
+	assert.Equal(t, time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC), dayStart)
+	assert.Equal(t, time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC), monthStart)
+}
+
+// newLimitsService собирает сервисы поверх хранилища в памяти с уровнями ограничений standard (USD и JPY) и vip (USD)
+func newLimitsService(batching bool) *services.Service {
+	return services.NewService(
+		repository.NewMemoryRepository(),
+		configs.HoldsConfig{DefaultTTL: time.Minute, MaxTTL: time.Hour},
+		configs.FXConfig{QuoteTTL: time.Minute, Rounding: "HALF_EVEN"},
+		configs.BatchingConfig{Enabled: batching, MaxBatchSize: 100, Timeout: time.Second},
+		configs.LimitsConfig{
+			DefaultTier: "standard",
+			Tiers: []configs.LimitTierConfig{
+				{Name: "standard", Currency: "USD", MaxTransaction: "100", DailyWithdrawal: "150", MonthlyWithdrawal: "1000", MaxBalance: "500"},
+				{Name: "standard", Currency: "JPY", MaxTransaction: "15000", MaxBalance: "75000"},
+				{Name: "vip", Currency: "USD", MaxTransaction: "10000"},
+			},
+		},
+		nil,
+		nil,
+		nil,
+	)
+}
+
+func TestWalletLimits(t *testing.T) {
+	for _, batching := range []bool{false, true} {
+		t.Run(map[bool]string{false: "direct", true: "batching"}[batching], func(t *testing.T) {
+			ctx := context.Background()
+			service := newLimitsService(batching)
+
+			wallet, err := service.CreateWallet(ctx, domain.CreateWalletRequest{Currency: "USD", OwnerID: "owner-1"})
+			require.NoError(t, err)
+
+			limits, err := service.GetLimits(ctx, wallet.ID)
+			require.NoError(t, err)
+			assert.Empty(t, limits.Tier)
+			assert.Equal(t, "100", limits.Effective.MaxTransaction.String())
+
+			operation := func(walletID uuid.UUID, opType domain.OperationType, amount string) error {
+				_, err := service.ProcessOperation(ctx, domain.WalletOperation{WalletID: walletID, OperationType: opType, Amount: amount})
+				return err
+			}
+
+			err = operation(wallet.ID, domain.Deposit, "100.01")
+			assert.Equal(t, domain.LimitMaxTransaction, limitOf(err))
+			for i := 0; i < 4; i++ {
+				require.NoError(t, operation(wallet.ID, domain.Deposit, "100"))
+			}
+
+			// Баланс доходит до потолка 500, но не выше
+			require.NoError(t, operation(wallet.ID, domain.Deposit, "100"))
+			err = operation(wallet.ID, domain.Deposit, "0.01")
+			assert.ErrorIs(t, err, app_errors.ErrLimitExceeded)
+			assert.Equal(t, domain.LimitMaxBalance, limitOf(err))
+
+			// Снятия и переводы за день складываются
+			require.NoError(t, operation(wallet.ID, domain.Withdraw, "100"))
+			other, err := service.CreateWallet(ctx, domain.CreateWalletRequest{Currency: "USD", OwnerID: "owner-2"})
+			require.NoError(t, err)
+			_, err = service.Transfer(ctx, domain.TransferOperation{FromWalletID: wallet.ID, ToWalletID: other.ID, Amount: "50.01"})
+			assert.Equal(t, domain.LimitDailyWithdrawal, limitOf(err))
+			_, err = service.Transfer(ctx, domain.TransferOperation{FromWalletID: wallet.ID, ToWalletID: other.ID, Amount: "50"})
+			require.NoError(t, err)
+			err = operation(wallet.ID, domain.Withdraw, "0.01")
+			assert.Equal(t, domain.LimitDailyWithdrawal, limitOf(err))
+
+			// Отклоненные операции не изменили баланс
+			balance, err := service.GetBalance(ctx, wallet.ID)
+			require.NoError(t, err)
+			assert.Equal(t, "350", balance.Total.String())
+
+			// Собственное ограничение кошелька заменяет ограничение уровня
+			limits, err = service.SetLimits(ctx, wallet.ID, domain.WalletLimitsRequest{Tier: "vip", DailyWithdrawal: "300"})
+			require.NoError(t, err)
+			assert.Equal(t, "vip", limits.Tier)
+			assert.Equal(t, "300", limits.Overrides.DailyWithdrawal.String())
+			assert.Equal(t, "10000", limits.Effective.MaxTransaction.String())
+			assert.Nil(t, limits.Effective.MaxBalance)
+			require.NotNil(t, limits.UpdatedAt)
+
+			require.NoError(t, operation(wallet.ID, domain.Deposit, "1000"))
+			require.NoError(t, operation(wallet.ID, domain.Withdraw, "150"))
+			err = operation(wallet.ID, domain.Withdraw, "0.01")
+			assert.Equal(t, domain.LimitDailyWithdrawal, limitOf(err))
+
+			// Получатель перевода тоже проверяется: баланс other ограничен уровнем standard
+			_, err = service.SetLimits(ctx, wallet.ID, domain.WalletLimitsRequest{Tier: "vip"})
+			require.NoError(t, err)
+			for i := 0; i < 4; i++ {
+				require.NoError(t, operation(other.ID, domain.Deposit, "100"))
+			}
+			_, err = service.Transfer(ctx, domain.TransferOperation{FromWalletID: wallet.ID, ToWalletID: other.ID, Amount: "50.01"})
+			assert.Equal(t, domain.LimitMaxBalance, limitOf(err))
+		})
+	}
+}
+
+func TestWalletLimits_Holds(t *testing.T) {
+	for _, batching := range []bool{false, true} {
+		t.Run(map[bool]string{false: "direct", true: "batching"}[batching], func(t *testing.T) {
+			ctx := context.Background()
+			service := newLimitsService(batching)
+
+			wallet, err := service.CreateWallet(ctx, domain.CreateWalletRequest{Currency: "USD", OwnerID: "owner-1"})
+			require.NoError(t, err)
+			deposit(t, service, wallet.ID, "100")
+			deposit(t, service, wallet.ID, "100")
+
+			// Резерв проверяется как списание
+			_, err = service.CreateHold(ctx, wallet.ID, domain.HoldRequest{Amount: "100.01"})
+			assert.Equal(t, domain.LimitMaxTransaction, limitOf(err))
+			hold, err := service.CreateHold(ctx, wallet.ID, domain.HoldRequest{Amount: "100"})
+			require.NoError(t, err)
+
+			// Активный холд — будущее списание: он учитывается в списаниях за день
+			withdraw := domain.WalletOperation{WalletID: wallet.ID, OperationType: domain.Withdraw, Amount: "50.01"}
+			_, err = service.ProcessOperation(ctx, withdraw)
+			assert.Equal(t, domain.LimitDailyWithdrawal, limitOf(err))
+			withdraw.Amount = "50"
+			_, err = service.ProcessOperation(ctx, withdraw)
+			require.NoError(t, err)
+			_, err = service.CreateHold(ctx, wallet.ID, domain.HoldRequest{Amount: "0.01"})
+			assert.Equal(t, domain.LimitDailyWithdrawal, limitOf(err))
+
+			// Списание холда заменяет его резерв и укладывается в ограничение
+			_, err = service.CaptureHold(ctx, wallet.ID, hold.ID, domain.CaptureRequest{})
+			require.NoError(t, err)
+
+			// Ограничение, ужесточенное после создания холда, проверяется при списании
+			_, err = service.SetLimits(ctx, wallet.ID, domain.WalletLimitsRequest{Tier: "vip"})
+			require.NoError(t, err)
+			deposit(t, service, wallet.ID, "1000")
+			hold, err = service.CreateHold(ctx, wallet.ID, domain.HoldRequest{Amount: "500"})
+			require.NoError(t, err)
+			_, err = service.SetLimits(ctx, wallet.ID, domain.WalletLimitsRequest{Tier: "vip", DailyWithdrawal: "300"})
+			require.NoError(t, err)
+
+			_, err = service.CaptureHold(ctx, wallet.ID, hold.ID, domain.CaptureRequest{})
+			assert.ErrorIs(t, err, app_errors.ErrLimitExceeded)
+			assert.Equal(t, domain.LimitDailyWithdrawal, limitOf(err))
+
+			// Отклоненное списание не тронуло холд: за день списано 150, можно списать еще 150
+			result, err := service.CaptureHold(ctx, wallet.ID, hold.ID, domain.CaptureRequest{Amount: "150"})
+			require.NoError(t, err)
+			assert.Equal(t, domain.HoldCaptured, result.Hold.Status)
+
+			balance, err := service.GetBalance(ctx, wallet.ID)
+			require.NoError(t, err)
+			assert.Equal(t, "900", balance.Total.String())
+			assert.True(t, balance.Held.IsZero())
+		})
+	}
+}
+
+func TestWalletLimits_Corrections(t *testing.T) {
+	ctx := context.Background()
+	service := newLimitsService(false)
+
+	wallet, err := service.CreateWallet(ctx, domain.CreateWalletRequest{Currency: "USD", OwnerID: "owner-1"})
+	require.NoError(t, err)
+	operation := func(opType domain.OperationType, amount string, original *uuid.UUID) (domain.Transaction, error) {
+		return service.ProcessOperation(ctx, domain.WalletOperation{
+			WalletID: wallet.ID, OperationType: opType, Amount: amount, TransactionID: original,
+		})
+	}
+
+	var firstDeposit domain.Transaction
+	for i := 0; i < 5; i++ {
+		transaction, err := operation(domain.Deposit, "100", nil)
+		require.NoError(t, err)
+		if i == 0 {
+			firstDeposit = transaction
+		}
+	}
+	withdrawal, err := operation(domain.Withdraw, "100", nil)
+	require.NoError(t, err)
+	_, err = operation(domain.Deposit, "100", nil)
+	require.NoError(t, err)
+
+	// Возврат снятия зачисляет средства и не поднимает баланс выше потолка
+	_, err = operation(domain.Refund, "100", &withdrawal.ID)
+	assert.ErrorIs(t, err, app_errors.ErrLimitExceeded)
+	assert.Equal(t, domain.LimitMaxBalance, limitOf(err))
+
+	// Сторно пополнения исправляет операцию и не ограничено списаниями за день
+	_, err = operation(domain.Withdraw, "50", nil)
+	require.NoError(t, err)
+	_, err = operation(domain.Reversal, "", &firstDeposit.ID)
+	require.NoError(t, err)
+
+	_, err = operation(domain.Refund, "100", &withdrawal.ID)
+	require.NoError(t, err)
+
+	balance, err := service.GetBalance(ctx, wallet.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "450", balance.Total.String())
+}
+
+func TestWalletLimits_Management(t *testing.T) {
+	service := newLimitsService(false)
+	owner := as("owner-1")
+
+	wallet, err := service.CreateWallet(owner, domain.CreateWalletRequest{Currency: "USD"})
+	require.NoError(t, err)
+
+	// Владелец видит ограничения кошелька, но менять их может только администратор
+	_, err = service.GetLimits(owner, wallet.ID)
+	require.NoError(t, err)
+	_, err = service.SetLimits(owner, wallet.ID, domain.WalletLimitsRequest{Tier: "vip"})
+	assert.ErrorIs(t, err, app_errors.ErrForbidden)
+	_, err = service.GetLimits(as("mallory"), wallet.ID)
+	assert.ErrorIs(t, err, app_errors.ErrForbidden)
+
+	compliance := as("compliance", domain.RoleAdmin)
+	_, err = service.SetLimits(compliance, wallet.ID, domain.WalletLimitsRequest{Tier: "gold"})
+	assert.ErrorIs(t, err, app_errors.ErrUnknownLimitTier)
+	_, err = service.SetLimits(compliance, wallet.ID, domain.WalletLimitsRequest{MaxBalance: "0"})
+	assert.ErrorIs(t, err, app_errors.ErrAmountMustBePositive)
+	_, err = service.SetLimits(compliance, wallet.ID, domain.WalletLimitsRequest{MaxTransaction: "1e3"})
+	assert.ErrorIs(t, err, app_errors.ErrInvalidAmount)
+	_, err = service.SetLimits(compliance, uuid.New(), domain.WalletLimitsRequest{})
+	assert.ErrorIs(t, err, app_errors.ErrWalletNotFound)
+
+	// Пустой запрос возвращает кошелек к уровню по умолчанию без собственных ограничений
+	limits, err := service.SetLimits(compliance, wallet.ID, domain.WalletLimitsRequest{})
+	require.NoError(t, err)
+	assert.True(t, limits.Overrides.IsZero())
+	assert.Equal(t, "USD", limits.Currency)
+	assert.Equal(t, "500", limits.Effective.MaxBalance.String())
+
+	// Суммы уровня берутся в валюте кошелька; уровень, не описанный для нее, не назначается и не ограничивает
+	yen, err := service.CreateWallet(owner, domain.CreateWalletRequest{Currency: "JPY"})
+	require.NoError(t, err)
+	limits, err = service.GetLimits(owner, yen.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "JPY", limits.Currency)
+	assert.Equal(t, "75000", limits.Effective.MaxBalance.String())
+	_, err = service.SetLimits(compliance, yen.ID, domain.WalletLimitsRequest{Tier: "vip"})
+	assert.ErrorIs(t, err, app_errors.ErrUnknownLimitTier)
+
+	rubles, err := service.CreateWallet(owner, domain.CreateWalletRequest{Currency: "RUB"})
+	require.NoError(t, err)
+	limits, err = service.GetLimits(owner, rubles.ID)
+	require.NoError(t, err)
+	assert.True(t, limits.Effective.IsZero())
+}
+
+func TestLimitExceeded_ProblemDetails(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	limitErr := domain.TransactionLimits{DailyWithdrawal: decimalPtr("150")}.
+		Check(decimal.NewFromInt(-200), decimal.Zero, domain.WithdrawalUsage{})
+	mockService := mocks.NewMockWallet(ctrl)
+	mockService.EXPECT().ProcessOperation(gomock.Any(), gomock.Any()).Return(domain.Transaction{}, limitErr).Times(1)
+
+	h := delivery.NewHandler(&services.Service{Wallet: mockService})
+	router := gin.New()
+	router.POST("/api/v1/wallet", h.ChangeBalance)
+
+	body := `{"walletId":"` + uuid.NewString() + `","operationType":"WITHDRAW","amount":"200"}`
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/wallet", strings.NewReader(body))
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	var problem delivery.ProblemDetails
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &problem))
+	assert.Equal(t, "limit_exceeded", problem.Code)
+	assert.Equal(t, domain.LimitDailyWithdrawal, problem.Limit)
+	assert.Equal(t, "daily_withdrawal limit of 150 exceeded", problem.Detail)
+}
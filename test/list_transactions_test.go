@@ -0,0 +1,123 @@
+package test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	"wallet-app/internal/app/app_errors"
+	delivery "wallet-app/internal/app/delivery/http"
+	"wallet-app/internal/app/domain"
+	"wallet-app/internal/app/services"
+	"wallet-app/internal/app/services/mocks"
+)
+
+func TestListTransactions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	walletID := uuid.New()
+	transactionID := uuid.New()
+	createdAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	balanceAfter := decimal.NewFromInt(150)
+	nextCursor := domain.TransactionCursor{CreatedAt: createdAt, TransactionID: transactionID}.Encode()
+
+	// Ожидаем, что фильтры из query-параметров дойдут до сервиса
+	mockWallet := mocks.NewMockWallet(ctrl)
+	mockWallet.
+		EXPECT().
+		ListTransactions(gomock.Any(), gomock.Eq(walletID), gomock.Eq(domain.TransactionFilter{
+			OperationType: domain.Withdraw,
+			MinAmount:     "10",
+			Limit:         1,
+		})).
+		Return(domain.TransactionPage{
+			Transactions: []domain.Transaction{{
+				ID:            transactionID,
+				WalletID:      walletID,
+				OperationType: domain.Withdraw,
+				Amount:        decimal.NewFromInt(-50),
+				BalanceAfter:  &balanceAfter,
+				CreatedAt:     createdAt,
+			}},
+			NextCursor: nextCursor,
+		}, nil).Times(1)
+
+	h := delivery.NewHandler(&services.Service{Wallet: mockWallet})
+	router := gin.Default()
+	router.GET("/api/v1/wallets/:walletId/transactions", h.ListTransactions)
+
+	req, _ := http.NewRequest("GET", "/api/v1/wallets/"+walletID.String()+"/transactions?operationType=WITHDRAW&minAmount=10&limit=1", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var response domain.TransactionPage
+	err := json.Unmarshal(resp.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Len(t, response.Transactions, 1)
+	assert.Equal(t, transactionID, response.Transactions[0].ID)
+	assert.Equal(t, nextCursor, response.NextCursor)
+}
+
+func TestListTransactions_InvalidParams(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	walletID := uuid.New().String()
+
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{name: "invalid cursor", query: "cursor=not-a-cursor"},
+		{name: "unknown operation type", query: "operationType=UNKNOWN"},
+		{name: "inverted amount range", query: "minAmount=100&maxAmount=10"},
+		{name: "limit too large", query: "limit=1000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Сервис не должен вызываться при невалидных параметрах
+			h := delivery.NewHandler(nil)
+			router := gin.Default()
+			router.GET("/api/v1/wallets/:walletId/transactions", h.ListTransactions)
+
+			req, _ := http.NewRequest("GET", "/api/v1/wallets/"+walletID+"/transactions?"+tt.query, nil)
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, http.StatusBadRequest, resp.Code)
+		})
+	}
+}
+
+func TestListTransactions_WalletNotFound(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	walletID := uuid.New()
+
+	mockWallet := mocks.NewMockWallet(ctrl)
+	mockWallet.EXPECT().
+		ListTransactions(gomock.Any(), walletID, gomock.Any()).
+		Return(domain.TransactionPage{}, app_errors.ErrWalletNotFound).Times(1)
+
+	h := delivery.NewHandler(&services.Service{Wallet: mockWallet})
+	router := gin.Default()
+	router.GET("/api/v1/wallets/:walletId/transactions", h.ListTransactions)
+
+	req, _ := http.NewRequest("GET", "/api/v1/wallets/"+walletID.String()+"/transactions", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
@@ -0,0 +1,218 @@
+package test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"wallet-app/internal/app/app_errors"
+	"wallet-app/internal/app/domain"
+	"wallet-app/internal/app/repository"
+	"wallet-app/internal/app/services"
+	"wallet-app/internal/configs"
+)
+
+// newMemoryService собирает сервисы поверх хранилища в памяти
+func newMemoryService(batching bool) *services.Service {
+	return services.NewService(
+		repository.NewMemoryRepository(),
+		configs.HoldsConfig{DefaultTTL: time.Minute, MaxTTL: time.Hour},
+		configs.FXConfig{QuoteTTL: time.Minute, Rounding: "HALF_EVEN"},
+		configs.BatchingConfig{Enabled: batching, MaxBatchSize: 100, Timeout: time.Second},
+		configs.LimitsConfig{},
+		nil,
+		nil,
+		nil,
+	)
+}
+
+func deposit(t *testing.T, service *services.Service, walletID uuid.UUID, amount string) {
+	t.Helper()
+	_, err := service.ProcessOperation(context.Background(), domain.WalletOperation{
+		WalletID: walletID, OperationType: domain.Deposit, Amount: amount,
+	})
+	require.NoError(t, err)
+}
+
+func TestMemoryRepository_Operations(t *testing.T) {
+	ctx := context.Background()
+	service := newMemoryService(false)
+
+	wallet, err := service.CreateWallet(ctx, domain.CreateWalletRequest{Currency: "USD", OwnerID: "owner-1"})
+	require.NoError(t, err)
+	deposit(t, service, wallet.ID, "100.00")
+
+	// Снятие сверх остатка отклоняется и не меняет баланс
+	_, err = service.ProcessOperation(ctx, domain.WalletOperation{
+		WalletID: wallet.ID, OperationType: domain.Withdraw, Amount: "150",
+	})
+	assert.ErrorIs(t, err, app_errors.ErrInsufficientFunds)
+
+	// Холд уменьшает доступный остаток
+	_, err = service.CreateHold(ctx, wallet.ID, domain.HoldRequest{Amount: "80"})
+	require.NoError(t, err)
+	_, err = service.ProcessOperation(ctx, domain.WalletOperation{
+		WalletID: wallet.ID, OperationType: domain.Withdraw, Amount: "30",
+	})
+	assert.ErrorIs(t, err, app_errors.ErrInsufficientFunds)
+
+	balance, err := service.GetBalance(ctx, wallet.ID)
+	require.NoError(t, err)
+	assert.True(t, balance.Total.Equal(decimal.NewFromInt(100)))
+	assert.True(t, balance.Held.Equal(decimal.NewFromInt(80)))
+	assert.True(t, balance.Available.Equal(decimal.NewFromInt(20)))
+
+	// Повтор с тем же ключом идемпотентности возвращает ту же операцию
+	op := domain.WalletOperation{WalletID: wallet.ID, OperationType: domain.Withdraw, Amount: "5", IdempotencyKey: "key-1"}
+	first, err := service.ProcessOperation(ctx, op)
+	require.NoError(t, err)
+	second, err := service.ProcessOperation(ctx, op)
+	require.NoError(t, err)
+	assert.Equal(t, first.ID, second.ID)
+
+	op.Amount = "6"
+	_, err = service.ProcessOperation(ctx, op)
+	assert.ErrorIs(t, err, app_errors.ErrIdempotencyKeyReused)
+
+	// Перевод между кошельками одной валюты
+	recipient, err := service.CreateWallet(ctx, domain.CreateWalletRequest{Currency: "USD", OwnerID: "owner-1"})
+	require.NoError(t, err)
+	result, err := service.Transfer(ctx, domain.TransferOperation{
+		FromWalletID: wallet.ID, ToWalletID: recipient.ID, Amount: "15",
+	})
+	require.NoError(t, err)
+	assert.True(t, result.Credit.BalanceAfter.Equal(decimal.NewFromInt(15)))
+
+	page, err := service.ListTransactions(ctx, wallet.ID, domain.TransactionFilter{Limit: 2})
+	require.NoError(t, err)
+	assert.Len(t, page.Transactions, 2)
+	assert.Equal(t, domain.Transfer, page.Transactions[0].OperationType)
+	assert.NotEmpty(t, page.NextCursor)
+
+	owned, err := service.ListWalletsByOwner(ctx, "owner-1")
+	require.NoError(t, err)
+	assert.Len(t, owned.Wallets, 2)
+
+	report, err := service.VerifyLedger(ctx)
+	require.NoError(t, err)
+	assert.True(t, report.Balanced)
+}
+
+func TestMemoryRepository_IdempotencyKeyPerClient(t *testing.T) {
+	for _, batching := range []bool{false, true} {
+		service := newMemoryService(batching)
+		wallet, err := service.CreateWallet(context.Background(), domain.CreateWalletRequest{})
+		require.NoError(t, err)
+
+		// Клиенты выбирают ключи независимо: одинаковый ключ другого клиента не находит чужой ответ
+		op := domain.WalletOperation{WalletID: wallet.ID, OperationType: domain.Deposit, Amount: "10", IdempotencyKey: "order-1"}
+		first, err := service.ProcessOperation(as("billing", domain.RoleAdmin), op)
+		require.NoError(t, err)
+
+		op.Amount = "20"
+		second, err := service.ProcessOperation(as("payouts", domain.RoleAdmin), op)
+		require.NoError(t, err)
+		assert.NotEqual(t, first.ID, second.ID)
+
+		// Для своего клиента ключ по-прежнему дедуплицирует и проверяет тело запроса
+		replay, err := service.ProcessOperation(as("payouts", domain.RoleAdmin), op)
+		require.NoError(t, err)
+		assert.Equal(t, second.ID, replay.ID)
+		_, err = service.ProcessOperation(as("billing", domain.RoleAdmin), op)
+		assert.ErrorIs(t, err, app_errors.ErrIdempotencyKeyReused)
+
+		balance, err := service.GetBalance(context.Background(), wallet.ID)
+		require.NoError(t, err)
+		assert.True(t, balance.Total.Equal(decimal.NewFromInt(30)), "batching=%v: %s", batching, balance.Total)
+	}
+}
+
+func TestMemoryRepository_ConcurrentWithdrawals(t *testing.T) {
+	for _, batching := range []bool{false, true} {
+		name := "single operations"
+		if batching {
+			name = "batched operations"
+		}
+
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			service := newMemoryService(batching)
+
+			wallet, err := service.CreateWallet(ctx, domain.CreateWalletRequest{})
+			require.NoError(t, err)
+			deposit(t, service, wallet.ID, "500")
+
+			// 100 одновременных снятий по 10 с баланса 500: ровно половина проходит, баланс не уходит в минус
+			var wg sync.WaitGroup
+			var mu sync.Mutex
+			succeeded, rejected := 0, 0
+			for i := 0; i < 100; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					_, err := service.ProcessOperation(ctx, domain.WalletOperation{
+						WalletID: wallet.ID, OperationType: domain.Withdraw, Amount: "10",
+					})
+					mu.Lock()
+					defer mu.Unlock()
+					if err == nil {
+						succeeded++
+					} else if assert.ErrorIs(t, err, app_errors.ErrInsufficientFunds) {
+						rejected++
+					}
+				}()
+			}
+			wg.Wait()
+
+			assert.Equal(t, 50, succeeded)
+			assert.Equal(t, 50, rejected)
+
+			balance, err := service.GetBalance(ctx, wallet.ID)
+			require.NoError(t, err)
+			assert.True(t, balance.Total.IsZero())
+
+			report, err := service.VerifyLedger(ctx)
+			require.NoError(t, err)
+			assert.True(t, report.Balanced)
+		})
+	}
+}
+
+func TestMemoryRepository_IfMatch(t *testing.T) {
+	ctx := context.Background()
+	service := newMemoryService(false)
+
+	wallet, err := service.CreateWallet(ctx, domain.CreateWalletRequest{})
+	require.NoError(t, err)
+
+	balance, err := service.GetBalance(ctx, wallet.ID)
+	require.NoError(t, err)
+	version := balance.Version
+
+	op := domain.WalletOperation{WalletID: wallet.ID, OperationType: domain.Deposit, Amount: "10", ExpectedVersion: &version}
+	_, err = service.ProcessOperation(ctx, op)
+	require.NoError(t, err)
+
+	// Версия изменилась после первой операции
+	_, err = service.ProcessOperation(ctx, op)
+	assert.ErrorIs(t, err, app_errors.ErrPreconditionFailed)
+}
+
+func TestMemoryRepository_UnknownWallet(t *testing.T) {
+	ctx := context.Background()
+	service := newMemoryService(true)
+
+	_, err := service.GetBalance(ctx, uuid.New())
+	assert.ErrorIs(t, err, app_errors.ErrWalletNotFound)
+
+	_, err = service.ProcessOperation(ctx, domain.WalletOperation{
+		WalletID: uuid.New(), OperationType: domain.Deposit, Amount: "10",
+	})
+	assert.ErrorIs(t, err, app_errors.ErrWalletNotFound)
+}
@@ -0,0 +1,162 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	"wallet-app/internal/app/app_errors"
+	"wallet-app/internal/app/domain"
+	"wallet-app/internal/app/services"
+	"wallet-app/internal/app/services/mocks"
+)
+
+func TestOperationBatcher_GroupsConcurrentOperations(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	walletID := uuid.New()
+	store := mocks.NewMockBalanceStore(ctrl)
+	batcher := services.NewOperationBatcher(store, 100, 0, time.Second)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var batchSizes []int
+
+	// Пакет применяет операции по порядку: списание сверх остатка отклоняется, остальные проходят
+	store.EXPECT().UpdateBalanceBatch(gomock.Any(), walletID, gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ uuid.UUID, updates []domain.BalanceUpdate) ([]domain.BalanceUpdateResult, error) {
+			if len(batchSizes) == 0 {
+				close(started)
+				<-release
+			}
+			batchSizes = append(batchSizes, len(updates))
+
+			results := make([]domain.BalanceUpdateResult, len(updates))
+			for i, update := range updates {
+				if update.Amount.LessThan(decimal.NewFromInt(-100)) {
+					results[i].Err = app_errors.ErrInsufficientFunds
+					continue
+				}
+				results[i].Transaction = domain.Transaction{ID: uuid.New(), WalletID: walletID, Amount: update.Amount}
+			}
+			return results, nil
+		},
+	).Times(2)
+
+	submit := func(amount int64) (domain.Transaction, error) {
+		return batcher.Submit(context.Background(), domain.BalanceUpdate{
+			WalletID:      walletID,
+			OperationType: domain.Deposit,
+			Amount:        decimal.NewFromInt(amount),
+		})
+	}
+
+	// Первая операция занимает кошелек, остальные копятся в очереди и уходят одним пакетом
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_, err := submit(10)
+		assert.NoError(t, err)
+	}()
+	<-started
+
+	amounts := []int64{20, -500, 30}
+	transactions := make([]domain.Transaction, len(amounts))
+	errs := make([]error, len(amounts))
+	for i, amount := range amounts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			transactions[i], errs[i] = submit(amount)
+		}()
+	}
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, []int{1, 3}, batchSizes)
+	assert.NoError(t, errs[0])
+	assert.True(t, transactions[0].Amount.Equal(decimal.NewFromInt(20)))
+	assert.ErrorIs(t, errs[1], app_errors.ErrInsufficientFunds)
+	assert.NoError(t, errs[2])
+	assert.True(t, transactions[2].Amount.Equal(decimal.NewFromInt(30)))
+}
+
+func TestOperationBatcher_FallsBackToSingleOperations(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	walletID := uuid.New()
+	store := mocks.NewMockBalanceStore(ctrl)
+	batcher := services.NewOperationBatcher(store, 100, 50*time.Millisecond, time.Second)
+
+	// Ошибка пакета, не связанная с кошельком, разбирает пакет на отдельные операции
+	store.EXPECT().UpdateBalanceBatch(gomock.Any(), walletID, gomock.Any()).
+		Return(nil, app_errors.ErrInternal.Wrap(errors.New("numeric field overflow"))).Times(1)
+	store.EXPECT().UpdateBalance(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, update domain.BalanceUpdate) (domain.Transaction, error) {
+			if update.Amount.GreaterThan(decimal.NewFromInt(1000)) {
+				return domain.Transaction{}, app_errors.ErrInternal
+			}
+			return domain.Transaction{ID: uuid.New(), WalletID: walletID, Amount: update.Amount}, nil
+		},
+	).Times(2)
+
+	var wg sync.WaitGroup
+	errs := make([]error, 2)
+	for i, amount := range []int64{10, 1_000_000} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = batcher.Submit(context.Background(), domain.BalanceUpdate{
+				WalletID:      walletID,
+				OperationType: domain.Deposit,
+				Amount:        decimal.NewFromInt(amount),
+			})
+		}()
+	}
+	wg.Wait()
+
+	assert.NoError(t, errs[0])
+	assert.ErrorIs(t, errs[1], app_errors.ErrInternal)
+}
+
+func TestOperationBatcher_WalletNotFoundFailsWholeBatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	walletID := uuid.New()
+	store := mocks.NewMockBalanceStore(ctrl)
+	batcher := services.NewOperationBatcher(store, 100, 50*time.Millisecond, time.Second)
+
+	store.EXPECT().UpdateBalanceBatch(gomock.Any(), walletID, gomock.Any()).
+		Return(nil, app_errors.ErrWalletNotFound).Times(1)
+
+	var wg sync.WaitGroup
+	errs := make([]error, 3)
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = batcher.Submit(context.Background(), domain.BalanceUpdate{
+				WalletID:      walletID,
+				OperationType: domain.Withdraw,
+				Amount:        decimal.NewFromInt(-10),
+			})
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		assert.ErrorIs(t, err, app_errors.ErrWalletNotFound)
+	}
+}
@@ -0,0 +1,214 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	delivery "wallet-app/internal/app/delivery/http"
+	"wallet-app/internal/app/domain"
+	"wallet-app/internal/app/services"
+	"wallet-app/internal/app/services/mocks"
+	"wallet-app/internal/configs"
+	"wallet-app/internal/infrastructure/ratelimit"
+)
+
+func TestRateLimit_Take(t *testing.T) {
+	limit := domain.RateLimit{Requests: 60, Per: time.Minute, Burst: 3}
+	now := time.Now()
+	var bucket domain.TokenBucket
+
+	// Корзина вмещает burst запросов подряд
+	for i := 0; i < 3; i++ {
+		decision := limit.Take(&bucket, now)
+		require.True(t, decision.Allowed, "request %d", i)
+		assert.Equal(t, 2-i, decision.Remaining)
+	}
+
+	decision := limit.Take(&bucket, now)
+	assert.False(t, decision.Allowed)
+	assert.Equal(t, time.Second, decision.RetryAfter)
+
+	// Токен в секунду: через полсекунды ждать еще полсекунды, через секунду запрос проходит
+	decision = limit.Take(&bucket, now.Add(500*time.Millisecond))
+	assert.False(t, decision.Allowed)
+	assert.Equal(t, 500*time.Millisecond, decision.RetryAfter)
+	assert.True(t, limit.Take(&bucket, now.Add(time.Second)).Allowed)
+
+	// После долгого простоя в корзине не больше burst токенов
+	decision = limit.Take(&bucket, now.Add(time.Hour))
+	require.True(t, decision.Allowed)
+	assert.Equal(t, 2, decision.Remaining)
+
+	assert.True(t, domain.RateLimit{}.Take(&domain.TokenBucket{}, now).Allowed)
+}
+
+func TestRateLimitService(t *testing.T) {
+	walletID, busyWalletID := uuid.New(), uuid.New()
+	limits := services.NewRateLimitService(ratelimit.NewMemoryLimiter(), configs.RateLimitConfig{
+		Client:       configs.RateLimitRule{Requests: 2, Per: time.Minute},
+		Clients:      []configs.ClientRateLimit{{Subject: "billing", RateLimitRule: configs.RateLimitRule{Requests: 5, Per: time.Minute}}},
+		WalletDebits: configs.RateLimitRule{Requests: 1, Per: time.Minute},
+		Wallets:      []configs.WalletRateLimit{{WalletID: busyWalletID.String(), RateLimitRule: configs.RateLimitRule{Requests: 3, Per: time.Minute}}},
+	})
+
+	allowed := func(decide func() domain.RateDecision, n int) int {
+		count := 0
+		for i := 0; i < n; i++ {
+			if decide().Allowed {
+				count++
+			}
+		}
+		return count
+	}
+
+	user, billing := as("user-1"), as("billing")
+	assert.Equal(t, 2, allowed(func() domain.RateDecision { return limits.AllowRequest(user) }, 10))
+	assert.Equal(t, 5, allowed(func() domain.RateDecision { return limits.AllowRequest(billing) }, 10))
+	// Корзины клиентов независимы
+	assert.Equal(t, 2, allowed(func() domain.RateDecision { return limits.AllowRequest(as("user-2")) }, 10))
+
+	assert.Equal(t, 1, allowed(func() domain.RateDecision { return limits.AllowWalletDebit(user, walletID) }, 10))
+	assert.Equal(t, 3, allowed(func() domain.RateDecision { return limits.AllowWalletDebit(user, busyWalletID) }, 10))
+
+	// Недоступное хранилище корзин не останавливает обработку запросов
+	failing := services.NewRateLimitService(failingLimiter{}, configs.RateLimitConfig{
+		Client: configs.RateLimitRule{Requests: 1, Per: time.Minute},
+	})
+	assert.Equal(t, 3, allowed(func() domain.RateDecision { return failing.AllowRequest(user) }, 3))
+}
+
+type failingLimiter struct{}
+
+func (failingLimiter) TakeToken(context.Context, string, domain.RateLimit) (domain.RateDecision, error) {
+	return domain.RateDecision{}, errors.New("connection refused")
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	limits := services.NewRateLimitService(ratelimit.NewMemoryLimiter(), configs.RateLimitConfig{
+		Client:       configs.RateLimitRule{Requests: 4, Per: time.Minute},
+		WalletDebits: configs.RateLimitRule{Requests: 1, Per: time.Minute},
+	})
+	walletID := uuid.New()
+
+	router := gin.New()
+	router.POST("/api/v1/wallet",
+		func(c *gin.Context) {
+			c.Request = c.Request.WithContext(domain.ContextWithPrincipal(c.Request.Context(), domain.Principal{Subject: "user-1"}))
+		},
+		delivery.LimitClientRate(limits),
+		delivery.LimitWalletDebits(limits, func(c *gin.Context) (uuid.UUID, bool) {
+			var op domain.WalletOperation
+			body, _ := io.ReadAll(c.Request.Body)
+			c.Request.Body = io.NopCloser(bytes.NewReader(body))
+			return op.WalletID, json.Unmarshal(body, &op) == nil && op.OperationType == domain.Withdraw
+		}),
+		func(c *gin.Context) {
+			body, err := io.ReadAll(c.Request.Body)
+			require.NoError(t, err)
+			c.String(http.StatusOK, string(body))
+		},
+	)
+
+	send := func(opType domain.OperationType) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(domain.WalletOperation{WalletID: walletID, OperationType: opType, Amount: "1"})
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/wallet", bytes.NewReader(body))
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+	problemCode := func(resp *httptest.ResponseRecorder) string {
+		var problem delivery.ProblemDetails
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &problem))
+		return problem.Code
+	}
+
+	assert.Equal(t, http.StatusOK, send(domain.Withdraw).Code)
+
+	// Второе снятие упирается в ограничение кошелька, пополнения им не ограничены
+	resp := send(domain.Withdraw)
+	assert.Equal(t, http.StatusTooManyRequests, resp.Code)
+	assert.Equal(t, "rate_limited", problemCode(resp))
+	assert.Equal(t, "60", resp.Header().Get(delivery.RetryAfterHeader))
+
+	resp = send(domain.Deposit)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Contains(t, resp.Body.String(), `"DEPOSIT"`)
+
+	// Четыре запроса клиента исчерпали его ограничение
+	assert.Equal(t, http.StatusOK, send(domain.Deposit).Code)
+	resp = send(domain.Deposit)
+	assert.Equal(t, http.StatusTooManyRequests, resp.Code)
+	assert.Equal(t, "15", resp.Header().Get(delivery.RetryAfterHeader))
+}
+
+func TestRateLimit_WalletDebitRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	walletID := uuid.New()
+	originalID := uuid.New()
+
+	mockAuth := mocks.NewMockAuth(ctrl)
+	mockAuth.EXPECT().Authenticate(gomock.Any(), gomock.Any()).Return(domain.Principal{Subject: "user-1"}, nil).AnyTimes()
+	mockAuth.EXPECT().VerifySignature(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockLimits := mocks.NewMockRateLimits(ctrl)
+	mockLimits.EXPECT().AllowRequest(gomock.Any()).Return(domain.RateDecision{Allowed: true}).AnyTimes()
+	mockWallet := mocks.NewMockWallet(ctrl)
+
+	router := delivery.NewHandler(&services.Service{Auth: mockAuth, RateLimits: mockLimits, Wallet: mockWallet}).InitRoutes()
+	send := func(path string, body any) *httptest.ResponseRecorder {
+		var reader io.Reader = http.NoBody
+		if body != nil {
+			encoded, _ := json.Marshal(body)
+			reader = bytes.NewReader(encoded)
+		}
+		req, _ := http.NewRequest(http.MethodPost, path, reader)
+		req.Header.Set(delivery.APIKeyHeader, "wk_test")
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+
+	// Каждый путь, которым можно списать средства, упирается в ограничение списаний кошелька
+	debits := map[string]struct {
+		path string
+		body any
+	}{
+		"withdraw": {"/api/v1/wallet", domain.WalletOperation{WalletID: walletID, OperationType: domain.Withdraw, Amount: "1"}},
+		"reversal": {"/api/v1/wallet", domain.WalletOperation{WalletID: walletID, OperationType: domain.Reversal, TransactionID: &originalID}},
+		"refund": {"/api/v1/wallet", domain.WalletOperation{
+			WalletID: walletID, OperationType: domain.Refund, Amount: "1", TransactionID: &originalID,
+		}},
+		"capture": {"/api/v1/wallets/" + walletID.String() + "/holds/" + uuid.NewString() + "/capture", nil},
+	}
+	for name, debit := range debits {
+		t.Run(name, func(t *testing.T) {
+			mockLimits.EXPECT().AllowWalletDebit(gomock.Any(), walletID).
+				Return(domain.RateDecision{RetryAfter: time.Minute}).Times(1)
+
+			resp := send(debit.path, debit.body)
+			assert.Equal(t, http.StatusTooManyRequests, resp.Code)
+			assert.Equal(t, "60", resp.Header().Get(delivery.RetryAfterHeader))
+		})
+	}
+
+	// Пополнение ограничением списаний не проверяется
+	mockWallet.EXPECT().ProcessOperation(gomock.Any(), gomock.Any()).Return(domain.Transaction{ID: uuid.New()}, nil).Times(1)
+	resp := send("/api/v1/wallet", domain.WalletOperation{WalletID: walletID, OperationType: domain.Deposit, Amount: "1"})
+	assert.Equal(t, http.StatusOK, resp.Code)
+}
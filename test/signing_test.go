@@ -0,0 +1,201 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"wallet-app/internal/app/app_errors"
+	delivery "wallet-app/internal/app/delivery/http"
+	"wallet-app/internal/app/domain"
+	"wallet-app/internal/app/repository"
+	"wallet-app/internal/app/services"
+	"wallet-app/internal/configs"
+	"wallet-app/internal/infrastructure/signing"
+)
+
+const (
+	billingOldSecret = "billing-old-secret-billing-old-secret"
+	billingNewSecret = "billing-new-secret-billing-new-secret"
+	reportingSecret  = "reporting-secret-reporting-secret-00"
+)
+
+func newSigningVerifier(t *testing.T) *signing.Verifier {
+	t.Helper()
+	verifier, err := signing.NewVerifier(configs.SigningConfig{
+		MaxSkew: time.Minute,
+		Keys: []configs.SigningKeyConfig{
+			// У billing два ключа: старый еще принимается на время смены
+			{ID: "billing-1", Client: "billing", Secret: billingOldSecret},
+			{ID: "billing-2", Client: "billing", Secret: billingNewSecret},
+			{ID: "reporting-1", Client: "reporting", Secret: reportingSecret},
+		},
+	})
+	require.NoError(t, err)
+	return verifier
+}
+
+// signedRequest подписывает запрос ключом keyID с секретом secret в момент signedAt
+func signedRequest(keyID, secret string, signedAt time.Time, body string) domain.SignedRequest {
+	req := domain.SignedRequest{
+		KeyID:     keyID,
+		Timestamp: strconv.FormatInt(signedAt.Unix(), 10),
+		Nonce:     uuid.NewString(),
+		Method:    http.MethodPost,
+		Path:      "/api/v1/wallet",
+		Body:      []byte(body),
+	}
+	req.Signature = signing.Sign([]byte(secret), req)
+	return req
+}
+
+func TestSigningVerifier(t *testing.T) {
+	verifier := newSigningVerifier(t)
+	now := time.Now()
+
+	assert.True(t, verifier.Requires("billing"))
+	assert.False(t, verifier.Requires("user-1"))
+
+	for _, req := range []domain.SignedRequest{
+		signedRequest("billing-1", billingOldSecret, now, `{"amount":"10"}`),
+		signedRequest("billing-2", billingNewSecret, now.Add(-30*time.Second), `{"amount":"10"}`),
+	} {
+		expiresAt, err := verifier.Verify("billing", req)
+		require.NoError(t, err)
+		assert.True(t, expiresAt.After(now))
+	}
+
+	tests := []struct {
+		name    string
+		subject string
+		req     func() domain.SignedRequest
+		wantErr error
+	}{
+		{"wrong secret", "billing", func() domain.SignedRequest {
+			return signedRequest("billing-2", billingOldSecret, now, "{}")
+		}, app_errors.ErrInvalidSignature},
+		{"unknown key", "billing", func() domain.SignedRequest {
+			return signedRequest("billing-3", billingNewSecret, now, "{}")
+		}, app_errors.ErrInvalidSignature},
+		{"key of another client", "billing", func() domain.SignedRequest {
+			return signedRequest("reporting-1", reportingSecret, now, "{}")
+		}, app_errors.ErrInvalidSignature},
+		{"tampered body", "billing", func() domain.SignedRequest {
+			req := signedRequest("billing-2", billingNewSecret, now, `{"amount":"10"}`)
+			req.Body = []byte(`{"amount":"1000"}`)
+			return req
+		}, app_errors.ErrInvalidSignature},
+		{"tampered path", "billing", func() domain.SignedRequest {
+			req := signedRequest("billing-2", billingNewSecret, now, "{}")
+			req.Path = "/api/v1/transfer"
+			return req
+		}, app_errors.ErrInvalidSignature},
+		{"short nonce", "billing", func() domain.SignedRequest {
+			req := signedRequest("billing-2", billingNewSecret, now, "{}")
+			req.Nonce = "abc"
+			req.Signature = signing.Sign([]byte(billingNewSecret), req)
+			return req
+		}, app_errors.ErrInvalidSignature},
+		{"stale timestamp", "billing", func() domain.SignedRequest {
+			return signedRequest("billing-2", billingNewSecret, now.Add(-2*time.Minute), "{}")
+		}, app_errors.ErrSignatureExpired},
+		{"future timestamp", "billing", func() domain.SignedRequest {
+			return signedRequest("billing-2", billingNewSecret, now.Add(2*time.Minute), "{}")
+		}, app_errors.ErrSignatureExpired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := verifier.Verify(tt.subject, tt.req())
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestAuthService_VerifySignature(t *testing.T) {
+	repo := repository.NewMemoryRepository()
+	auth := services.NewAuthService(repo, repo, nil, newSigningVerifier(t))
+	billing := domain.ContextWithPrincipal(context.Background(), domain.Principal{Subject: "billing"})
+	user := domain.ContextWithPrincipal(context.Background(), domain.Principal{Subject: "user-1"})
+
+	req := signedRequest("billing-2", billingNewSecret, time.Now(), `{"amount":"10"}`)
+	require.NoError(t, auth.VerifySignature(billing, req))
+	// Повтор того же запроса отклоняется
+	assert.ErrorIs(t, auth.VerifySignature(billing, req), app_errors.ErrRequestReplayed)
+
+	// Клиент с ключами подписи обязан подписывать запросы, остальные — нет
+	assert.ErrorIs(t, auth.VerifySignature(billing, domain.SignedRequest{Method: http.MethodGet}), app_errors.ErrSignatureRequired)
+	require.NoError(t, auth.VerifySignature(user, domain.SignedRequest{Method: http.MethodGet}))
+
+	// Без настроенных ключей подписанные запросы не принимаются
+	unsigned := services.NewAuthService(repo, repo, nil, nil)
+	err := unsigned.VerifySignature(billing, signedRequest("billing-2", billingNewSecret, time.Now(), "{}"))
+	assert.ErrorIs(t, err, app_errors.ErrInvalidSignature)
+}
+
+func TestVerifySignatureMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	repo := repository.NewMemoryRepository()
+	auth := services.NewAuthService(repo, repo, nil, newSigningVerifier(t))
+
+	router := gin.New()
+	router.POST("/api/v1/wallet",
+		func(c *gin.Context) {
+			principal := domain.Principal{Subject: "billing"}
+			c.Request = c.Request.WithContext(domain.ContextWithPrincipal(c.Request.Context(), principal))
+		},
+		delivery.VerifySignature(auth),
+		func(c *gin.Context) {
+			// Обработчик получает тело запроса целиком, хотя его уже прочитала проверка подписи
+			body, err := io.ReadAll(c.Request.Body)
+			require.NoError(t, err)
+			c.String(http.StatusOK, string(body))
+		},
+	)
+
+	send := func(signed domain.SignedRequest, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/wallet", bytes.NewBufferString(body))
+		req.Header.Set(delivery.SignatureKeyIDHeader, signed.KeyID)
+		req.Header.Set(delivery.SignatureTimestampHeader, signed.Timestamp)
+		req.Header.Set(delivery.SignatureNonceHeader, signed.Nonce)
+		req.Header.Set(delivery.SignatureHeader, signed.Signature)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+	code := func(resp *httptest.ResponseRecorder) string {
+		var problem delivery.ProblemDetails
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &problem))
+		return problem.Code
+	}
+
+	body := `{"walletId":"` + uuid.NewString() + `","operationType":"DEPOSIT","amount":"10"}`
+	signed := signedRequest("billing-2", billingNewSecret, time.Now(), body)
+
+	resp := send(signed, body)
+	require.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, body, resp.Body.String())
+
+	resp = send(signed, body)
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	assert.Equal(t, "request_replayed", code(resp))
+
+	tampered := signedRequest("billing-2", billingNewSecret, time.Now(), body)
+	resp = send(tampered, `{"amount":"1000"}`)
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	assert.Equal(t, "invalid_signature", code(resp))
+
+	resp = send(domain.SignedRequest{}, body)
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	assert.Equal(t, "signature_required", code(resp))
+}
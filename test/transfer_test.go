@@ -0,0 +1,115 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	"wallet-app/internal/app/app_errors"
+	delivery "wallet-app/internal/app/delivery/http"
+	"wallet-app/internal/app/domain"
+	"wallet-app/internal/app/services"
+	"wallet-app/internal/app/services/mocks"
+)
+
+func TestTransfer_Success(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	fromID := uuid.New()
+	toID := uuid.New()
+	transferID := uuid.New()
+
+	mockService := mocks.NewMockWallet(ctrl)
+	mockService.EXPECT().Transfer(gomock.Any(), gomock.Eq(domain.TransferOperation{
+		FromWalletID: fromID,
+		ToWalletID:   toID,
+		Amount:       "25.50",
+	})).Return(domain.TransferResult{
+		ID:           transferID,
+		FromWalletID: fromID,
+		ToWalletID:   toID,
+		Amount:       decimal.RequireFromString("25.50"),
+	}, nil).Times(1)
+
+	h := delivery.NewHandler(&services.Service{Wallet: mockService})
+	router := gin.Default()
+	router.POST("/api/v1/transfer", h.Transfer)
+
+	requestBody, err := json.Marshal(map[string]string{
+		"fromWalletId": fromID.String(),
+		"toWalletId":   toID.String(),
+		"amount":       "25.50",
+	})
+	assert.NoError(t, err)
+
+	req, _ := http.NewRequest("POST", "/api/v1/transfer", bytes.NewBuffer(requestBody))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var response domain.TransferResult
+	err = json.Unmarshal(resp.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, transferID, response.ID)
+}
+
+func TestTransfer_SameWallet(t *testing.T) {
+	walletID := uuid.New().String()
+
+	// Сервис не должен вызываться при переводе самому себе
+	h := delivery.NewHandler(nil)
+	router := gin.Default()
+	router.POST("/api/v1/transfer", h.Transfer)
+
+	requestBody, err := json.Marshal(map[string]string{
+		"fromWalletId": walletID,
+		"toWalletId":   walletID,
+		"amount":       "10",
+	})
+	assert.NoError(t, err)
+
+	req, _ := http.NewRequest("POST", "/api/v1/transfer", bytes.NewBuffer(requestBody))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
+
+func TestTransfer_WalletNotFound(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockWallet(ctrl)
+	mockService.EXPECT().Transfer(gomock.Any(), gomock.Any()).
+		Return(domain.TransferResult{}, app_errors.ErrWalletNotFound).Times(1)
+
+	h := delivery.NewHandler(&services.Service{Wallet: mockService})
+	router := gin.Default()
+	router.POST("/api/v1/transfer", h.Transfer)
+
+	requestBody, err := json.Marshal(map[string]string{
+		"fromWalletId": uuid.New().String(),
+		"toWalletId":   uuid.New().String(),
+		"amount":       "10",
+	})
+	assert.NoError(t, err)
+
+	req, _ := http.NewRequest("POST", "/api/v1/transfer", bytes.NewBuffer(requestBody))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
@@ -0,0 +1,63 @@
+package test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+
+	"wallet-app/internal/app/app_errors"
+	"wallet-app/internal/app/repository"
+)
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"serialization failure", &pgconn.PgError{Code: "40001"}, true},
+		{"deadlock", &pgconn.PgError{Code: "40P01"}, true},
+		{"wrapped serialization failure", fmt.Errorf("commit: %w", &pgconn.PgError{Code: "40001"}), true},
+		{"unique violation", &pgconn.PgError{Code: "23505"}, false},
+		{"lock not available", &pgconn.PgError{Code: "55P03"}, false},
+		{"application error", app_errors.ErrInsufficientFunds, false},
+		{"plain error", errors.New("connection reset"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, repository.IsRetryable(tt.err))
+		})
+	}
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := repository.RetryPolicy{MaxAttempts: 10, BaseDelay: 10 * time.Millisecond, MaxDelay: 100 * time.Millisecond}
+
+	tests := []struct {
+		attempt int
+		min     time.Duration
+		max     time.Duration
+	}{
+		{1, 5 * time.Millisecond, 10 * time.Millisecond},
+		{2, 10 * time.Millisecond, 20 * time.Millisecond},
+		{4, 40 * time.Millisecond, 80 * time.Millisecond},
+		// Пауза ограничена MaxDelay
+		{5, 50 * time.Millisecond, 100 * time.Millisecond},
+		{64, 50 * time.Millisecond, 100 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("attempt %d", tt.attempt), func(t *testing.T) {
+			for i := 0; i < 100; i++ {
+				delay := policy.Backoff(tt.attempt)
+				assert.GreaterOrEqual(t, delay, tt.min)
+				assert.LessOrEqual(t, delay, tt.max)
+			}
+		})
+	}
+}
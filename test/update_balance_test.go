@@ -10,8 +10,10 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 
+	"wallet-app/internal/app/app_errors"
 	delivery "wallet-app/internal/app/delivery/http"
 	"wallet-app/internal/app/domain"
 	"wallet-app/internal/app/services"
@@ -27,13 +29,20 @@ func TestChangeBalance_Success(t *testing.T) {
 
 	// Статичный UUID для теста
 	walletID := uuid.Must(uuid.Parse("5979943c-4b3d-4313-9822-b12b47333916"))
+	balanceAfter := decimal.NewFromInt(100)
 
 	// Ожидаем, что метод ProcessOperation будет вызван с нужной операцией
 	mockService.EXPECT().ProcessOperation(gomock.Any(), gomock.Eq(domain.WalletOperation{
 		WalletID:      walletID,
 		OperationType: domain.OperationType("DEPOSIT"), // Убедитесь, что "DEPOSIT" корректен
 		Amount:        "100.00",
-	})).Return(nil).Times(1)
+	})).Return(domain.Transaction{
+		ID:            uuid.New(),
+		WalletID:      walletID,
+		OperationType: domain.Deposit,
+		Amount:        decimal.NewFromInt(100),
+		BalanceAfter:  &balanceAfter,
+	}, nil).Times(1)
 
 	// Создаем сервис с мок-сервисом
 	service := &services.Service{
@@ -68,6 +77,7 @@ func TestChangeBalance_Success(t *testing.T) {
 	err = json.Unmarshal(resp.Body.Bytes(), &response)
 	assert.NoError(t, err)
 	assert.Equal(t, "Operation completed", response["message"])
+	assert.Contains(t, response, "transaction")
 }
 
 func TestChangeBalance_InvalidRequestFormat(t *testing.T) {
@@ -168,3 +178,218 @@ func TestChangeBalance_ValidationError(t *testing.T) {
 	// Проверяем, что вернулась ошибка с статусом BadRequest
 	assert.Equal(t, http.StatusBadRequest, resp.Code)
 }
+
+func TestChangeBalance_IdempotencyKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockWallet(ctrl)
+	walletID := uuid.New()
+
+	// Ожидаем, что ключ из заголовка попадет в операцию
+	mockService.EXPECT().ProcessOperation(gomock.Any(), gomock.Eq(domain.WalletOperation{
+		WalletID:       walletID,
+		OperationType:  domain.Deposit,
+		Amount:         "100.00",
+		IdempotencyKey: "order-42",
+	})).Return(domain.Transaction{ID: uuid.New(), WalletID: walletID}, nil).Times(1)
+
+	h := delivery.NewHandler(&services.Service{Wallet: mockService})
+	router := gin.Default()
+	router.POST("/api/v1/wallet", h.ChangeBalance)
+
+	requestBody, err := json.Marshal(map[string]string{
+		"walletId":      walletID.String(),
+		"operationType": "DEPOSIT",
+		"amount":        "100.00",
+	})
+	assert.NoError(t, err)
+
+	req, _ := http.NewRequest("POST", "/api/v1/wallet", bytes.NewBuffer(requestBody))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(delivery.IdempotencyKeyHeader, "order-42")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+}
+
+func TestChangeBalance_IdempotencyKeyReused(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockWallet(ctrl)
+
+	// Ключ уже использовался с другой суммой
+	mockService.EXPECT().ProcessOperation(gomock.Any(), gomock.Any()).
+		Return(domain.Transaction{}, app_errors.ErrIdempotencyKeyReused).Times(1)
+
+	h := delivery.NewHandler(&services.Service{Wallet: mockService})
+	router := gin.Default()
+	router.POST("/api/v1/wallet", h.ChangeBalance)
+
+	requestBody, err := json.Marshal(map[string]string{
+		"walletId":      uuid.New().String(),
+		"operationType": "DEPOSIT",
+		"amount":        "200.00",
+	})
+	assert.NoError(t, err)
+
+	req, _ := http.NewRequest("POST", "/api/v1/wallet", bytes.NewBuffer(requestBody))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(delivery.IdempotencyKeyHeader, "order-42")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+}
+
+func TestChangeBalance_Refund(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockWallet(ctrl)
+	walletID := uuid.New()
+	originalID := uuid.New()
+
+	// Возврат части списания ссылается на исходную операцию
+	mockService.EXPECT().ProcessOperation(gomock.Any(), gomock.Eq(domain.WalletOperation{
+		WalletID:      walletID,
+		OperationType: domain.Refund,
+		Amount:        "30",
+		TransactionID: &originalID,
+	})).Return(domain.Transaction{
+		ID:                    uuid.New(),
+		WalletID:              walletID,
+		OperationType:         domain.Refund,
+		Amount:                decimal.NewFromInt(30),
+		OriginalTransactionID: &originalID,
+	}, nil).Times(1)
+
+	h := delivery.NewHandler(&services.Service{Wallet: mockService})
+	router := gin.Default()
+	router.POST("/api/v1/wallet", h.ChangeBalance)
+
+	requestBody, err := json.Marshal(map[string]string{
+		"walletId":      walletID.String(),
+		"operationType": "REFUND",
+		"amount":        "30",
+		"transactionId": originalID.String(),
+	})
+	assert.NoError(t, err)
+
+	req, _ := http.NewRequest("POST", "/api/v1/wallet", bytes.NewBuffer(requestBody))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+}
+
+func TestChangeBalance_CorrectionValidation(t *testing.T) {
+	walletID := uuid.New().String()
+
+	tests := []struct {
+		name string
+		body map[string]string
+	}{
+		{
+			name: "refund without transactionId",
+			body: map[string]string{"walletId": walletID, "operationType": "REFUND", "amount": "10"},
+		},
+		{
+			name: "reversal with amount",
+			body: map[string]string{"walletId": walletID, "operationType": "REVERSAL", "amount": "10", "transactionId": uuid.New().String()},
+		},
+		{
+			name: "deposit with transactionId",
+			body: map[string]string{"walletId": walletID, "operationType": "DEPOSIT", "amount": "10", "transactionId": uuid.New().String()},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Сервис не должен вызываться при невалидной операции
+			h := delivery.NewHandler(nil)
+			router := gin.Default()
+			router.POST("/api/v1/wallet", h.ChangeBalance)
+
+			requestBody, err := json.Marshal(tt.body)
+			assert.NoError(t, err)
+
+			req, _ := http.NewRequest("POST", "/api/v1/wallet", bytes.NewBuffer(requestBody))
+			req.Header.Set("Content-Type", "application/json")
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, http.StatusBadRequest, resp.Code)
+		})
+	}
+}
+
+func TestChangeBalance_CurrencyValidation(t *testing.T) {
+	walletID := uuid.New().String()
+
+	tests := []struct {
+		name string
+		body map[string]string
+	}{
+		{
+			name: "unsupported currency",
+			body: map[string]string{"walletId": walletID, "operationType": "DEPOSIT", "amount": "10", "currency": "XXX"},
+		},
+		{
+			name: "fractional amount in JPY",
+			body: map[string]string{"walletId": walletID, "operationType": "DEPOSIT", "amount": "10.5", "currency": "JPY"},
+		},
+		{
+			name: "too many decimal places in USD",
+			body: map[string]string{"walletId": walletID, "operationType": "WITHDRAW", "amount": "0.001", "currency": "USD"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := delivery.NewHandler(nil)
+			router := gin.Default()
+			router.POST("/api/v1/wallet", h.ChangeBalance)
+
+			requestBody, err := json.Marshal(tt.body)
+			assert.NoError(t, err)
+
+			req, _ := http.NewRequest("POST", "/api/v1/wallet", bytes.NewBuffer(requestBody))
+			req.Header.Set("Content-Type", "application/json")
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, http.StatusBadRequest, resp.Code)
+		})
+	}
+}
+
+func TestChangeBalance_RefundExceedsOriginal(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockWallet(ctrl)
+	mockService.EXPECT().ProcessOperation(gomock.Any(), gomock.Any()).
+		Return(domain.Transaction{}, app_errors.ErrRefundExceedsOriginal).Times(1)
+
+	h := delivery.NewHandler(&services.Service{Wallet: mockService})
+	router := gin.Default()
+	router.POST("/api/v1/wallet", h.ChangeBalance)
+
+	requestBody, err := json.Marshal(map[string]string{
+		"walletId":      uuid.New().String(),
+		"operationType": "REVERSAL",
+		"transactionId": uuid.New().String(),
+	})
+	assert.NoError(t, err)
+
+	req, _ := http.NewRequest("POST", "/api/v1/wallet", bytes.NewBuffer(requestBody))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusConflict, resp.Code)
+}
@@ -0,0 +1,125 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	"wallet-app/internal/app/app_errors"
+	delivery "wallet-app/internal/app/delivery/http"
+	"wallet-app/internal/app/domain"
+	"wallet-app/internal/app/services"
+	"wallet-app/internal/app/services/mocks"
+)
+
+func TestFreezeWallet(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	walletID := uuid.New()
+
+	mockAdmin := mocks.NewMockWalletAdmin(ctrl)
+	mockAdmin.EXPECT().SetWalletStatus(gomock.Any(), walletID, domain.WalletFrozen, domain.WalletStatusRequest{Reason: "AML check"}).
+		Return(domain.WalletState{WalletID: walletID, Status: domain.WalletFrozen, Reason: "AML check"}, nil).Times(1)
+
+	h := delivery.NewHandler(&services.Service{WalletAdmin: mockAdmin})
+	router := gin.Default()
+	router.POST("/api/v1/admin/wallets/:walletId/freeze", h.FreezeWallet)
+
+	requestBody, err := json.Marshal(map[string]string{"reason": "AML check"})
+	assert.NoError(t, err)
+
+	req, _ := http.NewRequest("POST", "/api/v1/admin/wallets/"+walletID.String()+"/freeze", bytes.NewBuffer(requestBody))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var response domain.WalletState
+	err = json.Unmarshal(resp.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.WalletFrozen, response.Status)
+}
+
+func TestCloseWallet_BalanceNotZero(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	walletID := uuid.New()
+
+	mockAdmin := mocks.NewMockWalletAdmin(ctrl)
+	mockAdmin.EXPECT().SetWalletStatus(gomock.Any(), walletID, domain.WalletClosed, domain.WalletStatusRequest{}).
+		Return(domain.WalletState{}, app_errors.ErrWalletBalanceNotZero).Times(1)
+
+	h := delivery.NewHandler(&services.Service{WalletAdmin: mockAdmin})
+	router := gin.Default()
+	router.POST("/api/v1/admin/wallets/:walletId/close", h.CloseWallet)
+
+	req, _ := http.NewRequest("POST", "/api/v1/admin/wallets/"+walletID.String()+"/close", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusConflict, resp.Code)
+}
+
+func TestChangeBalance_WalletFrozen(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	walletID := uuid.New()
+
+	// Списание с замороженного кошелька отклоняется
+	mockService := mocks.NewMockWallet(ctrl)
+	mockService.EXPECT().ProcessOperation(gomock.Any(), gomock.Any()).
+		Return(domain.Transaction{}, app_errors.ErrWalletFrozen).Times(1)
+
+	h := delivery.NewHandler(&services.Service{Wallet: mockService})
+	router := gin.Default()
+	router.POST("/api/v1/wallet", h.ChangeBalance)
+
+	requestBody, err := json.Marshal(map[string]string{
+		"walletId":      walletID.String(),
+		"operationType": "WITHDRAW",
+		"amount":        "10",
+	})
+	assert.NoError(t, err)
+
+	req, _ := http.NewRequest("POST", "/api/v1/wallet", bytes.NewBuffer(requestBody))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusConflict, resp.Code)
+}
+
+func TestWalletStatus_CheckMovement(t *testing.T) {
+	debit := decimal.NewFromInt(-10)
+	credit := decimal.NewFromInt(10)
+
+	tests := []struct {
+		status    domain.WalletStatus
+		debitErr  error
+		creditErr error
+	}{
+		{status: domain.WalletActive},
+		{status: domain.WalletFrozen, debitErr: app_errors.ErrWalletFrozen},
+		{status: domain.WalletBlocked, debitErr: app_errors.ErrWalletBlocked, creditErr: app_errors.ErrWalletBlocked},
+		{status: domain.WalletClosed, debitErr: app_errors.ErrWalletClosed, creditErr: app_errors.ErrWalletClosed},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.status), func(t *testing.T) {
+			assert.Equal(t, tt.debitErr, tt.status.CheckMovement(debit))
+			assert.Equal(t, tt.creditErr, tt.status.CheckMovement(credit))
+		})
+	}
+}